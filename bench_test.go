@@ -1,6 +1,7 @@
 package ntgo
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -163,3 +164,92 @@ func Benchmark_Value(b *testing.B) {
 		})
 	})
 }
+
+type BenchServer struct {
+	Host    string   `nt:"host,required"`
+	Port    int      `nt:"port,default=80"`
+	Ratio   float64  `nt:"ratio"`
+	Enabled bool     `nt:"enabled"`
+	Tags    []string `nt:"tags"`
+}
+
+type BenchConfig struct {
+	Name    string        `nt:"name"`
+	Servers []BenchServer `nt:"servers"`
+	Owner   struct {
+		Name  string `nt:"name"`
+		Email string `nt:"email"`
+	} `nt:"owner"`
+}
+
+const BenchConfigSample = `name: bench
+servers:
+  -
+    host: a.example.com
+    port: 8080
+    ratio: 0.5
+    enabled: true
+    tags:
+      - x
+      - y
+  -
+    host: b.example.com
+    ratio: 1.5
+    enabled: false
+owner:
+  name: smith
+  email: smith@example.com
+`
+
+func Benchmark_Marshaller(b *testing.B) {
+	b.Run("Marshal", func(b *testing.B) {
+		b.Run("Small struct", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				s := &BenchConfig{}
+				Marshal(BenchConfigSample, s)
+			}
+		})
+
+		b.Run("Small struct in parallel", func(b *testing.B) {
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					s := &BenchConfig{}
+					Marshal(BenchConfigSample, s)
+				}
+			})
+		})
+	})
+
+	b.Run("Field plan", func(b *testing.B) {
+		typ := reflect.TypeOf(BenchServer{})
+
+		b.Run("Uncached", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				typeFields(typ, nil)
+			}
+		})
+
+		b.Run("Cached", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				cachedTypeFields(typ, nil)
+			}
+		})
+	})
+
+	b.Run("Unmarshal", func(b *testing.B) {
+		b.Run("Small struct", func(b *testing.B) {
+			s := &BenchConfig{}
+			Marshal(BenchConfigSample, s)
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				Unmarshal(s)
+			}
+		})
+	})
+}
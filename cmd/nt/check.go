@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	ntgo "github.com/dolow/nt-go"
+)
+
+func check(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	include := flags.Bool("include", false, "resolve include directives relative to each file")
+	schemaFile := flags.String("schema", "", "NestedText schema file to validate documents against")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: nt check [flags] [file.nt...]\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	var schema *ntgo.Schema
+	if *schemaFile != "" {
+		data, err := ioutil.ReadFile(*schemaFile)
+		if err == nil {
+			schema, err = ntgo.ParseSchema(string(data))
+		}
+		if err != nil {
+			fmt.Fprintln(stderr, describeError(*schemaFile, "", err))
+			return 2
+		}
+	}
+
+	files := flags.Args()
+	if len(files) == 0 {
+		files = []string{""}
+	}
+
+	status := 0
+	for _, file := range files {
+		if err := checkFile(file, stdin, *include, schema); err != nil {
+			fmt.Fprintln(stderr, err)
+			status = 1
+		}
+	}
+	return status
+}
+
+// checkFile parses file and validates it against schema when it is not nil.
+// Returned error describes positions of the problems.
+func checkFile(file string, stdin io.Reader, include bool, schema *ntgo.Schema) error {
+	var value *ntgo.Value
+	var err error
+	dir := ""
+	name := file
+
+	if include && file != "" {
+		dir = filepath.Dir(file)
+		value, err = ntgo.ParseFileWithIncludes(os.DirFS(dir), filepath.Base(file))
+	} else {
+		var data []byte
+		if name, data, err = readInput(file, stdin); err == nil {
+			value, err = ntgo.ParseNamed(name, data)
+		}
+	}
+	if err == nil && schema != nil {
+		err = schema.Validate(value)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%s", describeError(name, dir, err))
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	ntgo "github.com/dolow/nt-go"
+)
+
+func convert(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("convert", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	to := flags.String("to", "", "output format; json or nt, default nt for .json files and json otherwise")
+	indent := flags.Int("indent", 2, "number of spaces to indent JSON, 0 for compact JSON")
+	output := flags.String("output", "", "output file name; default standard output")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: nt convert [flags] [file]\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return 2
+	}
+
+	file := flags.Arg(0)
+	if *to == "" {
+		*to = "json"
+		if strings.EqualFold(filepath.Ext(file), ".json") {
+			*to = "nt"
+		}
+	}
+	if *to != "json" && *to != "nt" {
+		fmt.Fprintf(stderr, "nt: -to must be json or nt, got %q\n", *to)
+		return 2
+	}
+
+	name, data, err := readInput(file, stdin)
+	var result []byte
+	if err == nil {
+		if *to == "json" {
+			result, err = toJSON(name, data, *indent)
+		} else {
+			result, err = ntgo.JSONToNestedText(data)
+		}
+	}
+	if err == nil {
+		if *output == "" {
+			_, err = stdout.Write(result)
+		} else {
+			err = ioutil.WriteFile(*output, result, 0644)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, describeError(name, "", err))
+		return 1
+	}
+	return 0
+}
+
+// toJSON converts NestedText content into JSON terminated with a line break.
+func toJSON(name string, content []byte, indent int) ([]byte, error) {
+	value, err := ntgo.ParseNamed(name, content)
+	if err != nil {
+		return nil, err
+	}
+	result, err := value.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	if indent > 0 {
+		indented := &bytes.Buffer{}
+		if err := json.Indent(indented, result, "", strings.Repeat(" ", indent)); err != nil {
+			return nil, err
+		}
+		result = indented.Bytes()
+	}
+	return append(result, '\n'), nil
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	ntgo "github.com/dolow/nt-go"
+)
+
+// diffContext is the number of unchanged lines around changes in diffs.
+const diffContext = 3
+
+func format(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	write := flags.Bool("w", false, "write result to the file instead of standard output")
+	showDiff := flags.Bool("d", false, "print diffs instead of formatted documents")
+	list := flags.Bool("l", false, "print names of files whose formatting differs")
+	indent := flags.Int("indent", ntgo.UnmarshalDefaultIndentSize, "number of spaces for each level")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: nt fmt [flags] [file.nt...]\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *indent < 1 {
+		fmt.Fprintf(stderr, "nt: -indent must be positive, got %d\n", *indent)
+		return 2
+	}
+
+	files := flags.Args()
+	if len(files) == 0 {
+		if *write {
+			fmt.Fprintln(stderr, "nt: -w can not be used with standard input")
+			return 2
+		}
+		files = []string{""}
+	}
+
+	status := 0
+	for _, file := range files {
+		name, data, err := readInput(file, stdin)
+		var formatted []byte
+		if err == nil {
+			formatted, err = formatDocument(name, data, *indent)
+		}
+		if err != nil {
+			fmt.Fprintln(stderr, describeError(name, "", err))
+			status = 1
+			continue
+		}
+
+		changed := !bytes.Equal(data, formatted)
+		if *list && changed {
+			fmt.Fprintln(stdout, name)
+		}
+		if *showDiff && changed {
+			fmt.Fprint(stdout, lineDiff(name, data, formatted))
+		}
+		if *write && changed {
+			if err := ioutil.WriteFile(file, formatted, 0644); err != nil {
+				fmt.Fprintln(stderr, describeError(name, "", err))
+				status = 1
+			}
+		}
+		if !*list && !*showDiff && !*write {
+			stdout.Write(formatted)
+		}
+	}
+	return status
+}
+
+// formatDocument re-indents content with indent spaces for each level.
+// Lines are kept as they are except for indentation and line breaks, comments are indented as the following line.
+func formatDocument(name string, content []byte, indent int) ([]byte, error) {
+	original, err := ntgo.ParseNamed(name, content)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := splitLines(content)
+	depths := make([]int, len(lines))
+	// levels are indentations of ancestors and the current line
+	levels := []int{0}
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			depths[i] = -1
+			continue
+		}
+		width := len(line) - len(trimmed)
+		for len(levels) > 1 && levels[len(levels)-1] > width {
+			levels = levels[:len(levels)-1]
+		}
+		if levels[len(levels)-1] < width {
+			levels = append(levels, width)
+		}
+		depths[i] = len(levels) - 1
+	}
+
+	result := &bytes.Buffer{}
+	next := 0
+	for i := len(lines) - 1; i >= 0; i-- {
+		if depths[i] < 0 {
+			depths[i] = next
+		} else {
+			next = depths[i]
+		}
+	}
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed != "" {
+			result.WriteString(strings.Repeat(" ", depths[i]*indent))
+			result.WriteString(trimmed)
+		}
+		result.WriteByte('\n')
+	}
+
+	formatted, err := ntgo.ParseNamed(name, result.Bytes())
+	if err != nil || changesDocument(original, formatted) {
+		return nil, fmt.Errorf("ntgo: formatting changes the document")
+	}
+	return result.Bytes(), nil
+}
+
+// changesDocument reports whether formatted differs from original other than line breaks normalized in texts.
+func changesDocument(original *ntgo.Value, formatted *ntgo.Value) bool {
+	for _, change := range ntgo.Diff(original, formatted) {
+		if change.Type == ntgo.ChangeTypeModified && change.Old.Type == ntgo.ValueTypeText && change.New.Type == ntgo.ValueTypeText &&
+			normalizeLineBreaks(change.Old.Text.String()) == normalizeLineBreaks(change.New.Text.String()) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func normalizeLineBreaks(str string) string {
+	str = strings.ReplaceAll(str, "\r\n", "\n")
+	return strings.ReplaceAll(str, "\r", "\n")
+}
+
+// splitLines splits content into lines without line breaks.
+func splitLines(content []byte) []string {
+	str := strings.TrimSuffix(normalizeLineBreaks(string(content)), "\n")
+	if str == "" {
+		return []string{}
+	}
+	return strings.Split(str, "\n")
+}
+
+// lineDiff returns the unified diff from before to after, which have the same number of lines.
+func lineDiff(name string, before []byte, after []byte) string {
+	oldLines := splitLines(before)
+	newLines := splitLines(after)
+	if len(oldLines) != len(newLines) {
+		return fmt.Sprintf("--- %s.orig\n+++ %s\n(line count changed)\n", name, name)
+	}
+	missingNewline := len(before) > 0 && before[len(before)-1] != '\n' && before[len(before)-1] != '\r'
+
+	changed := make([]bool, len(oldLines))
+	for i := range oldLines {
+		changed[i] = oldLines[i] != newLines[i] || (missingNewline && i == len(oldLines)-1)
+	}
+
+	result := &strings.Builder{}
+	fmt.Fprintf(result, "--- %s.orig\n+++ %s\n", name, name)
+	for i := 0; i < len(changed); i++ {
+		if !changed[i] {
+			continue
+		}
+
+		// extend the hunk while changes are close enough to share context
+		begin := i - diffContext
+		if begin < 0 {
+			begin = 0
+		}
+		end := i
+		for j := i; j < len(changed) && j <= end+2*diffContext; j++ {
+			if changed[j] {
+				end = j
+			}
+		}
+		stop := end + diffContext + 1
+		if stop > len(changed) {
+			stop = len(changed)
+		}
+
+		fmt.Fprintf(result, "@@ -%d,%d +%d,%d @@\n", begin+1, stop-begin, begin+1, stop-begin)
+		for j := begin; j < stop; {
+			if !changed[j] {
+				fmt.Fprintf(result, " %s\n", oldLines[j])
+				j++
+				continue
+			}
+			k := j
+			for ; k < stop && changed[k]; k++ {
+				fmt.Fprintf(result, "-%s\n", oldLines[k])
+				if missingNewline && k == len(oldLines)-1 {
+					result.WriteString("\\ No newline at end of file\n")
+				}
+			}
+			for ; j < k; j++ {
+				fmt.Fprintf(result, "+%s\n", newLines[j])
+			}
+		}
+		i = stop - 1
+	}
+	return result.String()
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	ntgo "github.com/dolow/nt-go"
+)
+
+func get(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("get", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	outputFormat := flags.String("format", "raw", "output format; raw for strings as they are, nt for a list of values or json")
+	include := flags.Bool("include", false, "resolve include directives relative to the file")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: nt get [flags] query [file.nt]\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() < 1 || flags.NArg() > 2 {
+		flags.Usage()
+		return 2
+	}
+	if *outputFormat != "raw" && *outputFormat != "nt" && *outputFormat != "json" {
+		fmt.Fprintf(stderr, "nt: -format must be raw, nt or json, got %q\n", *outputFormat)
+		return 2
+	}
+
+	query, err := ntgo.ParseQuery(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "nt: %s\n", message(err))
+		return 2
+	}
+
+	file := flags.Arg(1)
+	var value *ntgo.Value
+	name := file
+	dir := ""
+	if *include && file != "" {
+		dir = filepath.Dir(file)
+		value, err = ntgo.ParseFileWithIncludes(os.DirFS(dir), filepath.Base(file))
+	} else {
+		var data []byte
+		if name, data, err = readInput(file, stdin); err == nil {
+			value, err = ntgo.ParseNamed(name, data)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, describeError(name, dir, err))
+		return 1
+	}
+
+	matches := query.Find(value)
+	if len(matches) == 0 {
+		fmt.Fprintf(stderr, "nt: no value matches %q\n", query.String())
+		return 1
+	}
+
+	result, err := formatMatches(matches, *outputFormat)
+	if err != nil {
+		fmt.Fprintf(stderr, "nt: %s\n", message(err))
+		return 1
+	}
+	stdout.Write(result)
+	return 0
+}
+
+// formatMatches writes values of matches in outputFormat.
+func formatMatches(matches []*ntgo.QueryMatch, outputFormat string) ([]byte, error) {
+	result := &bytes.Buffer{}
+	switch outputFormat {
+	case "nt":
+		values := make([]*ntgo.Value, len(matches))
+		for i, match := range matches {
+			values[i] = match.Value
+		}
+		document, err := (&ntgo.Marshaller{}).Unmarshal(values)
+		if err != nil {
+			return nil, err
+		}
+		result.WriteString(document)
+	case "json":
+		for _, match := range matches {
+			encoded, err := match.Value.MarshalJSON()
+			if err != nil {
+				return nil, err
+			}
+			if err := json.Indent(result, encoded, "", "  "); err != nil {
+				return nil, err
+			}
+			result.WriteByte('\n')
+		}
+	default:
+		for _, match := range matches {
+			switch match.Value.Type {
+			case ntgo.ValueTypeString:
+				result.WriteString(match.Value.String)
+			case ntgo.ValueTypeText:
+				result.WriteString(match.Value.Text.String())
+			default:
+				document, err := (&ntgo.Marshaller{}).Unmarshal(match.Value)
+				if err != nil {
+					return nil, err
+				}
+				result.WriteString(document)
+			}
+			if !strings.HasSuffix(result.String(), "\n") {
+				result.WriteByte('\n')
+			}
+		}
+	}
+	return result.Bytes(), nil
+}
@@ -0,0 +1,131 @@
+// Command nt checks, formats, converts and queries NestedText documents.
+//
+// Usage:
+//
+//	nt check [-include] [-schema schema.nt] [file.nt...]
+//	nt fmt [-w] [-d] [-l] [-indent 2] [file.nt...]
+//	nt convert [-to json|nt] [-indent 2] [-output file] [file]
+//	nt get [-format raw|nt|json] [-include] query [file.nt]
+//
+// Standard input is read when no file is given.
+// Errors are printed with file names and lines, and nt exits with non-zero status.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	ntgo "github.com/dolow/nt-go"
+)
+
+const stdinName = "<stdin>"
+
+const usage = `Usage: nt <command> [flags] [file...]
+
+Commands:
+  check    parse documents and report errors
+  fmt      re-indent documents
+  convert  convert between NestedText and JSON
+  get      print values selected by query, e.g. 'officers[*].email'
+
+Run "nt <command> -h" for flags of the command.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command in args and returns the exit status.
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	switch args[0] {
+	case "check":
+		return check(args[1:], stdin, stdout, stderr)
+	case "fmt":
+		return format(args[1:], stdin, stdout, stderr)
+	case "convert":
+		return convert(args[1:], stdin, stdout, stderr)
+	case "get":
+		return get(args[1:], stdin, stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	}
+	fmt.Fprintf(stderr, "nt: unknown command %q\n%s", args[0], usage)
+	return 2
+}
+
+// readInput reads file, or stdin when file is empty.
+func readInput(file string, stdin io.Reader) (string, []byte, error) {
+	if file == "" {
+		data, err := ioutil.ReadAll(stdin)
+		return stdinName, data, err
+	}
+	data, err := ioutil.ReadFile(file)
+	return file, data, err
+}
+
+// describeError formats err with the position of the cause.
+// Names of files in ParseError and SchemaViolation are relative to dir.
+func describeError(name string, dir string, err error) string {
+	parseErr := &ntgo.ParseError{}
+	if errors.As(err, &parseErr) {
+		return position(sourceFile(name, dir, parseErr.File), parseErr.Line) + message(parseErr.Err)
+	}
+
+	validationErr := &ntgo.ValidationError{}
+	if errors.As(err, &validationErr) {
+		lines := []string{}
+		for _, violation := range validationErr.Violations {
+			lines = append(lines, fmt.Sprintf("%s%s: %s", position(sourceFile(name, dir, violation.File), violation.Line), displayKeyPath(violation.KeyPath), violation.Message))
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	return position(name, 0) + message(err)
+}
+
+// sourceFile returns the path of file reported in errors, or name when it is unknown.
+func sourceFile(name string, dir string, file string) string {
+	if file == "" {
+		return name
+	}
+	if dir != "" {
+		return filepath.Join(dir, filepath.FromSlash(file))
+	}
+	return file
+}
+
+func position(file string, line int) string {
+	if line > 0 {
+		return fmt.Sprintf("%s:%d: ", file, line)
+	}
+	return file + ": "
+}
+
+func displayKeyPath(path string) string {
+	if path == "" {
+		return "root"
+	}
+	return strconv.Quote(path)
+}
+
+// message returns err without the prefix of the package and the name of the file.
+func message(err error) string {
+	pathErr := &fs.PathError{}
+	if errors.As(err, &pathErr) {
+		err = pathErr.Err
+	}
+	return strings.TrimPrefix(err.Error(), "ntgo: ")
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func subject(stdin string, args ...string) (int, string, string) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	status := run(args, strings.NewReader(stdin), stdout, stderr)
+	return status, stdout.String(), stderr.String()
+}
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "nt")
+	assert.Nil(t, err)
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestRun(t *testing.T) {
+	t.Run("unknown command", func(t *testing.T) {
+		t.Run("should exit with usage", func(t *testing.T) {
+			status, _, stderr := subject("", "lint")
+			assert.Equal(t, 2, status)
+			assert.True(t, strings.HasPrefix(stderr, `nt: unknown command "lint"`))
+		})
+	})
+}
+
+func TestCheck(t *testing.T) {
+	t.Run("valid document", func(t *testing.T) {
+		t.Run("should exit with 0", func(t *testing.T) {
+			status, stdout, stderr := subject("a: 1\nb:\n  - x", "check")
+			assert.Equal(t, 0, status)
+			assert.Equal(t, "", stdout)
+			assert.Equal(t, "", stderr)
+		})
+	})
+
+	t.Run("invalid documents", func(t *testing.T) {
+		t.Run("should be reported with file names and lines", func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"valid.nt":   "a: 1",
+				"invalid.nt": "a: 1\nb:\n  c: 1\n   d: 2",
+			})
+			defer os.RemoveAll(dir)
+			valid := filepath.Join(dir, "valid.nt")
+			invalid := filepath.Join(dir, "invalid.nt")
+
+			status, _, stderr := subject("", "check", valid, invalid, filepath.Join(dir, "none.nt"))
+			assert.Equal(t, 1, status)
+			assert.Equal(t, invalid+":4: string type can not have child\n"+filepath.Join(dir, "none.nt")+": no such file or directory\n", stderr)
+		})
+	})
+
+	t.Run("with -include", func(t *testing.T) {
+		t.Run("should report errors in included files", func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"app.nt":             "name: app\ndatabase: !include config/database.nt",
+				"config/database.nt": "host: localhost\nhost: example.com",
+			})
+			defer os.RemoveAll(dir)
+
+			status, _, stderr := subject("", "check", "-include", filepath.Join(dir, "app.nt"))
+			assert.Equal(t, 1, status)
+			assert.Equal(t, filepath.Join(dir, "config", "database.nt")+":2: dictionary type can not have the same key\n", stderr)
+		})
+	})
+
+	t.Run("with -schema", func(t *testing.T) {
+		t.Run("should report violations with lines", func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"schema.nt": "keys:\n  port:\n    minimum: 1\n  host:",
+			})
+			defer os.RemoveAll(dir)
+
+			status, _, stderr := subject("port: 0\nuser: admin", "check", "-schema", filepath.Join(dir, "schema.nt"))
+			assert.Equal(t, 1, status)
+			assert.Equal(t, "<stdin>:1: \"host\": missing key\n<stdin>:1: \"port\": 0 is less than minimum 1\n<stdin>:2: \"user\": unknown key\n", stderr)
+		})
+
+		t.Run("should report violations in included files", func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"schema.nt":        "keys:\n  server:\n    keys:\n      port:\n        minimum: 1\n      host:",
+				"app.nt":           "server: !include config/server.nt",
+				"config/server.nt": "host: localhost\nport: 0",
+			})
+			defer os.RemoveAll(dir)
+
+			status, _, stderr := subject("", "check", "-include", "-schema", filepath.Join(dir, "schema.nt"), filepath.Join(dir, "app.nt"))
+			assert.Equal(t, 1, status)
+			assert.Equal(t, filepath.Join(dir, "config", "server.nt")+":2: \"server.port\": 0 is less than minimum 1\n", stderr)
+		})
+	})
+}
+
+func TestFormat(t *testing.T) {
+	content := "# config\na:\n    b: 1\n  # comment of c\n    c:\n          - x\n          -\n                > text\n\nd: 2"
+
+	t.Run("document", func(t *testing.T) {
+		t.Run("should be re-indented keeping comments and blank lines", func(t *testing.T) {
+			status, stdout, _ := subject(content, "fmt")
+			assert.Equal(t, 0, status)
+			assert.Equal(t, "# config\na:\n  b: 1\n  # comment of c\n  c:\n    - x\n    -\n      > text\n\nd: 2\n", stdout)
+		})
+
+		t.Run("should be re-indented with -indent", func(t *testing.T) {
+			_, stdout, _ := subject("a:\n  - x", "fmt", "-indent", "4")
+			assert.Equal(t, "a:\n    - x\n", stdout)
+		})
+
+		t.Run("should normalize line breaks", func(t *testing.T) {
+			_, stdout, _ := subject("a:\r\n  b: 1\r\n", "fmt")
+			assert.Equal(t, "a:\n  b: 1\n", stdout)
+		})
+
+		t.Run("should normalize line breaks of texts", func(t *testing.T) {
+			status, stdout, stderr := subject("a:\r\n    > first\r\n    > second\r\nb: 1\r\n", "fmt")
+			assert.Equal(t, 0, status)
+			assert.Equal(t, "", stderr)
+			assert.Equal(t, "a:\n  > first\n  > second\nb: 1\n", stdout)
+		})
+
+		t.Run("should not be formatted when it is invalid", func(t *testing.T) {
+			status, stdout, stderr := subject("a: 1\n  b: 2", "fmt")
+			assert.Equal(t, 1, status)
+			assert.Equal(t, "", stdout)
+			assert.Equal(t, "<stdin>:2: string type can not have child\n", stderr)
+		})
+	})
+
+	t.Run("with -d", func(t *testing.T) {
+		t.Run("should print unified diff", func(t *testing.T) {
+			_, stdout, _ := subject("a:\n    b: 1\n    c: 2\nd: 3\ne: 4\nf: 5\ng: 6\nh: 7\ni: 8\nj:\n    - x", "fmt", "-d")
+			assert.Equal(t, `--- <stdin>.orig
++++ <stdin>
+@@ -1,6 +1,6 @@
+ a:
+-    b: 1
+-    c: 2
++  b: 1
++  c: 2
+ d: 3
+ e: 4
+ f: 5
+@@ -8,4 +8,4 @@
+ h: 7
+ i: 8
+ j:
+-    - x
+\ No newline at end of file
++  - x
+`, stdout)
+		})
+
+		t.Run("should print nothing for formatted document", func(t *testing.T) {
+			_, stdout, _ := subject("a:\n  b: 1\n", "fmt", "-d")
+			assert.Equal(t, "", stdout)
+		})
+	})
+
+	t.Run("with -w and -l", func(t *testing.T) {
+		t.Run("should rewrite and list changed files", func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"changed.nt":   "a:\n    b: 1\n",
+				"unchanged.nt": "a:\n  b: 1\n",
+			})
+			defer os.RemoveAll(dir)
+			changed := filepath.Join(dir, "changed.nt")
+
+			status, stdout, _ := subject("", "fmt", "-w", "-l", changed, filepath.Join(dir, "unchanged.nt"))
+			assert.Equal(t, 0, status)
+			assert.Equal(t, changed+"\n", stdout)
+			data, _ := ioutil.ReadFile(changed)
+			assert.Equal(t, "a:\n  b: 1\n", string(data))
+		})
+	})
+}
+
+func TestConvert(t *testing.T) {
+	t.Run("NestedText", func(t *testing.T) {
+		t.Run("should be converted into JSON", func(t *testing.T) {
+			status, stdout, _ := subject("a:\n  - x\nb:\n  > text", "convert")
+			assert.Equal(t, 0, status)
+			assert.Equal(t, "{\n  \"a\": [\n    \"x\"\n  ],\n  \"b\": \"text\"\n}\n", stdout)
+		})
+
+		t.Run("should be converted into compact JSON with -indent 0", func(t *testing.T) {
+			_, stdout, _ := subject("a: 1", "convert", "-indent", "0")
+			assert.Equal(t, "{\"a\":\"1\"}\n", stdout)
+		})
+
+		t.Run("should report errors with lines", func(t *testing.T) {
+			status, _, stderr := subject("a: 1\na: 2", "convert")
+			assert.Equal(t, 1, status)
+			assert.Equal(t, "<stdin>:2: dictionary type can not have the same key\n", stderr)
+		})
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		t.Run("should be converted into NestedText by extension", func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{"config.json": `{"a": ["x", "y"], "b": "1"}`})
+			defer os.RemoveAll(dir)
+
+			status, stdout, _ := subject("", "convert", filepath.Join(dir, "config.json"))
+			assert.Equal(t, 0, status)
+			assert.Equal(t, "a:\n  - x\n  - y\nb: 1\n", stdout)
+		})
+
+		t.Run("should be converted into NestedText with -to nt", func(t *testing.T) {
+			_, stdout, _ := subject(`{"a": "1"}`, "convert", "-to", "nt")
+			assert.Equal(t, "a: 1\n", stdout)
+		})
+	})
+}
+
+func TestGet(t *testing.T) {
+	content := "president:\n  name: Katheryn McDaniel\n  address:\n    > 138 Almond Street\n    > Topika, Kansas 20697\n  phone:\n    cell: 1-210-555-5297\nofficers:\n  -\n    email: margaret@example.com\n  -\n    email: merrill@example.com"
+
+	t.Run("raw format", func(t *testing.T) {
+		t.Run("should print strings and texts as they are", func(t *testing.T) {
+			status, stdout, _ := subject(content, "get", "president.phone.cell")
+			assert.Equal(t, 0, status)
+			assert.Equal(t, "1-210-555-5297\n", stdout)
+
+			_, stdout, _ = subject(content, "get", "president.address")
+			assert.Equal(t, "138 Almond Street\nTopika, Kansas 20697\n", stdout)
+
+			_, stdout, _ = subject(content, "get", "..email")
+			assert.Equal(t, "margaret@example.com\nmerrill@example.com\n", stdout)
+		})
+
+		t.Run("should print dictionaries as NestedText", func(t *testing.T) {
+			_, stdout, _ := subject(content, "get", "president.phone")
+			assert.Equal(t, "cell: 1-210-555-5297\n", stdout)
+		})
+	})
+
+	t.Run("nt format", func(t *testing.T) {
+		t.Run("should print a list of values", func(t *testing.T) {
+			_, stdout, _ := subject(content, "get", "-format", "nt", "officers[*].email")
+			assert.Equal(t, "- margaret@example.com\n- merrill@example.com\n", stdout)
+		})
+	})
+
+	t.Run("json format", func(t *testing.T) {
+		t.Run("should print each value as JSON", func(t *testing.T) {
+			_, stdout, _ := subject(content, "get", "-format", "json", "..email")
+			assert.Equal(t, "\"margaret@example.com\"\n\"merrill@example.com\"\n", stdout)
+
+			_, stdout, _ = subject(content, "get", "-format", "json", "president[\"phone\"]")
+			assert.Equal(t, "{\n  \"cell\": \"1-210-555-5297\"\n}\n", stdout)
+		})
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		t.Run("should exit with 1", func(t *testing.T) {
+			status, stdout, stderr := subject(content, "get", "president.phone.home")
+			assert.Equal(t, 1, status)
+			assert.Equal(t, "", stdout)
+			assert.Equal(t, "nt: no value matches \"president.phone.home\"\n", stderr)
+		})
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		t.Run("should exit with usage error", func(t *testing.T) {
+			status, _, stderr := subject(content, "get", "officers[x]")
+			assert.Equal(t, 2, status)
+			assert.Equal(t, "nt: invalid query \"officers[x]\": invalid index \"x\" at 8\n", stderr)
+		})
+	})
+
+	t.Run("file", func(t *testing.T) {
+		t.Run("should be queried with includes", func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"officers.nt":  "president: !include president.nt",
+				"president.nt": "phone:\n  cell: 1-210-555-5297",
+			})
+			defer os.RemoveAll(dir)
+
+			_, stdout, _ := subject("", "get", "-include", "president.phone.cell", filepath.Join(dir, "officers.nt"))
+			assert.Equal(t, "1-210-555-5297\n", stdout)
+		})
+	})
+}
@@ -0,0 +1,719 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	ntgo "github.com/dolow/nt-go"
+)
+
+const ntgoPath = "github.com/dolow/nt-go"
+
+type typeKind int
+
+const (
+	kindString typeKind = iota
+	kindBool
+	kindInt
+	kindUint
+	kindFloat
+	kindComplex
+	kindStruct
+	kindPointer
+	kindSlice
+	kindMap
+)
+
+// typeInfo describes a field type supported by the generator.
+type typeInfo struct {
+	kind typeKind
+	// expr is the Go expression of the type in the generated file
+	expr string
+	// bits is the size for strconv, 0 for int and uint
+	bits int
+	elem *typeInfo
+}
+
+func (t *typeInfo) isScalar() bool {
+	switch t.kind {
+	case kindBool, kindInt, kindUint, kindFloat, kindComplex:
+		return true
+	}
+	return false
+}
+
+// pointee returns the type t points to, or t itself.
+func (t *typeInfo) pointee() *typeInfo {
+	if t.kind == kindPointer {
+		return t.elem
+	}
+	return t
+}
+
+type fieldInfo struct {
+	name         string
+	key          string
+	typ          *typeInfo
+	omitEmpty    bool
+	multiline    bool
+	defaultValue string
+	hasDefault   bool
+	format       string
+}
+
+type structInfo struct {
+	name   string
+	fields []fieldInfo
+}
+
+type generator struct {
+	specs map[string]*ast.TypeSpec
+	// methods are names of methods declared for each type
+	methods map[string]map[string]bool
+	// generating are names of types whose methods are generated
+	generating map[string]bool
+	naming     ntgo.NamingStrategy
+	// qualifiers are names of nt-go imports in the package
+	qualifiers map[string]bool
+	imports    map[string]bool
+
+	buf bytes.Buffer
+	seq int
+}
+
+// generate returns formatted source declaring methods of typeNames found in the package in dir.
+// Keys of fields without nt tag are derived with naming in the same manner as NamingStrategy of Marshaller.
+func generate(dir string, typeNames []string, naming ntgo.NamingStrategy) ([]byte, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected a package in %s, found %d", dir, len(pkgs))
+	}
+
+	g := &generator{
+		specs:      map[string]*ast.TypeSpec{},
+		methods:    map[string]map[string]bool{},
+		generating: map[string]bool{},
+		naming:     naming,
+		qualifiers: map[string]bool{},
+		imports:    map[string]bool{},
+	}
+
+	var pkgName string
+	for name, pkg := range pkgs {
+		pkgName = name
+		for _, file := range pkg.Files {
+			g.collect(file)
+		}
+	}
+
+	for i, name := range typeNames {
+		typeNames[i] = strings.TrimSpace(name)
+		g.generating[typeNames[i]] = true
+	}
+
+	structs := []*structInfo{}
+	for _, name := range typeNames {
+		s, err := g.parseStruct(name)
+		if err != nil {
+			return nil, err
+		}
+		structs = append(structs, s)
+	}
+
+	for _, s := range structs {
+		g.writeUnmarshal(s)
+		g.writeMarshal(s)
+	}
+
+	return g.source(pkgName)
+}
+
+// collect records type declarations, their methods and nt-go imports in file.
+func (g *generator) collect(file *ast.File) {
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		if path != ntgoPath {
+			continue
+		}
+		name := "ntgo"
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		g.qualifiers[name] = true
+	}
+
+	for _, decl := range file.Decls {
+		if funcDecl, ok := decl.(*ast.FuncDecl); ok {
+			g.collectMethod(funcDecl)
+			continue
+		}
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			g.specs[typeSpec.Name.Name] = typeSpec
+		}
+	}
+}
+
+// collectMethod records method declared by decl.
+// MarshalNestedText with pointer receiver is not recorded, since it is called for values that are not addressable.
+func (g *generator) collectMethod(decl *ast.FuncDecl) {
+	if decl.Recv == nil || len(decl.Recv.List) != 1 {
+		return
+	}
+	receiver := decl.Recv.List[0].Type
+	star, pointer := receiver.(*ast.StarExpr)
+	if pointer {
+		receiver = star.X
+	}
+	ident, ok := receiver.(*ast.Ident)
+	if !ok || (pointer && decl.Name.Name == "MarshalNestedText") {
+		return
+	}
+	if g.methods[ident.Name] == nil {
+		g.methods[ident.Name] = map[string]bool{}
+	}
+	g.methods[ident.Name][decl.Name.Name] = true
+}
+
+func (g *generator) parseStruct(name string) (*structInfo, error) {
+	spec, ok := g.specs[name]
+	if !ok {
+		return nil, fmt.Errorf("type %s is not found", name)
+	}
+	structType, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		return nil, fmt.Errorf("type %s is not a struct", name)
+	}
+
+	s := &structInfo{name: name}
+	for _, astField := range structType.Fields.List {
+		tagValue := ""
+		if astField.Tag != nil {
+			tag, _ := strconv.Unquote(astField.Tag.Value)
+			tagValue = reflect.StructTag(tag).Get("nt")
+		}
+		if tagValue == "-" {
+			continue
+		}
+		if len(astField.Names) == 0 {
+			return nil, fmt.Errorf("%s: embedded field %s is not supported", name, types.ExprString(astField.Type))
+		}
+
+		typ, err := g.resolve(astField.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %v", name, astField.Names[0].Name, err)
+		}
+
+		for _, fieldName := range astField.Names {
+			if !fieldName.IsExported() {
+				continue
+			}
+
+			tagValues := strings.Split(tagValue, ",")
+			f := fieldInfo{name: fieldName.Name, key: tagValues[0], typ: typ}
+			if f.key == "" {
+				f.key = f.name
+				if g.naming != nil {
+					f.key = g.naming(f.name)
+				}
+			}
+			for _, option := range tagValues[1:] {
+				switch {
+				case option == "omitempty":
+					f.omitEmpty = true
+				case option == "multilinestrings":
+					f.multiline = true
+				case strings.HasPrefix(option, "default="):
+					f.defaultValue = strings.TrimPrefix(option, "default=")
+					f.hasDefault = true
+				case strings.HasPrefix(option, "format="):
+					f.format = strings.TrimPrefix(option, "format=")
+				default:
+					return nil, fmt.Errorf("%s.%s: tag option %q is not supported", name, f.name, option)
+				}
+			}
+			s.fields = append(s.fields, f)
+		}
+	}
+	return s, nil
+}
+
+// resolve returns the description of type expression expr.
+func (g *generator) resolve(expr ast.Expr) (*typeInfo, error) {
+	switch x := expr.(type) {
+	case *ast.Ident:
+		if t, ok := basicTypes[x.Name]; ok {
+			return &typeInfo{kind: t.kind, expr: x.Name, bits: t.bits}, nil
+		}
+		spec, ok := g.specs[x.Name]
+		if !ok {
+			return nil, fmt.Errorf("type %s is not supported", x.Name)
+		}
+		if _, ok := spec.Type.(*ast.StructType); ok {
+			methods := g.methods[x.Name]
+			if !g.generating[x.Name] && !(methods["MarshalNestedText"] && methods["UnmarshalNestedText"]) {
+				return nil, fmt.Errorf("type %s must be given to -type as well, or implement ntgo.NestedTextMarshaler and ntgo.NestedTextUnmarshaler", x.Name)
+			}
+			return &typeInfo{kind: kindStruct, expr: x.Name}, nil
+		}
+		underlying, err := g.resolve(spec.Type)
+		if err != nil {
+			return nil, err
+		}
+		named := *underlying
+		named.expr = x.Name
+		return &named, nil
+	case *ast.StarExpr:
+		elem, err := g.resolve(x.X)
+		if err != nil {
+			return nil, err
+		}
+		if elem.kind == kindPointer {
+			return nil, fmt.Errorf("type %s is not supported", types.ExprString(expr))
+		}
+		return &typeInfo{kind: kindPointer, expr: "*" + elem.expr, elem: elem}, nil
+	case *ast.ArrayType:
+		if x.Len != nil {
+			return nil, fmt.Errorf("array %s is not supported", types.ExprString(expr))
+		}
+		elem, err := g.resolve(x.Elt)
+		if err != nil {
+			return nil, err
+		}
+		return &typeInfo{kind: kindSlice, expr: "[]" + elem.expr, elem: elem}, nil
+	case *ast.MapType:
+		if key, ok := x.Key.(*ast.Ident); !ok || key.Name != "string" {
+			return nil, fmt.Errorf("map key of %s is not supported", types.ExprString(expr))
+		}
+		elem, err := g.resolve(x.Value)
+		if err != nil {
+			return nil, err
+		}
+		return &typeInfo{kind: kindMap, expr: "map[string]" + elem.expr, elem: elem}, nil
+	case *ast.SelectorExpr:
+		if pkg, ok := x.X.(*ast.Ident); ok && g.qualifiers[pkg.Name] && x.Sel.Name == "MultilineStrings" {
+			return &typeInfo{kind: kindSlice, expr: "ntgo.MultilineStrings", elem: &typeInfo{kind: kindString, expr: "string"}}, nil
+		}
+	}
+	return nil, fmt.Errorf("type %s is not supported", types.ExprString(expr))
+}
+
+// parsedTypes are types of values returned by strconv.Parse functions.
+var parsedTypes = map[typeKind]string{
+	kindBool:    "bool",
+	kindInt:     "int64",
+	kindUint:    "uint64",
+	kindFloat:   "float64",
+	kindComplex: "complex128",
+}
+
+var basicTypes = map[string]typeInfo{
+	"string":     {kind: kindString},
+	"bool":       {kind: kindBool},
+	"int":        {kind: kindInt},
+	"int8":       {kind: kindInt, bits: 8},
+	"int16":      {kind: kindInt, bits: 16},
+	"int32":      {kind: kindInt, bits: 32},
+	"int64":      {kind: kindInt, bits: 64},
+	"uint":       {kind: kindUint},
+	"uint8":      {kind: kindUint, bits: 8},
+	"uint16":     {kind: kindUint, bits: 16},
+	"uint32":     {kind: kindUint, bits: 32},
+	"uint64":     {kind: kindUint, bits: 64},
+	"float32":    {kind: kindFloat, bits: 32},
+	"float64":    {kind: kindFloat, bits: 64},
+	"complex64":  {kind: kindComplex, bits: 64},
+	"complex128": {kind: kindComplex, bits: 128},
+}
+
+// convert returns the conversion of expr of type from into type to, or expr itself when they are the same.
+func convert(to string, from string, expr string) string {
+	if to == from {
+		return expr
+	}
+	return fmt.Sprintf("%s(%s)", to, expr)
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// name returns a variable name unique in the generated file.
+func (g *generator) name(prefix string) string {
+	g.seq++
+	return fmt.Sprintf("%s%d", prefix, g.seq)
+}
+
+func (g *generator) source(pkgName string) ([]byte, error) {
+	header := &bytes.Buffer{}
+	fmt.Fprintf(header, "// Code generated by ntgen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(header, "package %s\n\n", pkgName)
+	fmt.Fprintf(header, "import (\n")
+	imports := []string{}
+	for path := range g.imports {
+		imports = append(imports, path)
+	}
+	sort.Strings(imports)
+	for _, path := range imports {
+		fmt.Fprintf(header, "\t%q\n", path)
+	}
+	fmt.Fprintf(header, "\n\tntgo %q\n)\n", ntgoPath)
+
+	src := append(header.Bytes(), g.buf.Bytes()...)
+	formatted, err := format.Source(src)
+	if err != nil {
+		return nil, fmt.Errorf("generated code is invalid: %v\n%s", err, src)
+	}
+	return formatted, nil
+}
+
+// writeUnmarshal writes UnmarshalNestedText of s.
+// Semantics follow the reflective decoder of Marshaller.
+func (g *generator) writeUnmarshal(s *structInfo) {
+	g.printf("\n// UnmarshalNestedText implements ntgo.NestedTextUnmarshaler.\n")
+	g.printf("func (s *%s) UnmarshalNestedText(value *ntgo.Value) error {\n", s.name)
+	g.printf("if value.Type != ntgo.ValueTypeDictionary {\n")
+	g.printf("if value.Type == ntgo.ValueTypeString && value.String == \"\" {\nreturn nil\n}\n")
+	g.printf("return ntgo.NewTypeMismatchError(value, \"dictionary\")\n}\n\n")
+	if len(s.fields) == 0 {
+		g.printf("return nil\n}\n")
+		return
+	}
+
+	keys := []string{}
+	hasDefault := false
+	for _, f := range s.fields {
+		keys = append(keys, strconv.Quote(f.key))
+		hasDefault = hasDefault || f.hasDefault
+	}
+	if hasDefault {
+		g.printf("found := map[string]bool{}\n")
+	}
+
+	// keys are matched in the same manner as Marshaller, see ntgo.MatchFieldKey
+	g.printf("for _, key := range value.Keys() {\n")
+	g.printf("fieldKey, ok := ntgo.MatchFieldKey(key, []string{%s})\n", strings.Join(keys, ", "))
+	g.printf("if !ok {\ncontinue\n}\n")
+	if hasDefault {
+		g.printf("found[fieldKey] = true\n")
+	}
+	g.printf("child := value.Dictionary[key]\n")
+	g.printf("switch fieldKey {\n")
+	for _, f := range s.fields {
+		name := f.name
+		g.printf("case %q:\n", f.key)
+		g.writeDecode(f.typ, "child", "s."+f.name, func(err string) string {
+			return fmt.Sprintf("ntgo.WrapDecodeError(%s, %q, key)", err, name)
+		})
+	}
+	g.printf("}\n}\n")
+
+	for _, f := range s.fields {
+		if !f.hasDefault {
+			continue
+		}
+		f := f
+		child := g.name("child")
+		g.printf("if !found[%q] {\n", f.key)
+		g.printf("%s := &ntgo.Value{Type: ntgo.ValueTypeString, String: %q}\n", child, f.defaultValue)
+		g.writeDecode(f.typ, child, "s."+f.name, func(err string) string {
+			return fmt.Sprintf("ntgo.WrapDecodeError(%s, %q, %q)", err, f.name, f.key)
+		})
+		g.printf("}\n")
+	}
+
+	g.printf("return nil\n}\n")
+}
+
+// writeDecode writes statements storing src of *ntgo.Value into dst.
+// wrap returns the expression of error to be returned.
+func (g *generator) writeDecode(t *typeInfo, src string, dst string, wrap func(string) string) {
+	switch {
+	case t.kind == kindString:
+		g.imports["strings"] = true
+		g.printf("switch %s.Type {\n", src)
+		g.printf("case ntgo.ValueTypeText:\n%s = %s\n", dst, convert(t.expr, "string", "strings.Join("+src+".Text, \"\")"))
+		g.printf("case ntgo.ValueTypeString:\n%s = %s\n", dst, convert(t.expr, "string", src+".String"))
+		g.printf("default:\nreturn %s\n}\n", wrap(fmt.Sprintf("ntgo.NewTypeMismatchError(%s, \"string or text\")", src)))
+	case t.isScalar():
+		g.writeDecodeScalar(t, src, dst, wrap)
+	case t.kind == kindStruct:
+		// decoded into a new value to replace the existing one
+		decoded := g.name("decoded")
+		g.printf("var %s %s\n", decoded, t.expr)
+		g.printf("if err := %s.UnmarshalNestedText(%s); err != nil {\nreturn %s\n}\n", decoded, src, wrap("err"))
+		g.printf("%s = %s\n", dst, decoded)
+	case t.kind == kindPointer:
+		// empty value is nil as it is written for nil pointer
+		p := g.name("p")
+		g.printf("if %s.Type == ntgo.ValueTypeString && %s.String == \"\" {\n%s = nil\n} else {\n", src, src, dst)
+		g.printf("%s := new(%s)\n", p, t.elem.expr)
+		g.writeDecode(t.elem, src, "*"+p, wrap)
+		g.printf("%s = %s\n}\n", dst, p)
+	case t.kind == kindSlice:
+		g.writeDecodeSlice(t, src, dst, wrap)
+	case t.kind == kindMap:
+		g.writeDecodeMap(t, src, dst, wrap)
+	}
+}
+
+func (g *generator) writeDecodeScalar(t *typeInfo, src string, dst string, wrap func(string) string) {
+	g.imports["strconv"] = true
+	g.imports["strings"] = true
+
+	g.printf("if %s.Type != ntgo.ValueTypeString {\nreturn %s\n}\n", src, wrap(fmt.Sprintf("ntgo.NewTypeMismatchError(%s, \"string\")", src)))
+
+	// empty value is stored as zero value
+	parsed := g.name("parsed")
+	input := g.name("input")
+	g.printf("var %s %s\n", parsed, parsedTypes[t.kind])
+	g.printf("if %s := strings.TrimSpace(%s.String); %s != \"\" {\nvar err error\n", input, src, input)
+	switch t.kind {
+	case kindBool:
+		g.printf("%s, err = strconv.ParseBool(%s)\n", parsed, input)
+	case kindInt:
+		g.printf("%s, err = strconv.ParseInt(%s, 10, %d)\n", parsed, input, t.bits)
+	case kindUint:
+		g.printf("%s, err = strconv.ParseUint(%s, 10, %d)\n", parsed, input, t.bits)
+	case kindFloat:
+		g.printf("%s, err = strconv.ParseFloat(%s, %d)\n", parsed, input, t.bits)
+	case kindComplex:
+		g.printf("%s, err = strconv.ParseComplex(%s, %d)\n", parsed, input, t.bits)
+	}
+	g.printf("if err != nil {\nreturn %s\n}\n}\n", wrap(fmt.Sprintf("&ntgo.DecodeError{Line: %s.Line, File: %s.File, Err: err}", src, src)))
+	g.printf("%s = %s\n", dst, convert(t.expr, parsedTypes[t.kind], parsed))
+}
+
+func (g *generator) writeDecodeSlice(t *typeInfo, src string, dst string, wrap func(string) string) {
+	list := g.name("list")
+	i := g.name("i")
+	child := g.name("child")
+	element := g.name("element")
+	indexed := func(err string) string {
+		return wrap(fmt.Sprintf("ntgo.WrapDecodeErrorWithIndex(%s, %s)", err, i))
+	}
+	elem := t.elem
+
+	g.printf("%s := make(%s, 0, len(%s.List))\n", list, t.expr, src)
+
+	switch pointee := elem.pointee(); {
+	case pointee.kind == kindString:
+		// a string becomes a single element, and text becomes its lines
+		appendString := func(str string) {
+			if elem.kind == kindPointer {
+				g.printf("%s := %s\n", element, convert(pointee.expr, "string", str))
+				g.printf("%s = append(%s, &%s)\n", list, list, element)
+			} else {
+				g.printf("%s = append(%s, %s)\n", list, list, convert(elem.expr, "string", str))
+			}
+		}
+		g.printf("switch %s.Type {\n", src)
+		g.printf("case ntgo.ValueTypeString:\n")
+		appendString(src + ".String")
+		g.printf("case ntgo.ValueTypeText:\nfor _, %s := range %s.Text {\n", child, src)
+		appendString(child)
+		g.printf("}\n")
+		g.printf("case ntgo.ValueTypeList:\nfor %s, %s := range %s.List {\n", i, child, src)
+		g.printf("if %s.Type != ntgo.ValueTypeString {\nreturn %s\n}\n", child, indexed(fmt.Sprintf("ntgo.NewTypeMismatchError(%s, \"string\")", child)))
+		appendString(child + ".String")
+		g.printf("}\n")
+		g.printf("default:\nreturn %s\n}\n", wrap(fmt.Sprintf("ntgo.NewTypeMismatchError(%s, \"list, text or string\")", src)))
+	case pointee.isScalar():
+		// a string becomes a single element, and empty value leaves the slice empty
+		g.printf("switch {\n")
+		g.printf("case %s.Type == ntgo.ValueTypeString && %s.String == \"\":\n", src, src)
+		g.printf("case %s.Type == ntgo.ValueTypeString:\n", src)
+		g.printf("var %s %s\n", element, elem.expr)
+		g.writeDecode(elem, src, element, wrap)
+		g.printf("%s = append(%s, %s)\n", list, list, element)
+		g.printf("case %s.Type == ntgo.ValueTypeList:\n", src)
+		g.printf("for %s, %s := range %s.List {\n", i, child, src)
+		g.printf("var %s %s\n", element, elem.expr)
+		g.writeDecode(elem, child, element, indexed)
+		g.printf("%s = append(%s, %s)\n", list, list, element)
+		g.printf("}\n")
+		g.printf("default:\nreturn %s\n}\n", wrap(fmt.Sprintf("ntgo.NewTypeMismatchError(%s, \"list or string\")", src)))
+	default:
+		if pointee.kind == kindSlice {
+			// empty value leaves the slice empty
+			g.printf("if %s.Type != ntgo.ValueTypeString || %s.String != \"\" {\n", src, src)
+		}
+		g.printf("if %s.Type != ntgo.ValueTypeList {\nreturn %s\n}\n", src, wrap(fmt.Sprintf("ntgo.NewTypeMismatchError(%s, \"list\")", src)))
+		g.printf("for %s, %s := range %s.List {\n", i, child, src)
+		g.printf("var %s %s\n", element, elem.expr)
+		g.writeDecode(elem, child, element, indexed)
+		g.printf("%s = append(%s, %s)\n", list, list, element)
+		g.printf("}\n")
+		if pointee.kind == kindSlice {
+			g.printf("}\n")
+		}
+	}
+
+	g.printf("%s = %s\n", dst, list)
+}
+
+func (g *generator) writeDecodeMap(t *typeInfo, src string, dst string, wrap func(string) string) {
+	g.imports["fmt"] = true
+
+	m := g.name("m")
+	key := g.name("key")
+	element := g.name("element")
+	keyed := func(err string) string {
+		return wrap(fmt.Sprintf("ntgo.WrapDecodeError(%s, fmt.Sprintf(\"[%%q]\", %s), %s)", err, key, key))
+	}
+
+	g.printf("if %s.Type == ntgo.ValueTypeDictionary {\n", src)
+	g.printf("%s := make(%s, len(%s.Dictionary))\n", m, t.expr, src)
+	g.printf("for _, %s := range %s.Keys() {\n", key, src)
+	g.printf("var %s %s\n", element, t.elem.expr)
+	g.writeDecode(t.elem, src+".Dictionary["+key+"]", element, keyed)
+	g.printf("%s[%s] = %s\n", m, key, element)
+	g.printf("}\n")
+	g.printf("%s = %s\n", dst, m)
+	g.printf("} else if %s.Type != ntgo.ValueTypeString || %s.String != \"\" {\n", src, src)
+	g.printf("return %s\n}\n", wrap(fmt.Sprintf("ntgo.NewTypeMismatchError(%s, \"dictionary\")", src)))
+}
+
+// writeMarshal writes MarshalNestedText of s.
+// Semantics follow the reflective encoder of Marshaller.
+func (g *generator) writeMarshal(s *structInfo) {
+	g.printf("\n// MarshalNestedText implements ntgo.NestedTextMarshaler.\n")
+	g.printf("func (s %s) MarshalNestedText() (*ntgo.Value, error) {\n", s.name)
+	g.printf("value := &ntgo.Value{Type: ntgo.ValueTypeDictionary}\n")
+
+	for _, f := range s.fields {
+		child := g.name("child")
+		g.printf("{\nvar %s *ntgo.Value\n", child)
+		g.writeEncode(f.typ, "s."+f.name, child, f.multiline, f.format)
+		if f.omitEmpty {
+			g.printf("if %s != nil {\nvalue.Set(%q, %s)\n}\n", child, f.key, child)
+		} else {
+			g.printf("if %s == nil {\n%s = &ntgo.Value{Type: ntgo.ValueTypeString}\n}\n", child, child)
+			g.printf("value.Set(%q, %s)\n", f.key, child)
+		}
+		g.printf("}\n")
+	}
+
+	g.printf("return value, nil\n}\n")
+}
+
+// writeEncode writes statements storing src into dst of *ntgo.Value.
+// dst is left nil when src does not exist in the same manner as omitempty.
+func (g *generator) writeEncode(t *typeInfo, src string, dst string, multiline bool, format string) {
+	switch {
+	case t.kind == kindString:
+		g.imports["strings"] = true
+		lines := g.name("lines")
+		g.printf("if %s != \"\" {\n", src)
+		if multiline {
+			g.printf("if %s := strings.Split(%s, \"\\n\"); true {\n", lines, convert("string", t.expr, src))
+		} else {
+			g.printf("if %s := strings.Split(%s, \"\\n\"); len(%s) > 1 {\n", lines, convert("string", t.expr, src), lines)
+		}
+		g.printf("%s = ntgo.NewTextValue(%s)\n", dst, lines)
+		g.printf("} else {\n%s = &ntgo.Value{Type: ntgo.ValueTypeString, String: %s}\n}\n", dst, convert("string", t.expr, src))
+		g.printf("}\n")
+	case t.isScalar():
+		g.printf("%s = &ntgo.Value{Type: ntgo.ValueTypeString, String: %s}\n", dst, g.formatScalar(t, src, format))
+	case t.kind == kindStruct:
+		encoded := g.name("encoded")
+		g.printf("%s, err := %s.MarshalNestedText()\n", encoded, src)
+		g.printf("if err != nil {\nreturn nil, err\n}\n")
+		g.printf("if len(%s.Dictionary) > 0 {\n%s = %s\n}\n", encoded, dst, encoded)
+	case t.kind == kindPointer:
+		g.printf("if %s != nil {\n", src)
+		g.writeEncode(t.elem, "(*"+src+")", dst, multiline, format)
+		g.printf("}\n")
+	case t.kind == kindSlice:
+		element := g.name("element")
+		g.printf("if len(%s) > 0 {\n", src)
+		if multiline && t.elem.pointee().kind == kindString {
+			// all lines of elements are joined into a text
+			g.imports["strings"] = true
+			lines := g.name("lines")
+			g.printf("%s := []string{}\n", lines)
+			g.printf("for _, %s := range %s {\n", element, src)
+			if t.elem.kind == kindPointer {
+				g.printf("if %s == nil {\ncontinue\n}\n", element)
+				element = "*" + element
+			}
+			g.printf("%s = append(%s, strings.Split(%s, \"\\n\")...)\n", lines, lines, convert("string", t.elem.pointee().expr, element))
+			g.printf("}\n")
+			g.printf("%s = ntgo.NewTextValue(%s)\n", dst, lines)
+		} else {
+			list := g.name("list")
+			item := g.name("item")
+			g.printf("%s := &ntgo.Value{Type: ntgo.ValueTypeList}\n", list)
+			g.printf("for _, %s := range %s {\n", element, src)
+			g.printf("var %s *ntgo.Value\n", item)
+			g.writeEncode(t.elem, element, item, multiline, format)
+			g.printf("if %s == nil {\n%s = &ntgo.Value{Type: ntgo.ValueTypeString}\n}\n", item, item)
+			g.printf("%s.List = append(%s.List, %s)\n", list, list, item)
+			g.printf("}\n")
+			g.printf("%s = %s\n", dst, list)
+		}
+		g.printf("}\n")
+	case t.kind == kindMap:
+		g.imports["sort"] = true
+		dict := g.name("dict")
+		keys := g.name("keys")
+		key := g.name("key")
+		item := g.name("item")
+		g.printf("if len(%s) > 0 {\n", src)
+		g.printf("%s := make([]string, 0, len(%s))\n", keys, src)
+		g.printf("for %s := range %s {\n%s = append(%s, %s)\n}\n", key, src, keys, keys, key)
+		g.printf("sort.Strings(%s)\n", keys)
+		g.printf("%s := &ntgo.Value{Type: ntgo.ValueTypeDictionary}\n", dict)
+		g.printf("for _, %s := range %s {\n", key, keys)
+		g.printf("var %s *ntgo.Value\n", item)
+		g.writeEncode(t.elem, src+"["+key+"]", item, multiline, format)
+		g.printf("if %s == nil {\n%s = &ntgo.Value{Type: ntgo.ValueTypeString}\n}\n", item, item)
+		g.printf("%s.Set(%s, %s)\n", dict, key, item)
+		g.printf("}\n")
+		g.printf("%s = %s\n", dst, dict)
+		g.printf("}\n")
+	}
+}
+
+// formatScalar returns the expression formatting src in the same manner as Marshaller.
+func (g *generator) formatScalar(t *typeInfo, src string, format string) string {
+	if format != "" {
+		g.imports["fmt"] = true
+		return fmt.Sprintf("fmt.Sprintf(%q, %s)", format, src)
+	}
+
+	g.imports["strconv"] = true
+	switch t.kind {
+	case kindBool:
+		return fmt.Sprintf("strconv.FormatBool(%s)", convert("bool", t.expr, src))
+	case kindInt:
+		return fmt.Sprintf("strconv.FormatInt(%s, 10)", convert("int64", t.expr, src))
+	case kindUint:
+		return fmt.Sprintf("strconv.FormatUint(%s, 10)", convert("uint64", t.expr, src))
+	case kindFloat:
+		return fmt.Sprintf("strconv.FormatFloat(%s, 'g', -1, %d)", convert("float64", t.expr, src), t.bits)
+	case kindComplex:
+		return fmt.Sprintf("strconv.FormatComplex(%s, 'g', -1, %d)", convert("complex128", t.expr, src), t.bits)
+	}
+	return ""
+}
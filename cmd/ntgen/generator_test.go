@@ -0,0 +1,117 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	generateSource := func(t *testing.T, source string, typeNames ...string) ([]byte, error) {
+		dir, err := ioutil.TempDir("", "ntgen")
+		assert.Nil(t, err)
+		defer os.RemoveAll(dir)
+
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "types.go"), []byte(source), 0644))
+		return generate(dir, typeNames, nil)
+	}
+
+	t.Run("fixtures", func(t *testing.T) {
+		dir := filepath.Join("internal", "fixtures", "generated")
+		source, err := ioutil.ReadFile(filepath.Join(dir, "fixtures.go"))
+		assert.Nil(t, err)
+		directives := regexp.MustCompile(`(?m)^//go:generate .* -type (\S+)(?: -naming (\S+))?(?: -output (\S+))?$`).FindAllSubmatch(source, -1)
+		assert.Equal(t, 2, len(directives))
+
+		t.Run("should be generated with the current generator", func(t *testing.T) {
+			for _, directive := range directives {
+				output := "fixtures_nt.go"
+				if len(directive[3]) > 0 {
+					output = string(directive[3])
+				}
+				expected, err := ioutil.ReadFile(filepath.Join(dir, output))
+				assert.Nil(t, err)
+
+				actual, err := generate(dir, strings.Split(string(directive[1]), ","), namingStrategies[string(directive[2])])
+				assert.Nil(t, err)
+				assert.Equal(t, string(expected), string(actual), "run go generate ./cmd/ntgen/...")
+			}
+		})
+	})
+
+	t.Run("named types", func(t *testing.T) {
+		source := `package sample
+
+type Level int
+type Names []string
+
+type Config struct {
+	Level Level ` + "`nt:\"level\"`" + `
+	Names Names ` + "`nt:\"names\"`" + `
+}
+`
+		t.Run("should be converted from their underlying types", func(t *testing.T) {
+			generated, err := generateSource(t, source, "Config")
+			assert.Nil(t, err)
+			assert.Contains(t, string(generated), "s.Level = Level(parsed")
+			assert.Contains(t, string(generated), "strconv.FormatInt(int64(s.Level), 10)")
+			assert.Contains(t, string(generated), "make(Names, 0")
+		})
+	})
+
+	t.Run("unsupported declarations", func(t *testing.T) {
+		cases := map[string]string{
+			"embedded field": "type Base struct{}\ntype Config struct {\n\tBase\n}\n",
+			"required":       "type Config struct {\n\tName string `nt:\"name,required\"`\n}\n",
+			"interface":      "type Config struct {\n\tAny interface{}\n}\n",
+			"array":          "type Config struct {\n\tNames [2]string\n}\n",
+			"map key":        "type Config struct {\n\tPorts map[int]string\n}\n",
+			"foreign type":   "import \"time\"\ntype Config struct {\n\tWhen time.Time\n}\n",
+			"not a struct":   "type Config []string\n",
+			"not generated":  "type Inner struct{}\ntype Config struct {\n\tInner *Inner\n}\n",
+		}
+		for name, declarations := range cases {
+			t.Run(name+" should be an error", func(t *testing.T) {
+				_, err := generateSource(t, "package sample\n\n"+declarations, "Config")
+				assert.NotNil(t, err)
+			})
+		}
+	})
+
+	t.Run("struct type not generated", func(t *testing.T) {
+		t.Run("should be an error naming the type", func(t *testing.T) {
+			_, err := generateSource(t, "package sample\n\ntype Inner struct{}\ntype Config struct {\n\tInner Inner\n}\n", "Config")
+			assert.EqualError(t, err, "Config.Inner: type Inner must be given to -type as well, or implement ntgo.NestedTextMarshaler and ntgo.NestedTextUnmarshaler")
+		})
+
+		t.Run("should be accepted when it implements both of methods", func(t *testing.T) {
+			source := `package sample
+
+import ntgo "github.com/dolow/nt-go"
+
+type Inner struct{}
+
+func (i Inner) MarshalNestedText() (*ntgo.Value, error) { return nil, nil }
+func (i *Inner) UnmarshalNestedText(value *ntgo.Value) error { return nil }
+
+type Config struct {
+	Inner Inner
+}
+`
+			_, err := generateSource(t, source, "Config")
+			assert.Nil(t, err)
+		})
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		t.Run("should be an error", func(t *testing.T) {
+			_, err := generateSource(t, "package sample\n", "Config")
+			assert.EqualError(t, err, "type Config is not found")
+		})
+	})
+}
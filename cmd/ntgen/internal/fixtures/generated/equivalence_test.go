@@ -0,0 +1,324 @@
+package generated
+
+import (
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	ntgo "github.com/dolow/nt-go"
+	"github.com/dolow/nt-go/cmd/ntgen/internal/fixtures/plain"
+	"github.com/stretchr/testify/assert"
+)
+
+const sample = `
+string: hello
+string_ptr: world
+text:
+  > aaaa
+  > bbbb
+text_alias:
+  > aaaa alias
+  > bbbb alias
+dict:
+  dict_string: world
+  dict_text:
+    > dict text aaaa
+    > dict text bbbb
+dict_ptr:
+  dict_string: world pointer
+  dict_text:
+    > dict text aaaa pointer
+    > dict text bbbb pointer
+list_struct:
+  -
+    list_string: aaaa
+  -
+    list_string: bbbb
+list_ptr:
+  -
+    list_string: aaaa pointer
+  -
+    list_string: bbbb pointer
+list_of_list_struct:
+  -
+    -
+      list_string: aaaa nested
+    -
+      list_string: bbbb nested
+  -
+    -
+      list_string: cccc nested
+list_of_list_struct_pointer:
+  -
+    -
+      list_string: aaaa nested pointer
+  -
+    -
+      list_string: cccc nested pointer
+list_text:
+  -
+    > list text aaaa
+    > list text bbbb
+  -
+    > list text cccc
+list_string:
+  - list string aaaa
+  - list string bbbb
+list_string_pointer:
+  - list string pointer aaaa
+  - list string pointer bbbb
+not_omit_string:
+NoTag: no tag`
+
+type equivalenceCase struct {
+	name      string
+	content   string
+	plain     func() interface{}
+	generated func() interface{}
+	// naming is given to Marshaller for plain fixtures, and to ntgen for generated ones
+	naming ntgo.NamingStrategy
+}
+
+func (c equivalenceCase) marshaller() *ntgo.Marshaller {
+	return &ntgo.Marshaller{NamingStrategy: c.naming}
+}
+
+var equivalenceCases = []equivalenceCase{
+	{
+		name:      "sample",
+		content:   sample,
+		plain:     func() interface{} { return &plain.SampleStruct{} },
+		generated: func() interface{} { return &SampleStruct{} },
+	},
+	{
+		name:      "multiline strings",
+		content:   "str: single\nstr_ptr:\n  > line 1\n  > line 2\nstr_slice:\n  > aaaa\n  > bbbb\nstr_ptr_slice:\n  - cccc\n  - dddd",
+		plain:     func() interface{} { return &plain.SampleMultilineString{} },
+		generated: func() interface{} { return &SampleMultilineString{} },
+	},
+	{
+		name:      "numbers",
+		content:   "int: -1\nfloat: 1.5\nint_ptr: 2\nfloat_ptr: 2.5\nint_slice:\n  - 3\n  - 4\nfloat_slice: 3.5\nint_ptr_slice:\n  - 5\nfloat_ptr_slice:",
+		plain:     func() interface{} { return &plain.NumberStruct{} },
+		generated: func() interface{} { return &NumberStruct{} },
+	},
+	{
+		name:      "scalars",
+		content:   "int8: -8\nuint: 42\nuint16: 65535\nfloat64: 0.1\nbool: true\nbool_ptr: false\nuints:\n  - 1\n  - 255\nbools:\n  - true\nints:\n  -\n    - 1\n    - 2\n  -",
+		plain:     func() interface{} { return &plain.ScalarStruct{} },
+		generated: func() interface{} { return &ScalarStruct{} },
+	},
+	{
+		name:      "lossless numbers",
+		content:   "int: -9223372036854775808\nuint64: 18446744073709551615\nfloat32: 1e-45\nfloat64: 1.7976931348623157e+308\ncomplex64: (1+2i)\ncomplex128: -3i\nfloats:\n  - +Inf\n  - -0",
+		plain:     func() interface{} { return &plain.LosslessNumberStruct{} },
+		generated: func() interface{} { return &LosslessNumberStruct{} },
+	},
+	{
+		name:      "maps",
+		content:   "labels:\n  b: 2\n  a: 1\nservices:\n  web:\n    host: localhost\n    port: 80\n  db:\nnested:\n  x:\n    y:\n      - z\nhosts:\n  -\n    name: a\n  -\n    name: b",
+		plain:     func() interface{} { return &plain.MapStruct{} },
+		generated: func() interface{} { return &MapStruct{} },
+	},
+	{
+		name:      "defaults",
+		content:   "ratio: 0.125\ncomment: hello",
+		plain:     func() interface{} { return &plain.OptionStruct{} },
+		generated: func() interface{} { return &OptionStruct{} },
+	},
+	{
+		name:      "options",
+		content:   "port: 80\nhost: example.com\nratio: 1",
+		plain:     func() interface{} { return &plain.OptionStruct{} },
+		generated: func() interface{} { return &OptionStruct{} },
+	},
+	{
+		name:      "naming",
+		content:   "server_name: web\nhttp_port: 80\nTaggedKey: tagged\ndict:\n  dict_string: s",
+		plain:     func() interface{} { return &plain.NamingStruct{} },
+		generated: func() interface{} { return &NamingStruct{} },
+		naming:    ntgo.SnakeCase,
+	},
+	{
+		name:      "keys in different cases",
+		content:   "Server_Name: web\nHTTP_PORT: 80\ntaggedkey: tagged\nDICT:\n  Dict_String: s",
+		plain:     func() interface{} { return &plain.NamingStruct{} },
+		generated: func() interface{} { return &NamingStruct{} },
+		naming:    ntgo.SnakeCase,
+	},
+	{
+		name:      "fields of sample in different cases",
+		content:   "STRING: hello\nnotag: no tag\nDict:\n  DICT_STRING: world",
+		plain:     func() interface{} { return &plain.SampleStruct{} },
+		generated: func() interface{} { return &SampleStruct{} },
+	},
+}
+
+var mismatchCases = []equivalenceCase{
+	{
+		name:      "struct with string",
+		content:   "dict: world",
+		plain:     func() interface{} { return &plain.SampleStruct{} },
+		generated: func() interface{} { return &SampleStruct{} },
+	},
+	{
+		name:      "nested list element",
+		content:   "list_of_list_struct:\n  -\n    - aaaa",
+		plain:     func() interface{} { return &plain.SampleStruct{} },
+		generated: func() interface{} { return &SampleStruct{} },
+	},
+	{
+		name:      "list of strings with dictionary",
+		content:   "list_string:\n  -\n    key: value",
+		plain:     func() interface{} { return &plain.SampleStruct{} },
+		generated: func() interface{} { return &SampleStruct{} },
+	},
+	{
+		name:      "invalid number",
+		content:   "int_slice:\n  - 1\n  - one",
+		plain:     func() interface{} { return &plain.NumberStruct{} },
+		generated: func() interface{} { return &NumberStruct{} },
+	},
+	{
+		name:      "overflow",
+		content:   "int8: 128",
+		plain:     func() interface{} { return &plain.ScalarStruct{} },
+		generated: func() interface{} { return &ScalarStruct{} },
+	},
+	{
+		name:      "map element",
+		content:   "services:\n  web:\n    port: eighty",
+		plain:     func() interface{} { return &plain.MapStruct{} },
+		generated: func() interface{} { return &MapStruct{} },
+	},
+	{
+		name:      "invalid default",
+		content:   "host: localhost\nratio: 1\nport:\n  - 1",
+		plain:     func() interface{} { return &plain.OptionStruct{} },
+		generated: func() interface{} { return &OptionStruct{} },
+	},
+}
+
+// dump converts ref into a tree comparable across packages, nil is distinguished from empty.
+func dump(ref reflect.Value) interface{} {
+	switch ref.Kind() {
+	case reflect.Ptr:
+		if ref.IsNil() {
+			return "<nil>"
+		}
+		return []interface{}{"&", dump(ref.Elem())}
+	case reflect.Struct:
+		fields := map[string]interface{}{}
+		for i := 0; i < ref.NumField(); i++ {
+			fields[ref.Type().Field(i).Name] = dump(ref.Field(i))
+		}
+		return fields
+	case reflect.Slice:
+		if ref.IsNil() {
+			return "<nil>"
+		}
+		list := []interface{}{}
+		for i := 0; i < ref.Len(); i++ {
+			list = append(list, dump(ref.Index(i)))
+		}
+		return list
+	case reflect.Map:
+		if ref.IsNil() {
+			return "<nil>"
+		}
+		dict := map[string]interface{}{}
+		for _, key := range ref.MapKeys() {
+			dict[key.String()] = dump(ref.MapIndex(key))
+		}
+		return dict
+	}
+	return fmt.Sprintf("%v %#v", ref.Kind(), ref.Interface())
+}
+
+func TestEquivalence(t *testing.T) {
+	t.Run("when decoding", func(t *testing.T) {
+		for _, c := range equivalenceCases {
+			t.Run(c.name+" should store the same values as reflective decoding", func(t *testing.T) {
+				expected := c.plain()
+				actual := c.generated()
+				assert.Nil(t, c.marshaller().Marshal(c.content, expected))
+				assert.Nil(t, c.marshaller().Marshal(c.content, actual))
+				assert.Equal(t, dump(reflect.ValueOf(expected)), dump(reflect.ValueOf(actual)))
+			})
+		}
+
+		t.Run("nested struct should be replaced as reflective decoding", func(t *testing.T) {
+			expected := &plain.SampleStruct{Dict: plain.SampleDict{DictString: "old", DictText: ntgo.MultilineStrings{"old"}}}
+			actual := &SampleStruct{Dict: SampleDict{DictString: "old", DictText: ntgo.MultilineStrings{"old"}}}
+			assert.Nil(t, ntgo.Marshal("dict:\n  dict_string: new", expected))
+			assert.Nil(t, ntgo.Marshal("dict:\n  dict_string: new", actual))
+			assert.Equal(t, dump(reflect.ValueOf(expected)), dump(reflect.ValueOf(actual)))
+			assert.Nil(t, actual.Dict.DictText)
+		})
+
+		for _, c := range mismatchCases {
+			t.Run(c.name+" should return the same error as reflective decoding", func(t *testing.T) {
+				expected := ntgo.Marshal(c.content, c.plain())
+				actual := ntgo.Marshal(c.content, c.generated())
+				assert.NotNil(t, expected)
+				assert.Equal(t, expected, actual)
+			})
+		}
+	})
+
+	t.Run("when encoding", func(t *testing.T) {
+		for _, c := range equivalenceCases {
+			t.Run(c.name+" should write the same document as reflective encoding", func(t *testing.T) {
+				expected := c.plain()
+				actual := c.generated()
+				c.marshaller().Marshal(c.content, expected)
+				c.marshaller().Marshal(c.content, actual)
+				assert.Equal(t, terminated(unmarshal(c, expected)), unmarshal(c, actual))
+			})
+
+			t.Run(c.name+" with zero value should write the same document as reflective encoding", func(t *testing.T) {
+				assert.Equal(t, terminated(unmarshal(c, c.plain())), unmarshal(c, c.generated()))
+			})
+		}
+
+		t.Run("arbitrary numbers should be written as reflective encoding", func(t *testing.T) {
+			f := func(s plain.LosslessNumberStruct) bool {
+				return ntgo.Unmarshal(s) == ntgo.Unmarshal(LosslessNumberStruct(s))
+			}
+			assert.Nil(t, quick.Check(f, nil))
+		})
+	})
+
+	t.Run("fixtures should be identical to those of package plain", func(t *testing.T) {
+		normalize := func(path string, pkg string) string {
+			data, err := ioutil.ReadFile(path)
+			assert.Nil(t, err)
+			lines := []string{}
+			for _, line := range strings.Split(string(data), "\n") {
+				if strings.HasPrefix(line, "//") || strings.HasPrefix(line, "package ") {
+					continue
+				}
+				lines = append(lines, line)
+			}
+			return strings.TrimSpace(strings.Join(lines, "\n"))
+		}
+		assert.Equal(t, normalize("../plain/fixtures.go", "plain"), normalize("fixtures.go", "generated"))
+	})
+}
+
+// terminated adds line break to the empty value at the end of document written by reflective encoding,
+// which is left as a key followed by space.
+func terminated(document string) string {
+	if document != "" && !strings.HasSuffix(document, "\n") {
+		return strings.TrimSuffix(document, " ") + "\n"
+	}
+	return document
+}
+
+func unmarshal(c equivalenceCase, v interface{}) string {
+	document, _ := c.marshaller().Unmarshal(v)
+	return document
+}
@@ -0,0 +1,2486 @@
+// Code generated by ntgen. DO NOT EDIT.
+
+package generated
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	ntgo "github.com/dolow/nt-go"
+)
+
+// UnmarshalNestedText implements ntgo.NestedTextUnmarshaler.
+func (s *SampleDict) UnmarshalNestedText(value *ntgo.Value) error {
+	if value.Type != ntgo.ValueTypeDictionary {
+		if value.Type == ntgo.ValueTypeString && value.String == "" {
+			return nil
+		}
+		return ntgo.NewTypeMismatchError(value, "dictionary")
+	}
+
+	for _, key := range value.Keys() {
+		fieldKey, ok := ntgo.MatchFieldKey(key, []string{"dict_string", "dict_text"})
+		if !ok {
+			continue
+		}
+		child := value.Dictionary[key]
+		switch fieldKey {
+		case "dict_string":
+			switch child.Type {
+			case ntgo.ValueTypeText:
+				s.DictString = strings.Join(child.Text, "")
+			case ntgo.ValueTypeString:
+				s.DictString = child.String
+			default:
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string or text"), "DictString", key)
+			}
+		case "dict_text":
+			list1 := make(ntgo.MultilineStrings, 0, len(child.List))
+			switch child.Type {
+			case ntgo.ValueTypeString:
+				list1 = append(list1, child.String)
+			case ntgo.ValueTypeText:
+				for _, child3 := range child.Text {
+					list1 = append(list1, child3)
+				}
+			case ntgo.ValueTypeList:
+				for i2, child3 := range child.List {
+					if child3.Type != ntgo.ValueTypeString {
+						return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(ntgo.NewTypeMismatchError(child3, "string"), i2), "DictText", key)
+					}
+					list1 = append(list1, child3.String)
+				}
+			default:
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "list, text or string"), "DictText", key)
+			}
+			s.DictText = list1
+		}
+	}
+	return nil
+}
+
+// MarshalNestedText implements ntgo.NestedTextMarshaler.
+func (s SampleDict) MarshalNestedText() (*ntgo.Value, error) {
+	value := &ntgo.Value{Type: ntgo.ValueTypeDictionary}
+	{
+		var child5 *ntgo.Value
+		if s.DictString != "" {
+			if lines6 := strings.Split(s.DictString, "\n"); len(lines6) > 1 {
+				child5 = ntgo.NewTextValue(lines6)
+			} else {
+				child5 = &ntgo.Value{Type: ntgo.ValueTypeString, String: s.DictString}
+			}
+		}
+		if child5 == nil {
+			child5 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("dict_string", child5)
+	}
+	{
+		var child7 *ntgo.Value
+		if len(s.DictText) > 0 {
+			lines9 := []string{}
+			for _, element8 := range s.DictText {
+				lines9 = append(lines9, strings.Split(element8, "\n")...)
+			}
+			child7 = ntgo.NewTextValue(lines9)
+		}
+		if child7 == nil {
+			child7 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("dict_text", child7)
+	}
+	return value, nil
+}
+
+// UnmarshalNestedText implements ntgo.NestedTextUnmarshaler.
+func (s *SampleMultilineString) UnmarshalNestedText(value *ntgo.Value) error {
+	if value.Type != ntgo.ValueTypeDictionary {
+		if value.Type == ntgo.ValueTypeString && value.String == "" {
+			return nil
+		}
+		return ntgo.NewTypeMismatchError(value, "dictionary")
+	}
+
+	for _, key := range value.Keys() {
+		fieldKey, ok := ntgo.MatchFieldKey(key, []string{"str", "str_ptr", "str_slice", "str_ptr_slice"})
+		if !ok {
+			continue
+		}
+		child := value.Dictionary[key]
+		switch fieldKey {
+		case "str":
+			switch child.Type {
+			case ntgo.ValueTypeText:
+				s.Str = strings.Join(child.Text, "")
+			case ntgo.ValueTypeString:
+				s.Str = child.String
+			default:
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string or text"), "Str", key)
+			}
+		case "str_ptr":
+			if child.Type == ntgo.ValueTypeString && child.String == "" {
+				s.StrPtr = nil
+			} else {
+				p10 := new(string)
+				switch child.Type {
+				case ntgo.ValueTypeText:
+					*p10 = strings.Join(child.Text, "")
+				case ntgo.ValueTypeString:
+					*p10 = child.String
+				default:
+					return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string or text"), "StrPtr", key)
+				}
+				s.StrPtr = p10
+			}
+		case "str_slice":
+			list11 := make([]string, 0, len(child.List))
+			switch child.Type {
+			case ntgo.ValueTypeString:
+				list11 = append(list11, child.String)
+			case ntgo.ValueTypeText:
+				for _, child13 := range child.Text {
+					list11 = append(list11, child13)
+				}
+			case ntgo.ValueTypeList:
+				for i12, child13 := range child.List {
+					if child13.Type != ntgo.ValueTypeString {
+						return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(ntgo.NewTypeMismatchError(child13, "string"), i12), "StrSlice", key)
+					}
+					list11 = append(list11, child13.String)
+				}
+			default:
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "list, text or string"), "StrSlice", key)
+			}
+			s.StrSlice = list11
+		case "str_ptr_slice":
+			list15 := make([]*string, 0, len(child.List))
+			switch child.Type {
+			case ntgo.ValueTypeString:
+				element18 := child.String
+				list15 = append(list15, &element18)
+			case ntgo.ValueTypeText:
+				for _, child17 := range child.Text {
+					element18 := child17
+					list15 = append(list15, &element18)
+				}
+			case ntgo.ValueTypeList:
+				for i16, child17 := range child.List {
+					if child17.Type != ntgo.ValueTypeString {
+						return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(ntgo.NewTypeMismatchError(child17, "string"), i16), "StrPtrSlice", key)
+					}
+					element18 := child17.String
+					list15 = append(list15, &element18)
+				}
+			default:
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "list, text or string"), "StrPtrSlice", key)
+			}
+			s.StrPtrSlice = list15
+		}
+	}
+	return nil
+}
+
+// MarshalNestedText implements ntgo.NestedTextMarshaler.
+func (s SampleMultilineString) MarshalNestedText() (*ntgo.Value, error) {
+	value := &ntgo.Value{Type: ntgo.ValueTypeDictionary}
+	{
+		var child19 *ntgo.Value
+		if s.Str != "" {
+			if lines20 := strings.Split(s.Str, "\n"); true {
+				child19 = ntgo.NewTextValue(lines20)
+			} else {
+				child19 = &ntgo.Value{Type: ntgo.ValueTypeString, String: s.Str}
+			}
+		}
+		if child19 == nil {
+			child19 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("str", child19)
+	}
+	{
+		var child21 *ntgo.Value
+		if s.StrPtr != nil {
+			if (*s.StrPtr) != "" {
+				if lines22 := strings.Split((*s.StrPtr), "\n"); true {
+					child21 = ntgo.NewTextValue(lines22)
+				} else {
+					child21 = &ntgo.Value{Type: ntgo.ValueTypeString, String: (*s.StrPtr)}
+				}
+			}
+		}
+		if child21 == nil {
+			child21 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("str_ptr", child21)
+	}
+	{
+		var child23 *ntgo.Value
+		if len(s.StrSlice) > 0 {
+			lines25 := []string{}
+			for _, element24 := range s.StrSlice {
+				lines25 = append(lines25, strings.Split(element24, "\n")...)
+			}
+			child23 = ntgo.NewTextValue(lines25)
+		}
+		if child23 == nil {
+			child23 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("str_slice", child23)
+	}
+	{
+		var child26 *ntgo.Value
+		if len(s.StrPtrSlice) > 0 {
+			lines28 := []string{}
+			for _, element27 := range s.StrPtrSlice {
+				if element27 == nil {
+					continue
+				}
+				lines28 = append(lines28, strings.Split(*element27, "\n")...)
+			}
+			child26 = ntgo.NewTextValue(lines28)
+		}
+		if child26 == nil {
+			child26 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("str_ptr_slice", child26)
+	}
+	return value, nil
+}
+
+// UnmarshalNestedText implements ntgo.NestedTextUnmarshaler.
+func (s *SampleListElement) UnmarshalNestedText(value *ntgo.Value) error {
+	if value.Type != ntgo.ValueTypeDictionary {
+		if value.Type == ntgo.ValueTypeString && value.String == "" {
+			return nil
+		}
+		return ntgo.NewTypeMismatchError(value, "dictionary")
+	}
+
+	for _, key := range value.Keys() {
+		fieldKey, ok := ntgo.MatchFieldKey(key, []string{"list_string"})
+		if !ok {
+			continue
+		}
+		child := value.Dictionary[key]
+		switch fieldKey {
+		case "list_string":
+			switch child.Type {
+			case ntgo.ValueTypeText:
+				s.ListString = strings.Join(child.Text, "")
+			case ntgo.ValueTypeString:
+				s.ListString = child.String
+			default:
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string or text"), "ListString", key)
+			}
+		}
+	}
+	return nil
+}
+
+// MarshalNestedText implements ntgo.NestedTextMarshaler.
+func (s SampleListElement) MarshalNestedText() (*ntgo.Value, error) {
+	value := &ntgo.Value{Type: ntgo.ValueTypeDictionary}
+	{
+		var child29 *ntgo.Value
+		if s.ListString != "" {
+			if lines30 := strings.Split(s.ListString, "\n"); len(lines30) > 1 {
+				child29 = ntgo.NewTextValue(lines30)
+			} else {
+				child29 = &ntgo.Value{Type: ntgo.ValueTypeString, String: s.ListString}
+			}
+		}
+		if child29 == nil {
+			child29 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("list_string", child29)
+	}
+	return value, nil
+}
+
+// UnmarshalNestedText implements ntgo.NestedTextUnmarshaler.
+func (s *SampleStruct) UnmarshalNestedText(value *ntgo.Value) error {
+	if value.Type != ntgo.ValueTypeDictionary {
+		if value.Type == ntgo.ValueTypeString && value.String == "" {
+			return nil
+		}
+		return ntgo.NewTypeMismatchError(value, "dictionary")
+	}
+
+	for _, key := range value.Keys() {
+		fieldKey, ok := ntgo.MatchFieldKey(key, []string{"string", "string_ptr", "text", "text_alias", "dict", "dict_ptr", "list_struct", "list_ptr", "list_of_list_struct", "list_of_list_struct_pointer", "list_text", "list_string", "list_string_pointer", "omit_string", "not_omit_string", "NoTag"})
+		if !ok {
+			continue
+		}
+		child := value.Dictionary[key]
+		switch fieldKey {
+		case "string":
+			switch child.Type {
+			case ntgo.ValueTypeText:
+				s.String = strings.Join(child.Text, "")
+			case ntgo.ValueTypeString:
+				s.String = child.String
+			default:
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string or text"), "String", key)
+			}
+		case "string_ptr":
+			if child.Type == ntgo.ValueTypeString && child.String == "" {
+				s.StringPointer = nil
+			} else {
+				p31 := new(string)
+				switch child.Type {
+				case ntgo.ValueTypeText:
+					*p31 = strings.Join(child.Text, "")
+				case ntgo.ValueTypeString:
+					*p31 = child.String
+				default:
+					return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string or text"), "StringPointer", key)
+				}
+				s.StringPointer = p31
+			}
+		case "text":
+			list32 := make([]string, 0, len(child.List))
+			switch child.Type {
+			case ntgo.ValueTypeString:
+				list32 = append(list32, child.String)
+			case ntgo.ValueTypeText:
+				for _, child34 := range child.Text {
+					list32 = append(list32, child34)
+				}
+			case ntgo.ValueTypeList:
+				for i33, child34 := range child.List {
+					if child34.Type != ntgo.ValueTypeString {
+						return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(ntgo.NewTypeMismatchError(child34, "string"), i33), "Text", key)
+					}
+					list32 = append(list32, child34.String)
+				}
+			default:
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "list, text or string"), "Text", key)
+			}
+			s.Text = list32
+		case "text_alias":
+			list36 := make(ntgo.MultilineStrings, 0, len(child.List))
+			switch child.Type {
+			case ntgo.ValueTypeString:
+				list36 = append(list36, child.String)
+			case ntgo.ValueTypeText:
+				for _, child38 := range child.Text {
+					list36 = append(list36, child38)
+				}
+			case ntgo.ValueTypeList:
+				for i37, child38 := range child.List {
+					if child38.Type != ntgo.ValueTypeString {
+						return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(ntgo.NewTypeMismatchError(child38, "string"), i37), "TextAlias", key)
+					}
+					list36 = append(list36, child38.String)
+				}
+			default:
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "list, text or string"), "TextAlias", key)
+			}
+			s.TextAlias = list36
+		case "dict":
+			var decoded40 SampleDict
+			if err := decoded40.UnmarshalNestedText(child); err != nil {
+				return ntgo.WrapDecodeError(err, "Dict", key)
+			}
+			s.Dict = decoded40
+		case "dict_ptr":
+			if child.Type == ntgo.ValueTypeString && child.String == "" {
+				s.DictOfPointer = nil
+			} else {
+				p41 := new(SampleDict)
+				var decoded42 SampleDict
+				if err := decoded42.UnmarshalNestedText(child); err != nil {
+					return ntgo.WrapDecodeError(err, "DictOfPointer", key)
+				}
+				*p41 = decoded42
+				s.DictOfPointer = p41
+			}
+		case "list_struct":
+			list43 := make([]SampleListElement, 0, len(child.List))
+			if child.Type != ntgo.ValueTypeList {
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "list"), "ListOfStruct", key)
+			}
+			for i44, child45 := range child.List {
+				var element46 SampleListElement
+				var decoded47 SampleListElement
+				if err := decoded47.UnmarshalNestedText(child45); err != nil {
+					return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(err, i44), "ListOfStruct", key)
+				}
+				element46 = decoded47
+				list43 = append(list43, element46)
+			}
+			s.ListOfStruct = list43
+		case "list_ptr":
+			list48 := make([]*SampleListElement, 0, len(child.List))
+			if child.Type != ntgo.ValueTypeList {
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "list"), "ListOfStructPointer", key)
+			}
+			for i49, child50 := range child.List {
+				var element51 *SampleListElement
+				if child50.Type == ntgo.ValueTypeString && child50.String == "" {
+					element51 = nil
+				} else {
+					p52 := new(SampleListElement)
+					var decoded53 SampleListElement
+					if err := decoded53.UnmarshalNestedText(child50); err != nil {
+						return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(err, i49), "ListOfStructPointer", key)
+					}
+					*p52 = decoded53
+					element51 = p52
+				}
+				list48 = append(list48, element51)
+			}
+			s.ListOfStructPointer = list48
+		case "list_of_list_struct":
+			list54 := make([][]SampleListElement, 0, len(child.List))
+			if child.Type != ntgo.ValueTypeString || child.String != "" {
+				if child.Type != ntgo.ValueTypeList {
+					return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "list"), "ListOfListOfStruct", key)
+				}
+				for i55, child56 := range child.List {
+					var element57 []SampleListElement
+					list58 := make([]SampleListElement, 0, len(child56.List))
+					if child56.Type != ntgo.ValueTypeList {
+						return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(ntgo.NewTypeMismatchError(child56, "list"), i55), "ListOfListOfStruct", key)
+					}
+					for i59, child60 := range child56.List {
+						var element61 SampleListElement
+						var decoded62 SampleListElement
+						if err := decoded62.UnmarshalNestedText(child60); err != nil {
+							return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(ntgo.WrapDecodeErrorWithIndex(err, i59), i55), "ListOfListOfStruct", key)
+						}
+						element61 = decoded62
+						list58 = append(list58, element61)
+					}
+					element57 = list58
+					list54 = append(list54, element57)
+				}
+			}
+			s.ListOfListOfStruct = list54
+		case "list_of_list_struct_pointer":
+			list63 := make([][]*SampleListElement, 0, len(child.List))
+			if child.Type != ntgo.ValueTypeString || child.String != "" {
+				if child.Type != ntgo.ValueTypeList {
+					return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "list"), "ListOfListOfStructPointer", key)
+				}
+				for i64, child65 := range child.List {
+					var element66 []*SampleListElement
+					list67 := make([]*SampleListElement, 0, len(child65.List))
+					if child65.Type != ntgo.ValueTypeList {
+						return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(ntgo.NewTypeMismatchError(child65, "list"), i64), "ListOfListOfStructPointer", key)
+					}
+					for i68, child69 := range child65.List {
+						var element70 *SampleListElement
+						if child69.Type == ntgo.ValueTypeString && child69.String == "" {
+							element70 = nil
+						} else {
+							p71 := new(SampleListElement)
+							var decoded72 SampleListElement
+							if err := decoded72.UnmarshalNestedText(child69); err != nil {
+								return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(ntgo.WrapDecodeErrorWithIndex(err, i68), i64), "ListOfListOfStructPointer", key)
+							}
+							*p71 = decoded72
+							element70 = p71
+						}
+						list67 = append(list67, element70)
+					}
+					element66 = list67
+					list63 = append(list63, element66)
+				}
+			}
+			s.ListOfListOfStructPointer = list63
+		case "list_text":
+			list73 := make([]ntgo.MultilineStrings, 0, len(child.List))
+			if child.Type != ntgo.ValueTypeString || child.String != "" {
+				if child.Type != ntgo.ValueTypeList {
+					return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "list"), "ListOfText", key)
+				}
+				for i74, child75 := range child.List {
+					var element76 ntgo.MultilineStrings
+					list77 := make(ntgo.MultilineStrings, 0, len(child75.List))
+					switch child75.Type {
+					case ntgo.ValueTypeString:
+						list77 = append(list77, child75.String)
+					case ntgo.ValueTypeText:
+						for _, child79 := range child75.Text {
+							list77 = append(list77, child79)
+						}
+					case ntgo.ValueTypeList:
+						for i78, child79 := range child75.List {
+							if child79.Type != ntgo.ValueTypeString {
+								return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(ntgo.WrapDecodeErrorWithIndex(ntgo.NewTypeMismatchError(child79, "string"), i78), i74), "ListOfText", key)
+							}
+							list77 = append(list77, child79.String)
+						}
+					default:
+						return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(ntgo.NewTypeMismatchError(child75, "list, text or string"), i74), "ListOfText", key)
+					}
+					element76 = list77
+					list73 = append(list73, element76)
+				}
+			}
+			s.ListOfText = list73
+		case "list_string":
+			list81 := make([]string, 0, len(child.List))
+			switch child.Type {
+			case ntgo.ValueTypeString:
+				list81 = append(list81, child.String)
+			case ntgo.ValueTypeText:
+				for _, child83 := range child.Text {
+					list81 = append(list81, child83)
+				}
+			case ntgo.ValueTypeList:
+				for i82, child83 := range child.List {
+					if child83.Type != ntgo.ValueTypeString {
+						return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(ntgo.NewTypeMismatchError(child83, "string"), i82), "ListOfString", key)
+					}
+					list81 = append(list81, child83.String)
+				}
+			default:
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "list, text or string"), "ListOfString", key)
+			}
+			s.ListOfString = list81
+		case "list_string_pointer":
+			list85 := make([]*string, 0, len(child.List))
+			switch child.Type {
+			case ntgo.ValueTypeString:
+				element88 := child.String
+				list85 = append(list85, &element88)
+			case ntgo.ValueTypeText:
+				for _, child87 := range child.Text {
+					element88 := child87
+					list85 = append(list85, &element88)
+				}
+			case ntgo.ValueTypeList:
+				for i86, child87 := range child.List {
+					if child87.Type != ntgo.ValueTypeString {
+						return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(ntgo.NewTypeMismatchError(child87, "string"), i86), "ListOfStringPointer", key)
+					}
+					element88 := child87.String
+					list85 = append(list85, &element88)
+				}
+			default:
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "list, text or string"), "ListOfStringPointer", key)
+			}
+			s.ListOfStringPointer = list85
+		case "omit_string":
+			switch child.Type {
+			case ntgo.ValueTypeText:
+				s.OmitEmptyString = strings.Join(child.Text, "")
+			case ntgo.ValueTypeString:
+				s.OmitEmptyString = child.String
+			default:
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string or text"), "OmitEmptyString", key)
+			}
+		case "not_omit_string":
+			switch child.Type {
+			case ntgo.ValueTypeText:
+				s.NotOmitEmptyString = strings.Join(child.Text, "")
+			case ntgo.ValueTypeString:
+				s.NotOmitEmptyString = child.String
+			default:
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string or text"), "NotOmitEmptyString", key)
+			}
+		case "NoTag":
+			switch child.Type {
+			case ntgo.ValueTypeText:
+				s.NoTag = strings.Join(child.Text, "")
+			case ntgo.ValueTypeString:
+				s.NoTag = child.String
+			default:
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string or text"), "NoTag", key)
+			}
+		}
+	}
+	return nil
+}
+
+// MarshalNestedText implements ntgo.NestedTextMarshaler.
+func (s SampleStruct) MarshalNestedText() (*ntgo.Value, error) {
+	value := &ntgo.Value{Type: ntgo.ValueTypeDictionary}
+	{
+		var child89 *ntgo.Value
+		if s.String != "" {
+			if lines90 := strings.Split(s.String, "\n"); len(lines90) > 1 {
+				child89 = ntgo.NewTextValue(lines90)
+			} else {
+				child89 = &ntgo.Value{Type: ntgo.ValueTypeString, String: s.String}
+			}
+		}
+		if child89 == nil {
+			child89 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("string", child89)
+	}
+	{
+		var child91 *ntgo.Value
+		if s.StringPointer != nil {
+			if (*s.StringPointer) != "" {
+				if lines92 := strings.Split((*s.StringPointer), "\n"); len(lines92) > 1 {
+					child91 = ntgo.NewTextValue(lines92)
+				} else {
+					child91 = &ntgo.Value{Type: ntgo.ValueTypeString, String: (*s.StringPointer)}
+				}
+			}
+		}
+		if child91 == nil {
+			child91 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("string_ptr", child91)
+	}
+	{
+		var child93 *ntgo.Value
+		if len(s.Text) > 0 {
+			lines95 := []string{}
+			for _, element94 := range s.Text {
+				lines95 = append(lines95, strings.Split(element94, "\n")...)
+			}
+			child93 = ntgo.NewTextValue(lines95)
+		}
+		if child93 == nil {
+			child93 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("text", child93)
+	}
+	{
+		var child96 *ntgo.Value
+		if len(s.TextAlias) > 0 {
+			lines98 := []string{}
+			for _, element97 := range s.TextAlias {
+				lines98 = append(lines98, strings.Split(element97, "\n")...)
+			}
+			child96 = ntgo.NewTextValue(lines98)
+		}
+		if child96 == nil {
+			child96 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("text_alias", child96)
+	}
+	{
+		var child99 *ntgo.Value
+		encoded100, err := s.Dict.MarshalNestedText()
+		if err != nil {
+			return nil, err
+		}
+		if len(encoded100.Dictionary) > 0 {
+			child99 = encoded100
+		}
+		if child99 == nil {
+			child99 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("dict", child99)
+	}
+	{
+		var child101 *ntgo.Value
+		if s.DictOfPointer != nil {
+			encoded102, err := (*s.DictOfPointer).MarshalNestedText()
+			if err != nil {
+				return nil, err
+			}
+			if len(encoded102.Dictionary) > 0 {
+				child101 = encoded102
+			}
+		}
+		if child101 == nil {
+			child101 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("dict_ptr", child101)
+	}
+	{
+		var child103 *ntgo.Value
+		if len(s.ListOfStruct) > 0 {
+			list105 := &ntgo.Value{Type: ntgo.ValueTypeList}
+			for _, element104 := range s.ListOfStruct {
+				var item106 *ntgo.Value
+				encoded107, err := element104.MarshalNestedText()
+				if err != nil {
+					return nil, err
+				}
+				if len(encoded107.Dictionary) > 0 {
+					item106 = encoded107
+				}
+				if item106 == nil {
+					item106 = &ntgo.Value{Type: ntgo.ValueTypeString}
+				}
+				list105.List = append(list105.List, item106)
+			}
+			child103 = list105
+		}
+		if child103 == nil {
+			child103 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("list_struct", child103)
+	}
+	{
+		var child108 *ntgo.Value
+		if len(s.ListOfStructPointer) > 0 {
+			list110 := &ntgo.Value{Type: ntgo.ValueTypeList}
+			for _, element109 := range s.ListOfStructPointer {
+				var item111 *ntgo.Value
+				if element109 != nil {
+					encoded112, err := (*element109).MarshalNestedText()
+					if err != nil {
+						return nil, err
+					}
+					if len(encoded112.Dictionary) > 0 {
+						item111 = encoded112
+					}
+				}
+				if item111 == nil {
+					item111 = &ntgo.Value{Type: ntgo.ValueTypeString}
+				}
+				list110.List = append(list110.List, item111)
+			}
+			child108 = list110
+		}
+		if child108 == nil {
+			child108 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("list_ptr", child108)
+	}
+	{
+		var child113 *ntgo.Value
+		if len(s.ListOfListOfStruct) > 0 {
+			list115 := &ntgo.Value{Type: ntgo.ValueTypeList}
+			for _, element114 := range s.ListOfListOfStruct {
+				var item116 *ntgo.Value
+				if len(element114) > 0 {
+					list118 := &ntgo.Value{Type: ntgo.ValueTypeList}
+					for _, element117 := range element114 {
+						var item119 *ntgo.Value
+						encoded120, err := element117.MarshalNestedText()
+						if err != nil {
+							return nil, err
+						}
+						if len(encoded120.Dictionary) > 0 {
+							item119 = encoded120
+						}
+						if item119 == nil {
+							item119 = &ntgo.Value{Type: ntgo.ValueTypeString}
+						}
+						list118.List = append(list118.List, item119)
+					}
+					item116 = list118
+				}
+				if item116 == nil {
+					item116 = &ntgo.Value{Type: ntgo.ValueTypeString}
+				}
+				list115.List = append(list115.List, item116)
+			}
+			child113 = list115
+		}
+		if child113 == nil {
+			child113 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("list_of_list_struct", child113)
+	}
+	{
+		var child121 *ntgo.Value
+		if len(s.ListOfListOfStructPointer) > 0 {
+			list123 := &ntgo.Value{Type: ntgo.ValueTypeList}
+			for _, element122 := range s.ListOfListOfStructPointer {
+				var item124 *ntgo.Value
+				if len(element122) > 0 {
+					list126 := &ntgo.Value{Type: ntgo.ValueTypeList}
+					for _, element125 := range element122 {
+						var item127 *ntgo.Value
+						if element125 != nil {
+							encoded128, err := (*element125).MarshalNestedText()
+							if err != nil {
+								return nil, err
+							}
+							if len(encoded128.Dictionary) > 0 {
+								item127 = encoded128
+							}
+						}
+						if item127 == nil {
+							item127 = &ntgo.Value{Type: ntgo.ValueTypeString}
+						}
+						list126.List = append(list126.List, item127)
+					}
+					item124 = list126
+				}
+				if item124 == nil {
+					item124 = &ntgo.Value{Type: ntgo.ValueTypeString}
+				}
+				list123.List = append(list123.List, item124)
+			}
+			child121 = list123
+		}
+		if child121 == nil {
+			child121 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("list_of_list_struct_pointer", child121)
+	}
+	{
+		var child129 *ntgo.Value
+		if len(s.ListOfText) > 0 {
+			list131 := &ntgo.Value{Type: ntgo.ValueTypeList}
+			for _, element130 := range s.ListOfText {
+				var item132 *ntgo.Value
+				if len(element130) > 0 {
+					lines134 := []string{}
+					for _, element133 := range element130 {
+						lines134 = append(lines134, strings.Split(element133, "\n")...)
+					}
+					item132 = ntgo.NewTextValue(lines134)
+				}
+				if item132 == nil {
+					item132 = &ntgo.Value{Type: ntgo.ValueTypeString}
+				}
+				list131.List = append(list131.List, item132)
+			}
+			child129 = list131
+		}
+		if child129 == nil {
+			child129 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("list_text", child129)
+	}
+	{
+		var child135 *ntgo.Value
+		if len(s.ListOfString) > 0 {
+			list137 := &ntgo.Value{Type: ntgo.ValueTypeList}
+			for _, element136 := range s.ListOfString {
+				var item138 *ntgo.Value
+				if element136 != "" {
+					if lines139 := strings.Split(element136, "\n"); len(lines139) > 1 {
+						item138 = ntgo.NewTextValue(lines139)
+					} else {
+						item138 = &ntgo.Value{Type: ntgo.ValueTypeString, String: element136}
+					}
+				}
+				if item138 == nil {
+					item138 = &ntgo.Value{Type: ntgo.ValueTypeString}
+				}
+				list137.List = append(list137.List, item138)
+			}
+			child135 = list137
+		}
+		if child135 == nil {
+			child135 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("list_string", child135)
+	}
+	{
+		var child140 *ntgo.Value
+		if len(s.ListOfStringPointer) > 0 {
+			list142 := &ntgo.Value{Type: ntgo.ValueTypeList}
+			for _, element141 := range s.ListOfStringPointer {
+				var item143 *ntgo.Value
+				if element141 != nil {
+					if (*element141) != "" {
+						if lines144 := strings.Split((*element141), "\n"); len(lines144) > 1 {
+							item143 = ntgo.NewTextValue(lines144)
+						} else {
+							item143 = &ntgo.Value{Type: ntgo.ValueTypeString, String: (*element141)}
+						}
+					}
+				}
+				if item143 == nil {
+					item143 = &ntgo.Value{Type: ntgo.ValueTypeString}
+				}
+				list142.List = append(list142.List, item143)
+			}
+			child140 = list142
+		}
+		if child140 == nil {
+			child140 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("list_string_pointer", child140)
+	}
+	{
+		var child145 *ntgo.Value
+		if s.OmitEmptyString != "" {
+			if lines146 := strings.Split(s.OmitEmptyString, "\n"); len(lines146) > 1 {
+				child145 = ntgo.NewTextValue(lines146)
+			} else {
+				child145 = &ntgo.Value{Type: ntgo.ValueTypeString, String: s.OmitEmptyString}
+			}
+		}
+		if child145 != nil {
+			value.Set("omit_string", child145)
+		}
+	}
+	{
+		var child147 *ntgo.Value
+		if s.NotOmitEmptyString != "" {
+			if lines148 := strings.Split(s.NotOmitEmptyString, "\n"); len(lines148) > 1 {
+				child147 = ntgo.NewTextValue(lines148)
+			} else {
+				child147 = &ntgo.Value{Type: ntgo.ValueTypeString, String: s.NotOmitEmptyString}
+			}
+		}
+		if child147 == nil {
+			child147 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("not_omit_string", child147)
+	}
+	{
+		var child149 *ntgo.Value
+		if s.NoTag != "" {
+			if lines150 := strings.Split(s.NoTag, "\n"); len(lines150) > 1 {
+				child149 = ntgo.NewTextValue(lines150)
+			} else {
+				child149 = &ntgo.Value{Type: ntgo.ValueTypeString, String: s.NoTag}
+			}
+		}
+		if child149 == nil {
+			child149 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("NoTag", child149)
+	}
+	return value, nil
+}
+
+// UnmarshalNestedText implements ntgo.NestedTextUnmarshaler.
+func (s *NumberStruct) UnmarshalNestedText(value *ntgo.Value) error {
+	if value.Type != ntgo.ValueTypeDictionary {
+		if value.Type == ntgo.ValueTypeString && value.String == "" {
+			return nil
+		}
+		return ntgo.NewTypeMismatchError(value, "dictionary")
+	}
+
+	for _, key := range value.Keys() {
+		fieldKey, ok := ntgo.MatchFieldKey(key, []string{"int", "float", "int_ptr", "float_ptr", "int_slice", "float_slice", "int_ptr_slice", "float_ptr_slice"})
+		if !ok {
+			continue
+		}
+		child := value.Dictionary[key]
+		switch fieldKey {
+		case "int":
+			if child.Type != ntgo.ValueTypeString {
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string"), "Int", key)
+			}
+			var parsed151 int64
+			if input152 := strings.TrimSpace(child.String); input152 != "" {
+				var err error
+				parsed151, err = strconv.ParseInt(input152, 10, 0)
+				if err != nil {
+					return ntgo.WrapDecodeError(&ntgo.DecodeError{Line: child.Line, File: child.File, Err: err}, "Int", key)
+				}
+			}
+			s.Int = int(parsed151)
+		case "float":
+			if child.Type != ntgo.ValueTypeString {
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string"), "Float32", key)
+			}
+			var parsed153 float64
+			if input154 := strings.TrimSpace(child.String); input154 != "" {
+				var err error
+				parsed153, err = strconv.ParseFloat(input154, 32)
+				if err != nil {
+					return ntgo.WrapDecodeError(&ntgo.DecodeError{Line: child.Line, File: child.File, Err: err}, "Float32", key)
+				}
+			}
+			s.Float32 = float32(parsed153)
+		case "int_ptr":
+			if child.Type == ntgo.ValueTypeString && child.String == "" {
+				s.IntPtr = nil
+			} else {
+				p155 := new(int)
+				if child.Type != ntgo.ValueTypeString {
+					return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string"), "IntPtr", key)
+				}
+				var parsed156 int64
+				if input157 := strings.TrimSpace(child.String); input157 != "" {
+					var err error
+					parsed156, err = strconv.ParseInt(input157, 10, 0)
+					if err != nil {
+						return ntgo.WrapDecodeError(&ntgo.DecodeError{Line: child.Line, File: child.File, Err: err}, "IntPtr", key)
+					}
+				}
+				*p155 = int(parsed156)
+				s.IntPtr = p155
+			}
+		case "float_ptr":
+			if child.Type == ntgo.ValueTypeString && child.String == "" {
+				s.Float32Ptr = nil
+			} else {
+				p158 := new(float32)
+				if child.Type != ntgo.ValueTypeString {
+					return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string"), "Float32Ptr", key)
+				}
+				var parsed159 float64
+				if input160 := strings.TrimSpace(child.String); input160 != "" {
+					var err error
+					parsed159, err = strconv.ParseFloat(input160, 32)
+					if err != nil {
+						return ntgo.WrapDecodeError(&ntgo.DecodeError{Line: child.Line, File: child.File, Err: err}, "Float32Ptr", key)
+					}
+				}
+				*p158 = float32(parsed159)
+				s.Float32Ptr = p158
+			}
+		case "int_slice":
+			list161 := make([]int, 0, len(child.List))
+			switch {
+			case child.Type == ntgo.ValueTypeString && child.String == "":
+			case child.Type == ntgo.ValueTypeString:
+				var element164 int
+				if child.Type != ntgo.ValueTypeString {
+					return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string"), "IntSlice", key)
+				}
+				var parsed165 int64
+				if input166 := strings.TrimSpace(child.String); input166 != "" {
+					var err error
+					parsed165, err = strconv.ParseInt(input166, 10, 0)
+					if err != nil {
+						return ntgo.WrapDecodeError(&ntgo.DecodeError{Line: child.Line, File: child.File, Err: err}, "IntSlice", key)
+					}
+				}
+				element164 = int(parsed165)
+				list161 = append(list161, element164)
+			case child.Type == ntgo.ValueTypeList:
+				for i162, child163 := range child.List {
+					var element164 int
+					if child163.Type != ntgo.ValueTypeString {
+						return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(ntgo.NewTypeMismatchError(child163, "string"), i162), "IntSlice", key)
+					}
+					var parsed167 int64
+					if input168 := strings.TrimSpace(child163.String); input168 != "" {
+						var err error
+						parsed167, err = strconv.ParseInt(input168, 10, 0)
+						if err != nil {
+							return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(&ntgo.DecodeError{Line: child163.Line, File: child163.File, Err: err}, i162), "IntSlice", key)
+						}
+					}
+					element164 = int(parsed167)
+					list161 = append(list161, element164)
+				}
+			default:
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "list or string"), "IntSlice", key)
+			}
+			s.IntSlice = list161
+		case "float_slice":
+			list169 := make([]float32, 0, len(child.List))
+			switch {
+			case child.Type == ntgo.ValueTypeString && child.String == "":
+			case child.Type == ntgo.ValueTypeString:
+				var element172 float32
+				if child.Type != ntgo.ValueTypeString {
+					return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string"), "Float32Slice", key)
+				}
+				var parsed173 float64
+				if input174 := strings.TrimSpace(child.String); input174 != "" {
+					var err error
+					parsed173, err = strconv.ParseFloat(input174, 32)
+					if err != nil {
+						return ntgo.WrapDecodeError(&ntgo.DecodeError{Line: child.Line, File: child.File, Err: err}, "Float32Slice", key)
+					}
+				}
+				element172 = float32(parsed173)
+				list169 = append(list169, element172)
+			case child.Type == ntgo.ValueTypeList:
+				for i170, child171 := range child.List {
+					var element172 float32
+					if child171.Type != ntgo.ValueTypeString {
+						return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(ntgo.NewTypeMismatchError(child171, "string"), i170), "Float32Slice", key)
+					}
+					var parsed175 float64
+					if input176 := strings.TrimSpace(child171.String); input176 != "" {
+						var err error
+						parsed175, err = strconv.ParseFloat(input176, 32)
+						if err != nil {
+							return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(&ntgo.DecodeError{Line: child171.Line, File: child171.File, Err: err}, i170), "Float32Slice", key)
+						}
+					}
+					element172 = float32(parsed175)
+					list169 = append(list169, element172)
+				}
+			default:
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "list or string"), "Float32Slice", key)
+			}
+			s.Float32Slice = list169
+		case "int_ptr_slice":
+			list177 := make([]*int, 0, len(child.List))
+			switch {
+			case child.Type == ntgo.ValueTypeString && child.String == "":
+			case child.Type == ntgo.ValueTypeString:
+				var element180 *int
+				if child.Type == ntgo.ValueTypeString && child.String == "" {
+					element180 = nil
+				} else {
+					p181 := new(int)
+					if child.Type != ntgo.ValueTypeString {
+						return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string"), "IntPtrSlice", key)
+					}
+					var parsed182 int64
+					if input183 := strings.TrimSpace(child.String); input183 != "" {
+						var err error
+						parsed182, err = strconv.ParseInt(input183, 10, 0)
+						if err != nil {
+							return ntgo.WrapDecodeError(&ntgo.DecodeError{Line: child.Line, File: child.File, Err: err}, "IntPtrSlice", key)
+						}
+					}
+					*p181 = int(parsed182)
+					element180 = p181
+				}
+				list177 = append(list177, element180)
+			case child.Type == ntgo.ValueTypeList:
+				for i178, child179 := range child.List {
+					var element180 *int
+					if child179.Type == ntgo.ValueTypeString && child179.String == "" {
+						element180 = nil
+					} else {
+						p184 := new(int)
+						if child179.Type != ntgo.ValueTypeString {
+							return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(ntgo.NewTypeMismatchError(child179, "string"), i178), "IntPtrSlice", key)
+						}
+						var parsed185 int64
+						if input186 := strings.TrimSpace(child179.String); input186 != "" {
+							var err error
+							parsed185, err = strconv.ParseInt(input186, 10, 0)
+							if err != nil {
+								return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(&ntgo.DecodeError{Line: child179.Line, File: child179.File, Err: err}, i178), "IntPtrSlice", key)
+							}
+						}
+						*p184 = int(parsed185)
+						element180 = p184
+					}
+					list177 = append(list177, element180)
+				}
+			default:
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "list or string"), "IntPtrSlice", key)
+			}
+			s.IntPtrSlice = list177
+		case "float_ptr_slice":
+			list187 := make([]*float32, 0, len(child.List))
+			switch {
+			case child.Type == ntgo.ValueTypeString && child.String == "":
+			case child.Type == ntgo.ValueTypeString:
+				var element190 *float32
+				if child.Type == ntgo.ValueTypeString && child.String == "" {
+					element190 = nil
+				} else {
+					p191 := new(float32)
+					if child.Type != ntgo.ValueTypeString {
+						return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string"), "Float32PtrSlice", key)
+					}
+					var parsed192 float64
+					if input193 := strings.TrimSpace(child.String); input193 != "" {
+						var err error
+						parsed192, err = strconv.ParseFloat(input193, 32)
+						if err != nil {
+							return ntgo.WrapDecodeError(&ntgo.DecodeError{Line: child.Line, File: child.File, Err: err}, "Float32PtrSlice", key)
+						}
+					}
+					*p191 = float32(parsed192)
+					element190 = p191
+				}
+				list187 = append(list187, element190)
+			case child.Type == ntgo.ValueTypeList:
+				for i188, child189 := range child.List {
+					var element190 *float32
+					if child189.Type == ntgo.ValueTypeString && child189.String == "" {
+						element190 = nil
+					} else {
+						p194 := new(float32)
+						if child189.Type != ntgo.ValueTypeString {
+							return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(ntgo.NewTypeMismatchError(child189, "string"), i188), "Float32PtrSlice", key)
+						}
+						var parsed195 float64
+						if input196 := strings.TrimSpace(child189.String); input196 != "" {
+							var err error
+							parsed195, err = strconv.ParseFloat(input196, 32)
+							if err != nil {
+								return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(&ntgo.DecodeError{Line: child189.Line, File: child189.File, Err: err}, i188), "Float32PtrSlice", key)
+							}
+						}
+						*p194 = float32(parsed195)
+						element190 = p194
+					}
+					list187 = append(list187, element190)
+				}
+			default:
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "list or string"), "Float32PtrSlice", key)
+			}
+			s.Float32PtrSlice = list187
+		}
+	}
+	return nil
+}
+
+// MarshalNestedText implements ntgo.NestedTextMarshaler.
+func (s NumberStruct) MarshalNestedText() (*ntgo.Value, error) {
+	value := &ntgo.Value{Type: ntgo.ValueTypeDictionary}
+	{
+		var child197 *ntgo.Value
+		child197 = &ntgo.Value{Type: ntgo.ValueTypeString, String: strconv.FormatInt(int64(s.Int), 10)}
+		if child197 == nil {
+			child197 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("int", child197)
+	}
+	{
+		var child198 *ntgo.Value
+		child198 = &ntgo.Value{Type: ntgo.ValueTypeString, String: strconv.FormatFloat(float64(s.Float32), 'g', -1, 32)}
+		if child198 == nil {
+			child198 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("float", child198)
+	}
+	{
+		var child199 *ntgo.Value
+		if s.IntPtr != nil {
+			child199 = &ntgo.Value{Type: ntgo.ValueTypeString, String: strconv.FormatInt(int64((*s.IntPtr)), 10)}
+		}
+		if child199 == nil {
+			child199 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("int_ptr", child199)
+	}
+	{
+		var child200 *ntgo.Value
+		if s.Float32Ptr != nil {
+			child200 = &ntgo.Value{Type: ntgo.ValueTypeString, String: strconv.FormatFloat(float64((*s.Float32Ptr)), 'g', -1, 32)}
+		}
+		if child200 == nil {
+			child200 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("float_ptr", child200)
+	}
+	{
+		var child201 *ntgo.Value
+		if len(s.IntSlice) > 0 {
+			list203 := &ntgo.Value{Type: ntgo.ValueTypeList}
+			for _, element202 := range s.IntSlice {
+				var item204 *ntgo.Value
+				item204 = &ntgo.Value{Type: ntgo.ValueTypeString, String: strconv.FormatInt(int64(element202), 10)}
+				if item204 == nil {
+					item204 = &ntgo.Value{Type: ntgo.ValueTypeString}
+				}
+				list203.List = append(list203.List, item204)
+			}
+			child201 = list203
+		}
+		if child201 == nil {
+			child201 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("int_slice", child201)
+	}
+	{
+		var child205 *ntgo.Value
+		if len(s.Float32Slice) > 0 {
+			list207 := &ntgo.Value{Type: ntgo.ValueTypeList}
+			for _, element206 := range s.Float32Slice {
+				var item208 *ntgo.Value
+				item208 = &ntgo.Value{Type: ntgo.ValueTypeString, String: strconv.FormatFloat(float64(element206), 'g', -1, 32)}
+				if item208 == nil {
+					item208 = &ntgo.Value{Type: ntgo.ValueTypeString}
+				}
+				list207.List = append(list207.List, item208)
+			}
+			child205 = list207
+		}
+		if child205 == nil {
+			child205 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("float_slice", child205)
+	}
+	{
+		var child209 *ntgo.Value
+		if len(s.IntPtrSlice) > 0 {
+			list211 := &ntgo.Value{Type: ntgo.ValueTypeList}
+			for _, element210 := range s.IntPtrSlice {
+				var item212 *ntgo.Value
+				if element210 != nil {
+					item212 = &ntgo.Value{Type: ntgo.ValueTypeString, String: strconv.FormatInt(int64((*element210)), 10)}
+				}
+				if item212 == nil {
+					item212 = &ntgo.Value{Type: ntgo.ValueTypeString}
+				}
+				list211.List = append(list211.List, item212)
+			}
+			child209 = list211
+		}
+		if child209 == nil {
+			child209 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("int_ptr_slice", child209)
+	}
+	{
+		var child213 *ntgo.Value
+		if len(s.Float32PtrSlice) > 0 {
+			list215 := &ntgo.Value{Type: ntgo.ValueTypeList}
+			for _, element214 := range s.Float32PtrSlice {
+				var item216 *ntgo.Value
+				if element214 != nil {
+					item216 = &ntgo.Value{Type: ntgo.ValueTypeString, String: strconv.FormatFloat(float64((*element214)), 'g', -1, 32)}
+				}
+				if item216 == nil {
+					item216 = &ntgo.Value{Type: ntgo.ValueTypeString}
+				}
+				list215.List = append(list215.List, item216)
+			}
+			child213 = list215
+		}
+		if child213 == nil {
+			child213 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("float_ptr_slice", child213)
+	}
+	return value, nil
+}
+
+// UnmarshalNestedText implements ntgo.NestedTextUnmarshaler.
+func (s *ScalarStruct) UnmarshalNestedText(value *ntgo.Value) error {
+	if value.Type != ntgo.ValueTypeDictionary {
+		if value.Type == ntgo.ValueTypeString && value.String == "" {
+			return nil
+		}
+		return ntgo.NewTypeMismatchError(value, "dictionary")
+	}
+
+	for _, key := range value.Keys() {
+		fieldKey, ok := ntgo.MatchFieldKey(key, []string{"int8", "uint", "uint16", "float64", "bool", "bool_ptr", "uints", "bools", "ints"})
+		if !ok {
+			continue
+		}
+		child := value.Dictionary[key]
+		switch fieldKey {
+		case "int8":
+			if child.Type != ntgo.ValueTypeString {
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string"), "Int8", key)
+			}
+			var parsed217 int64
+			if input218 := strings.TrimSpace(child.String); input218 != "" {
+				var err error
+				parsed217, err = strconv.ParseInt(input218, 10, 8)
+				if err != nil {
+					return ntgo.WrapDecodeError(&ntgo.DecodeError{Line: child.Line, File: child.File, Err: err}, "Int8", key)
+				}
+			}
+			s.Int8 = int8(parsed217)
+		case "uint":
+			if child.Type != ntgo.ValueTypeString {
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string"), "Uint", key)
+			}
+			var parsed219 uint64
+			if input220 := strings.TrimSpace(child.String); input220 != "" {
+				var err error
+				parsed219, err = strconv.ParseUint(input220, 10, 0)
+				if err != nil {
+					return ntgo.WrapDecodeError(&ntgo.DecodeError{Line: child.Line, File: child.File, Err: err}, "Uint", key)
+				}
+			}
+			s.Uint = uint(parsed219)
+		case "uint16":
+			if child.Type != ntgo.ValueTypeString {
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string"), "Uint16", key)
+			}
+			var parsed221 uint64
+			if input222 := strings.TrimSpace(child.String); input222 != "" {
+				var err error
+				parsed221, err = strconv.ParseUint(input222, 10, 16)
+				if err != nil {
+					return ntgo.WrapDecodeError(&ntgo.DecodeError{Line: child.Line, File: child.File, Err: err}, "Uint16", key)
+				}
+			}
+			s.Uint16 = uint16(parsed221)
+		case "float64":
+			if child.Type != ntgo.ValueTypeString {
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string"), "Float64", key)
+			}
+			var parsed223 float64
+			if input224 := strings.TrimSpace(child.String); input224 != "" {
+				var err error
+				parsed223, err = strconv.ParseFloat(input224, 64)
+				if err != nil {
+					return ntgo.WrapDecodeError(&ntgo.DecodeError{Line: child.Line, File: child.File, Err: err}, "Float64", key)
+				}
+			}
+			s.Float64 = parsed223
+		case "bool":
+			if child.Type != ntgo.ValueTypeString {
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string"), "Bool", key)
+			}
+			var parsed225 bool
+			if input226 := strings.TrimSpace(child.String); input226 != "" {
+				var err error
+				parsed225, err = strconv.ParseBool(input226)
+				if err != nil {
+					return ntgo.WrapDecodeError(&ntgo.DecodeError{Line: child.Line, File: child.File, Err: err}, "Bool", key)
+				}
+			}
+			s.Bool = parsed225
+		case "bool_ptr":
+			if child.Type == ntgo.ValueTypeString && child.String == "" {
+				s.BoolPtr = nil
+			} else {
+				p227 := new(bool)
+				if child.Type != ntgo.ValueTypeString {
+					return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string"), "BoolPtr", key)
+				}
+				var parsed228 bool
+				if input229 := strings.TrimSpace(child.String); input229 != "" {
+					var err error
+					parsed228, err = strconv.ParseBool(input229)
+					if err != nil {
+						return ntgo.WrapDecodeError(&ntgo.DecodeError{Line: child.Line, File: child.File, Err: err}, "BoolPtr", key)
+					}
+				}
+				*p227 = parsed228
+				s.BoolPtr = p227
+			}
+		case "uints":
+			list230 := make([]uint8, 0, len(child.List))
+			switch {
+			case child.Type == ntgo.ValueTypeString && child.String == "":
+			case child.Type == ntgo.ValueTypeString:
+				var element233 uint8
+				if child.Type != ntgo.ValueTypeString {
+					return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string"), "Uints", key)
+				}
+				var parsed234 uint64
+				if input235 := strings.TrimSpace(child.String); input235 != "" {
+					var err error
+					parsed234, err = strconv.ParseUint(input235, 10, 8)
+					if err != nil {
+						return ntgo.WrapDecodeError(&ntgo.DecodeError{Line: child.Line, File: child.File, Err: err}, "Uints", key)
+					}
+				}
+				element233 = uint8(parsed234)
+				list230 = append(list230, element233)
+			case child.Type == ntgo.ValueTypeList:
+				for i231, child232 := range child.List {
+					var element233 uint8
+					if child232.Type != ntgo.ValueTypeString {
+						return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(ntgo.NewTypeMismatchError(child232, "string"), i231), "Uints", key)
+					}
+					var parsed236 uint64
+					if input237 := strings.TrimSpace(child232.String); input237 != "" {
+						var err error
+						parsed236, err = strconv.ParseUint(input237, 10, 8)
+						if err != nil {
+							return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(&ntgo.DecodeError{Line: child232.Line, File: child232.File, Err: err}, i231), "Uints", key)
+						}
+					}
+					element233 = uint8(parsed236)
+					list230 = append(list230, element233)
+				}
+			default:
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "list or string"), "Uints", key)
+			}
+			s.Uints = list230
+		case "bools":
+			list238 := make([]*bool, 0, len(child.List))
+			switch {
+			case child.Type == ntgo.ValueTypeString && child.String == "":
+			case child.Type == ntgo.ValueTypeString:
+				var element241 *bool
+				if child.Type == ntgo.ValueTypeString && child.String == "" {
+					element241 = nil
+				} else {
+					p242 := new(bool)
+					if child.Type != ntgo.ValueTypeString {
+						return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string"), "Bools", key)
+					}
+					var parsed243 bool
+					if input244 := strings.TrimSpace(child.String); input244 != "" {
+						var err error
+						parsed243, err = strconv.ParseBool(input244)
+						if err != nil {
+							return ntgo.WrapDecodeError(&ntgo.DecodeError{Line: child.Line, File: child.File, Err: err}, "Bools", key)
+						}
+					}
+					*p242 = parsed243
+					element241 = p242
+				}
+				list238 = append(list238, element241)
+			case child.Type == ntgo.ValueTypeList:
+				for i239, child240 := range child.List {
+					var element241 *bool
+					if child240.Type == ntgo.ValueTypeString && child240.String == "" {
+						element241 = nil
+					} else {
+						p245 := new(bool)
+						if child240.Type != ntgo.ValueTypeString {
+							return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(ntgo.NewTypeMismatchError(child240, "string"), i239), "Bools", key)
+						}
+						var parsed246 bool
+						if input247 := strings.TrimSpace(child240.String); input247 != "" {
+							var err error
+							parsed246, err = strconv.ParseBool(input247)
+							if err != nil {
+								return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(&ntgo.DecodeError{Line: child240.Line, File: child240.File, Err: err}, i239), "Bools", key)
+							}
+						}
+						*p245 = parsed246
+						element241 = p245
+					}
+					list238 = append(list238, element241)
+				}
+			default:
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "list or string"), "Bools", key)
+			}
+			s.Bools = list238
+		case "ints":
+			list248 := make([][]int, 0, len(child.List))
+			if child.Type != ntgo.ValueTypeString || child.String != "" {
+				if child.Type != ntgo.ValueTypeList {
+					return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "list"), "Ints", key)
+				}
+				for i249, child250 := range child.List {
+					var element251 []int
+					list252 := make([]int, 0, len(child250.List))
+					switch {
+					case child250.Type == ntgo.ValueTypeString && child250.String == "":
+					case child250.Type == ntgo.ValueTypeString:
+						var element255 int
+						if child250.Type != ntgo.ValueTypeString {
+							return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(ntgo.NewTypeMismatchError(child250, "string"), i249), "Ints", key)
+						}
+						var parsed256 int64
+						if input257 := strings.TrimSpace(child250.String); input257 != "" {
+							var err error
+							parsed256, err = strconv.ParseInt(input257, 10, 0)
+							if err != nil {
+								return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(&ntgo.DecodeError{Line: child250.Line, File: child250.File, Err: err}, i249), "Ints", key)
+							}
+						}
+						element255 = int(parsed256)
+						list252 = append(list252, element255)
+					case child250.Type == ntgo.ValueTypeList:
+						for i253, child254 := range child250.List {
+							var element255 int
+							if child254.Type != ntgo.ValueTypeString {
+								return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(ntgo.WrapDecodeErrorWithIndex(ntgo.NewTypeMismatchError(child254, "string"), i253), i249), "Ints", key)
+							}
+							var parsed258 int64
+							if input259 := strings.TrimSpace(child254.String); input259 != "" {
+								var err error
+								parsed258, err = strconv.ParseInt(input259, 10, 0)
+								if err != nil {
+									return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(ntgo.WrapDecodeErrorWithIndex(&ntgo.DecodeError{Line: child254.Line, File: child254.File, Err: err}, i253), i249), "Ints", key)
+								}
+							}
+							element255 = int(parsed258)
+							list252 = append(list252, element255)
+						}
+					default:
+						return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(ntgo.NewTypeMismatchError(child250, "list or string"), i249), "Ints", key)
+					}
+					element251 = list252
+					list248 = append(list248, element251)
+				}
+			}
+			s.Ints = list248
+		}
+	}
+	return nil
+}
+
+// MarshalNestedText implements ntgo.NestedTextMarshaler.
+func (s ScalarStruct) MarshalNestedText() (*ntgo.Value, error) {
+	value := &ntgo.Value{Type: ntgo.ValueTypeDictionary}
+	{
+		var child260 *ntgo.Value
+		child260 = &ntgo.Value{Type: ntgo.ValueTypeString, String: strconv.FormatInt(int64(s.Int8), 10)}
+		if child260 == nil {
+			child260 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("int8", child260)
+	}
+	{
+		var child261 *ntgo.Value
+		child261 = &ntgo.Value{Type: ntgo.ValueTypeString, String: strconv.FormatUint(uint64(s.Uint), 10)}
+		if child261 == nil {
+			child261 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("uint", child261)
+	}
+	{
+		var child262 *ntgo.Value
+		child262 = &ntgo.Value{Type: ntgo.ValueTypeString, String: strconv.FormatUint(uint64(s.Uint16), 10)}
+		if child262 == nil {
+			child262 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("uint16", child262)
+	}
+	{
+		var child263 *ntgo.Value
+		child263 = &ntgo.Value{Type: ntgo.ValueTypeString, String: strconv.FormatFloat(s.Float64, 'g', -1, 64)}
+		if child263 == nil {
+			child263 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("float64", child263)
+	}
+	{
+		var child264 *ntgo.Value
+		child264 = &ntgo.Value{Type: ntgo.ValueTypeString, String: strconv.FormatBool(s.Bool)}
+		if child264 == nil {
+			child264 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("bool", child264)
+	}
+	{
+		var child265 *ntgo.Value
+		if s.BoolPtr != nil {
+			child265 = &ntgo.Value{Type: ntgo.ValueTypeString, String: strconv.FormatBool((*s.BoolPtr))}
+		}
+		if child265 == nil {
+			child265 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("bool_ptr", child265)
+	}
+	{
+		var child266 *ntgo.Value
+		if len(s.Uints) > 0 {
+			list268 := &ntgo.Value{Type: ntgo.ValueTypeList}
+			for _, element267 := range s.Uints {
+				var item269 *ntgo.Value
+				item269 = &ntgo.Value{Type: ntgo.ValueTypeString, String: strconv.FormatUint(uint64(element267), 10)}
+				if item269 == nil {
+					item269 = &ntgo.Value{Type: ntgo.ValueTypeString}
+				}
+				list268.List = append(list268.List, item269)
+			}
+			child266 = list268
+		}
+		if child266 == nil {
+			child266 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("uints", child266)
+	}
+	{
+		var child270 *ntgo.Value
+		if len(s.Bools) > 0 {
+			list272 := &ntgo.Value{Type: ntgo.ValueTypeList}
+			for _, element271 := range s.Bools {
+				var item273 *ntgo.Value
+				if element271 != nil {
+					item273 = &ntgo.Value{Type: ntgo.ValueTypeString, String: strconv.FormatBool((*element271))}
+				}
+				if item273 == nil {
+					item273 = &ntgo.Value{Type: ntgo.ValueTypeString}
+				}
+				list272.List = append(list272.List, item273)
+			}
+			child270 = list272
+		}
+		if child270 == nil {
+			child270 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("bools", child270)
+	}
+	{
+		var child274 *ntgo.Value
+		if len(s.Ints) > 0 {
+			list276 := &ntgo.Value{Type: ntgo.ValueTypeList}
+			for _, element275 := range s.Ints {
+				var item277 *ntgo.Value
+				if len(element275) > 0 {
+					list279 := &ntgo.Value{Type: ntgo.ValueTypeList}
+					for _, element278 := range element275 {
+						var item280 *ntgo.Value
+						item280 = &ntgo.Value{Type: ntgo.ValueTypeString, String: strconv.FormatInt(int64(element278), 10)}
+						if item280 == nil {
+							item280 = &ntgo.Value{Type: ntgo.ValueTypeString}
+						}
+						list279.List = append(list279.List, item280)
+					}
+					item277 = list279
+				}
+				if item277 == nil {
+					item277 = &ntgo.Value{Type: ntgo.ValueTypeString}
+				}
+				list276.List = append(list276.List, item277)
+			}
+			child274 = list276
+		}
+		if child274 == nil {
+			child274 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("ints", child274)
+	}
+	return value, nil
+}
+
+// UnmarshalNestedText implements ntgo.NestedTextUnmarshaler.
+func (s *LosslessNumberStruct) UnmarshalNestedText(value *ntgo.Value) error {
+	if value.Type != ntgo.ValueTypeDictionary {
+		if value.Type == ntgo.ValueTypeString && value.String == "" {
+			return nil
+		}
+		return ntgo.NewTypeMismatchError(value, "dictionary")
+	}
+
+	for _, key := range value.Keys() {
+		fieldKey, ok := ntgo.MatchFieldKey(key, []string{"int", "int8", "uint", "uint64", "float32", "float64", "complex64", "complex128", "bool", "floats"})
+		if !ok {
+			continue
+		}
+		child := value.Dictionary[key]
+		switch fieldKey {
+		case "int":
+			if child.Type != ntgo.ValueTypeString {
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string"), "Int", key)
+			}
+			var parsed281 int64
+			if input282 := strings.TrimSpace(child.String); input282 != "" {
+				var err error
+				parsed281, err = strconv.ParseInt(input282, 10, 0)
+				if err != nil {
+					return ntgo.WrapDecodeError(&ntgo.DecodeError{Line: child.Line, File: child.File, Err: err}, "Int", key)
+				}
+			}
+			s.Int = int(parsed281)
+		case "int8":
+			if child.Type != ntgo.ValueTypeString {
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string"), "Int8", key)
+			}
+			var parsed283 int64
+			if input284 := strings.TrimSpace(child.String); input284 != "" {
+				var err error
+				parsed283, err = strconv.ParseInt(input284, 10, 8)
+				if err != nil {
+					return ntgo.WrapDecodeError(&ntgo.DecodeError{Line: child.Line, File: child.File, Err: err}, "Int8", key)
+				}
+			}
+			s.Int8 = int8(parsed283)
+		case "uint":
+			if child.Type != ntgo.ValueTypeString {
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string"), "Uint", key)
+			}
+			var parsed285 uint64
+			if input286 := strings.TrimSpace(child.String); input286 != "" {
+				var err error
+				parsed285, err = strconv.ParseUint(input286, 10, 0)
+				if err != nil {
+					return ntgo.WrapDecodeError(&ntgo.DecodeError{Line: child.Line, File: child.File, Err: err}, "Uint", key)
+				}
+			}
+			s.Uint = uint(parsed285)
+		case "uint64":
+			if child.Type != ntgo.ValueTypeString {
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string"), "Uint64", key)
+			}
+			var parsed287 uint64
+			if input288 := strings.TrimSpace(child.String); input288 != "" {
+				var err error
+				parsed287, err = strconv.ParseUint(input288, 10, 64)
+				if err != nil {
+					return ntgo.WrapDecodeError(&ntgo.DecodeError{Line: child.Line, File: child.File, Err: err}, "Uint64", key)
+				}
+			}
+			s.Uint64 = parsed287
+		case "float32":
+			if child.Type != ntgo.ValueTypeString {
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string"), "Float32", key)
+			}
+			var parsed289 float64
+			if input290 := strings.TrimSpace(child.String); input290 != "" {
+				var err error
+				parsed289, err = strconv.ParseFloat(input290, 32)
+				if err != nil {
+					return ntgo.WrapDecodeError(&ntgo.DecodeError{Line: child.Line, File: child.File, Err: err}, "Float32", key)
+				}
+			}
+			s.Float32 = float32(parsed289)
+		case "float64":
+			if child.Type != ntgo.ValueTypeString {
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string"), "Float64", key)
+			}
+			var parsed291 float64
+			if input292 := strings.TrimSpace(child.String); input292 != "" {
+				var err error
+				parsed291, err = strconv.ParseFloat(input292, 64)
+				if err != nil {
+					return ntgo.WrapDecodeError(&ntgo.DecodeError{Line: child.Line, File: child.File, Err: err}, "Float64", key)
+				}
+			}
+			s.Float64 = parsed291
+		case "complex64":
+			if child.Type != ntgo.ValueTypeString {
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string"), "Complex64", key)
+			}
+			var parsed293 complex128
+			if input294 := strings.TrimSpace(child.String); input294 != "" {
+				var err error
+				parsed293, err = strconv.ParseComplex(input294, 64)
+				if err != nil {
+					return ntgo.WrapDecodeError(&ntgo.DecodeError{Line: child.Line, File: child.File, Err: err}, "Complex64", key)
+				}
+			}
+			s.Complex64 = complex64(parsed293)
+		case "complex128":
+			if child.Type != ntgo.ValueTypeString {
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string"), "Complex128", key)
+			}
+			var parsed295 complex128
+			if input296 := strings.TrimSpace(child.String); input296 != "" {
+				var err error
+				parsed295, err = strconv.ParseComplex(input296, 128)
+				if err != nil {
+					return ntgo.WrapDecodeError(&ntgo.DecodeError{Line: child.Line, File: child.File, Err: err}, "Complex128", key)
+				}
+			}
+			s.Complex128 = parsed295
+		case "bool":
+			if child.Type != ntgo.ValueTypeString {
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string"), "Bool", key)
+			}
+			var parsed297 bool
+			if input298 := strings.TrimSpace(child.String); input298 != "" {
+				var err error
+				parsed297, err = strconv.ParseBool(input298)
+				if err != nil {
+					return ntgo.WrapDecodeError(&ntgo.DecodeError{Line: child.Line, File: child.File, Err: err}, "Bool", key)
+				}
+			}
+			s.Bool = parsed297
+		case "floats":
+			list299 := make([]float64, 0, len(child.List))
+			switch {
+			case child.Type == ntgo.ValueTypeString && child.String == "":
+			case child.Type == ntgo.ValueTypeString:
+				var element302 float64
+				if child.Type != ntgo.ValueTypeString {
+					return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string"), "Floats", key)
+				}
+				var parsed303 float64
+				if input304 := strings.TrimSpace(child.String); input304 != "" {
+					var err error
+					parsed303, err = strconv.ParseFloat(input304, 64)
+					if err != nil {
+						return ntgo.WrapDecodeError(&ntgo.DecodeError{Line: child.Line, File: child.File, Err: err}, "Floats", key)
+					}
+				}
+				element302 = parsed303
+				list299 = append(list299, element302)
+			case child.Type == ntgo.ValueTypeList:
+				for i300, child301 := range child.List {
+					var element302 float64
+					if child301.Type != ntgo.ValueTypeString {
+						return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(ntgo.NewTypeMismatchError(child301, "string"), i300), "Floats", key)
+					}
+					var parsed305 float64
+					if input306 := strings.TrimSpace(child301.String); input306 != "" {
+						var err error
+						parsed305, err = strconv.ParseFloat(input306, 64)
+						if err != nil {
+							return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(&ntgo.DecodeError{Line: child301.Line, File: child301.File, Err: err}, i300), "Floats", key)
+						}
+					}
+					element302 = parsed305
+					list299 = append(list299, element302)
+				}
+			default:
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "list or string"), "Floats", key)
+			}
+			s.Floats = list299
+		}
+	}
+	return nil
+}
+
+// MarshalNestedText implements ntgo.NestedTextMarshaler.
+func (s LosslessNumberStruct) MarshalNestedText() (*ntgo.Value, error) {
+	value := &ntgo.Value{Type: ntgo.ValueTypeDictionary}
+	{
+		var child307 *ntgo.Value
+		child307 = &ntgo.Value{Type: ntgo.ValueTypeString, String: strconv.FormatInt(int64(s.Int), 10)}
+		if child307 == nil {
+			child307 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("int", child307)
+	}
+	{
+		var child308 *ntgo.Value
+		child308 = &ntgo.Value{Type: ntgo.ValueTypeString, String: strconv.FormatInt(int64(s.Int8), 10)}
+		if child308 == nil {
+			child308 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("int8", child308)
+	}
+	{
+		var child309 *ntgo.Value
+		child309 = &ntgo.Value{Type: ntgo.ValueTypeString, String: strconv.FormatUint(uint64(s.Uint), 10)}
+		if child309 == nil {
+			child309 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("uint", child309)
+	}
+	{
+		var child310 *ntgo.Value
+		child310 = &ntgo.Value{Type: ntgo.ValueTypeString, String: strconv.FormatUint(s.Uint64, 10)}
+		if child310 == nil {
+			child310 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("uint64", child310)
+	}
+	{
+		var child311 *ntgo.Value
+		child311 = &ntgo.Value{Type: ntgo.ValueTypeString, String: strconv.FormatFloat(float64(s.Float32), 'g', -1, 32)}
+		if child311 == nil {
+			child311 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("float32", child311)
+	}
+	{
+		var child312 *ntgo.Value
+		child312 = &ntgo.Value{Type: ntgo.ValueTypeString, String: strconv.FormatFloat(s.Float64, 'g', -1, 64)}
+		if child312 == nil {
+			child312 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("float64", child312)
+	}
+	{
+		var child313 *ntgo.Value
+		child313 = &ntgo.Value{Type: ntgo.ValueTypeString, String: strconv.FormatComplex(complex128(s.Complex64), 'g', -1, 64)}
+		if child313 == nil {
+			child313 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("complex64", child313)
+	}
+	{
+		var child314 *ntgo.Value
+		child314 = &ntgo.Value{Type: ntgo.ValueTypeString, String: strconv.FormatComplex(s.Complex128, 'g', -1, 128)}
+		if child314 == nil {
+			child314 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("complex128", child314)
+	}
+	{
+		var child315 *ntgo.Value
+		child315 = &ntgo.Value{Type: ntgo.ValueTypeString, String: strconv.FormatBool(s.Bool)}
+		if child315 == nil {
+			child315 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("bool", child315)
+	}
+	{
+		var child316 *ntgo.Value
+		if len(s.Floats) > 0 {
+			list318 := &ntgo.Value{Type: ntgo.ValueTypeList}
+			for _, element317 := range s.Floats {
+				var item319 *ntgo.Value
+				item319 = &ntgo.Value{Type: ntgo.ValueTypeString, String: strconv.FormatFloat(element317, 'g', -1, 64)}
+				if item319 == nil {
+					item319 = &ntgo.Value{Type: ntgo.ValueTypeString}
+				}
+				list318.List = append(list318.List, item319)
+			}
+			child316 = list318
+		}
+		if child316 == nil {
+			child316 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("floats", child316)
+	}
+	return value, nil
+}
+
+// UnmarshalNestedText implements ntgo.NestedTextUnmarshaler.
+func (s *MapService) UnmarshalNestedText(value *ntgo.Value) error {
+	if value.Type != ntgo.ValueTypeDictionary {
+		if value.Type == ntgo.ValueTypeString && value.String == "" {
+			return nil
+		}
+		return ntgo.NewTypeMismatchError(value, "dictionary")
+	}
+
+	for _, key := range value.Keys() {
+		fieldKey, ok := ntgo.MatchFieldKey(key, []string{"host", "port"})
+		if !ok {
+			continue
+		}
+		child := value.Dictionary[key]
+		switch fieldKey {
+		case "host":
+			switch child.Type {
+			case ntgo.ValueTypeText:
+				s.Host = strings.Join(child.Text, "")
+			case ntgo.ValueTypeString:
+				s.Host = child.String
+			default:
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string or text"), "Host", key)
+			}
+		case "port":
+			if child.Type != ntgo.ValueTypeString {
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string"), "Port", key)
+			}
+			var parsed320 int64
+			if input321 := strings.TrimSpace(child.String); input321 != "" {
+				var err error
+				parsed320, err = strconv.ParseInt(input321, 10, 0)
+				if err != nil {
+					return ntgo.WrapDecodeError(&ntgo.DecodeError{Line: child.Line, File: child.File, Err: err}, "Port", key)
+				}
+			}
+			s.Port = int(parsed320)
+		}
+	}
+	return nil
+}
+
+// MarshalNestedText implements ntgo.NestedTextMarshaler.
+func (s MapService) MarshalNestedText() (*ntgo.Value, error) {
+	value := &ntgo.Value{Type: ntgo.ValueTypeDictionary}
+	{
+		var child322 *ntgo.Value
+		if s.Host != "" {
+			if lines323 := strings.Split(s.Host, "\n"); len(lines323) > 1 {
+				child322 = ntgo.NewTextValue(lines323)
+			} else {
+				child322 = &ntgo.Value{Type: ntgo.ValueTypeString, String: s.Host}
+			}
+		}
+		if child322 == nil {
+			child322 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("host", child322)
+	}
+	{
+		var child324 *ntgo.Value
+		child324 = &ntgo.Value{Type: ntgo.ValueTypeString, String: strconv.FormatInt(int64(s.Port), 10)}
+		if child324 == nil {
+			child324 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("port", child324)
+	}
+	return value, nil
+}
+
+// UnmarshalNestedText implements ntgo.NestedTextUnmarshaler.
+func (s *MapStruct) UnmarshalNestedText(value *ntgo.Value) error {
+	if value.Type != ntgo.ValueTypeDictionary {
+		if value.Type == ntgo.ValueTypeString && value.String == "" {
+			return nil
+		}
+		return ntgo.NewTypeMismatchError(value, "dictionary")
+	}
+
+	for _, key := range value.Keys() {
+		fieldKey, ok := ntgo.MatchFieldKey(key, []string{"labels", "services", "nested", "hosts"})
+		if !ok {
+			continue
+		}
+		child := value.Dictionary[key]
+		switch fieldKey {
+		case "labels":
+			if child.Type == ntgo.ValueTypeDictionary {
+				m325 := make(map[string]string, len(child.Dictionary))
+				for _, key326 := range child.Keys() {
+					var element327 string
+					switch child.Dictionary[key326].Type {
+					case ntgo.ValueTypeText:
+						element327 = strings.Join(child.Dictionary[key326].Text, "")
+					case ntgo.ValueTypeString:
+						element327 = child.Dictionary[key326].String
+					default:
+						return ntgo.WrapDecodeError(ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child.Dictionary[key326], "string or text"), fmt.Sprintf("[%q]", key326), key326), "Labels", key)
+					}
+					m325[key326] = element327
+				}
+				s.Labels = m325
+			} else if child.Type != ntgo.ValueTypeString || child.String != "" {
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "dictionary"), "Labels", key)
+			}
+		case "services":
+			if child.Type == ntgo.ValueTypeDictionary {
+				m328 := make(map[string]*MapService, len(child.Dictionary))
+				for _, key329 := range child.Keys() {
+					var element330 *MapService
+					if child.Dictionary[key329].Type == ntgo.ValueTypeString && child.Dictionary[key329].String == "" {
+						element330 = nil
+					} else {
+						p331 := new(MapService)
+						var decoded332 MapService
+						if err := decoded332.UnmarshalNestedText(child.Dictionary[key329]); err != nil {
+							return ntgo.WrapDecodeError(ntgo.WrapDecodeError(err, fmt.Sprintf("[%q]", key329), key329), "Services", key)
+						}
+						*p331 = decoded332
+						element330 = p331
+					}
+					m328[key329] = element330
+				}
+				s.Services = m328
+			} else if child.Type != ntgo.ValueTypeString || child.String != "" {
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "dictionary"), "Services", key)
+			}
+		case "nested":
+			if child.Type == ntgo.ValueTypeDictionary {
+				m333 := make(map[string]map[string][]string, len(child.Dictionary))
+				for _, key334 := range child.Keys() {
+					var element335 map[string][]string
+					if child.Dictionary[key334].Type == ntgo.ValueTypeDictionary {
+						m336 := make(map[string][]string, len(child.Dictionary[key334].Dictionary))
+						for _, key337 := range child.Dictionary[key334].Keys() {
+							var element338 []string
+							list339 := make([]string, 0, len(child.Dictionary[key334].Dictionary[key337].List))
+							switch child.Dictionary[key334].Dictionary[key337].Type {
+							case ntgo.ValueTypeString:
+								list339 = append(list339, child.Dictionary[key334].Dictionary[key337].String)
+							case ntgo.ValueTypeText:
+								for _, child341 := range child.Dictionary[key334].Dictionary[key337].Text {
+									list339 = append(list339, child341)
+								}
+							case ntgo.ValueTypeList:
+								for i340, child341 := range child.Dictionary[key334].Dictionary[key337].List {
+									if child341.Type != ntgo.ValueTypeString {
+										return ntgo.WrapDecodeError(ntgo.WrapDecodeError(ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(ntgo.NewTypeMismatchError(child341, "string"), i340), fmt.Sprintf("[%q]", key337), key337), fmt.Sprintf("[%q]", key334), key334), "Nested", key)
+									}
+									list339 = append(list339, child341.String)
+								}
+							default:
+								return ntgo.WrapDecodeError(ntgo.WrapDecodeError(ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child.Dictionary[key334].Dictionary[key337], "list, text or string"), fmt.Sprintf("[%q]", key337), key337), fmt.Sprintf("[%q]", key334), key334), "Nested", key)
+							}
+							element338 = list339
+							m336[key337] = element338
+						}
+						element335 = m336
+					} else if child.Dictionary[key334].Type != ntgo.ValueTypeString || child.Dictionary[key334].String != "" {
+						return ntgo.WrapDecodeError(ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child.Dictionary[key334], "dictionary"), fmt.Sprintf("[%q]", key334), key334), "Nested", key)
+					}
+					m333[key334] = element335
+				}
+				s.Nested = m333
+			} else if child.Type != ntgo.ValueTypeString || child.String != "" {
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "dictionary"), "Nested", key)
+			}
+		case "hosts":
+			list343 := make([]map[string]string, 0, len(child.List))
+			if child.Type != ntgo.ValueTypeList {
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "list"), "Hosts", key)
+			}
+			for i344, child345 := range child.List {
+				var element346 map[string]string
+				if child345.Type == ntgo.ValueTypeDictionary {
+					m347 := make(map[string]string, len(child345.Dictionary))
+					for _, key348 := range child345.Keys() {
+						var element349 string
+						switch child345.Dictionary[key348].Type {
+						case ntgo.ValueTypeText:
+							element349 = strings.Join(child345.Dictionary[key348].Text, "")
+						case ntgo.ValueTypeString:
+							element349 = child345.Dictionary[key348].String
+						default:
+							return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child345.Dictionary[key348], "string or text"), fmt.Sprintf("[%q]", key348), key348), i344), "Hosts", key)
+						}
+						m347[key348] = element349
+					}
+					element346 = m347
+				} else if child345.Type != ntgo.ValueTypeString || child345.String != "" {
+					return ntgo.WrapDecodeError(ntgo.WrapDecodeErrorWithIndex(ntgo.NewTypeMismatchError(child345, "dictionary"), i344), "Hosts", key)
+				}
+				list343 = append(list343, element346)
+			}
+			s.Hosts = list343
+		}
+	}
+	return nil
+}
+
+// MarshalNestedText implements ntgo.NestedTextMarshaler.
+func (s MapStruct) MarshalNestedText() (*ntgo.Value, error) {
+	value := &ntgo.Value{Type: ntgo.ValueTypeDictionary}
+	{
+		var child350 *ntgo.Value
+		if len(s.Labels) > 0 {
+			keys352 := make([]string, 0, len(s.Labels))
+			for key353 := range s.Labels {
+				keys352 = append(keys352, key353)
+			}
+			sort.Strings(keys352)
+			dict351 := &ntgo.Value{Type: ntgo.ValueTypeDictionary}
+			for _, key353 := range keys352 {
+				var item354 *ntgo.Value
+				if s.Labels[key353] != "" {
+					if lines355 := strings.Split(s.Labels[key353], "\n"); len(lines355) > 1 {
+						item354 = ntgo.NewTextValue(lines355)
+					} else {
+						item354 = &ntgo.Value{Type: ntgo.ValueTypeString, String: s.Labels[key353]}
+					}
+				}
+				if item354 == nil {
+					item354 = &ntgo.Value{Type: ntgo.ValueTypeString}
+				}
+				dict351.Set(key353, item354)
+			}
+			child350 = dict351
+		}
+		if child350 == nil {
+			child350 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("labels", child350)
+	}
+	{
+		var child356 *ntgo.Value
+		if len(s.Services) > 0 {
+			keys358 := make([]string, 0, len(s.Services))
+			for key359 := range s.Services {
+				keys358 = append(keys358, key359)
+			}
+			sort.Strings(keys358)
+			dict357 := &ntgo.Value{Type: ntgo.ValueTypeDictionary}
+			for _, key359 := range keys358 {
+				var item360 *ntgo.Value
+				if s.Services[key359] != nil {
+					encoded361, err := (*s.Services[key359]).MarshalNestedText()
+					if err != nil {
+						return nil, err
+					}
+					if len(encoded361.Dictionary) > 0 {
+						item360 = encoded361
+					}
+				}
+				if item360 == nil {
+					item360 = &ntgo.Value{Type: ntgo.ValueTypeString}
+				}
+				dict357.Set(key359, item360)
+			}
+			child356 = dict357
+		}
+		if child356 == nil {
+			child356 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("services", child356)
+	}
+	{
+		var child362 *ntgo.Value
+		if len(s.Nested) > 0 {
+			keys364 := make([]string, 0, len(s.Nested))
+			for key365 := range s.Nested {
+				keys364 = append(keys364, key365)
+			}
+			sort.Strings(keys364)
+			dict363 := &ntgo.Value{Type: ntgo.ValueTypeDictionary}
+			for _, key365 := range keys364 {
+				var item366 *ntgo.Value
+				if len(s.Nested[key365]) > 0 {
+					keys368 := make([]string, 0, len(s.Nested[key365]))
+					for key369 := range s.Nested[key365] {
+						keys368 = append(keys368, key369)
+					}
+					sort.Strings(keys368)
+					dict367 := &ntgo.Value{Type: ntgo.ValueTypeDictionary}
+					for _, key369 := range keys368 {
+						var item370 *ntgo.Value
+						if len(s.Nested[key365][key369]) > 0 {
+							list372 := &ntgo.Value{Type: ntgo.ValueTypeList}
+							for _, element371 := range s.Nested[key365][key369] {
+								var item373 *ntgo.Value
+								if element371 != "" {
+									if lines374 := strings.Split(element371, "\n"); len(lines374) > 1 {
+										item373 = ntgo.NewTextValue(lines374)
+									} else {
+										item373 = &ntgo.Value{Type: ntgo.ValueTypeString, String: element371}
+									}
+								}
+								if item373 == nil {
+									item373 = &ntgo.Value{Type: ntgo.ValueTypeString}
+								}
+								list372.List = append(list372.List, item373)
+							}
+							item370 = list372
+						}
+						if item370 == nil {
+							item370 = &ntgo.Value{Type: ntgo.ValueTypeString}
+						}
+						dict367.Set(key369, item370)
+					}
+					item366 = dict367
+				}
+				if item366 == nil {
+					item366 = &ntgo.Value{Type: ntgo.ValueTypeString}
+				}
+				dict363.Set(key365, item366)
+			}
+			child362 = dict363
+		}
+		if child362 == nil {
+			child362 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("nested", child362)
+	}
+	{
+		var child375 *ntgo.Value
+		if len(s.Hosts) > 0 {
+			list377 := &ntgo.Value{Type: ntgo.ValueTypeList}
+			for _, element376 := range s.Hosts {
+				var item378 *ntgo.Value
+				if len(element376) > 0 {
+					keys380 := make([]string, 0, len(element376))
+					for key381 := range element376 {
+						keys380 = append(keys380, key381)
+					}
+					sort.Strings(keys380)
+					dict379 := &ntgo.Value{Type: ntgo.ValueTypeDictionary}
+					for _, key381 := range keys380 {
+						var item382 *ntgo.Value
+						if element376[key381] != "" {
+							if lines383 := strings.Split(element376[key381], "\n"); len(lines383) > 1 {
+								item382 = ntgo.NewTextValue(lines383)
+							} else {
+								item382 = &ntgo.Value{Type: ntgo.ValueTypeString, String: element376[key381]}
+							}
+						}
+						if item382 == nil {
+							item382 = &ntgo.Value{Type: ntgo.ValueTypeString}
+						}
+						dict379.Set(key381, item382)
+					}
+					item378 = dict379
+				}
+				if item378 == nil {
+					item378 = &ntgo.Value{Type: ntgo.ValueTypeString}
+				}
+				list377.List = append(list377.List, item378)
+			}
+			child375 = list377
+		}
+		if child375 == nil {
+			child375 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("hosts", child375)
+	}
+	return value, nil
+}
+
+// UnmarshalNestedText implements ntgo.NestedTextUnmarshaler.
+func (s *OptionStruct) UnmarshalNestedText(value *ntgo.Value) error {
+	if value.Type != ntgo.ValueTypeDictionary {
+		if value.Type == ntgo.ValueTypeString && value.String == "" {
+			return nil
+		}
+		return ntgo.NewTypeMismatchError(value, "dictionary")
+	}
+
+	found := map[string]bool{}
+	for _, key := range value.Keys() {
+		fieldKey, ok := ntgo.MatchFieldKey(key, []string{"port", "host", "ratio", "comment"})
+		if !ok {
+			continue
+		}
+		found[fieldKey] = true
+		child := value.Dictionary[key]
+		switch fieldKey {
+		case "port":
+			if child.Type != ntgo.ValueTypeString {
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string"), "Port", key)
+			}
+			var parsed384 int64
+			if input385 := strings.TrimSpace(child.String); input385 != "" {
+				var err error
+				parsed384, err = strconv.ParseInt(input385, 10, 0)
+				if err != nil {
+					return ntgo.WrapDecodeError(&ntgo.DecodeError{Line: child.Line, File: child.File, Err: err}, "Port", key)
+				}
+			}
+			s.Port = int(parsed384)
+		case "host":
+			switch child.Type {
+			case ntgo.ValueTypeText:
+				s.Host = strings.Join(child.Text, "")
+			case ntgo.ValueTypeString:
+				s.Host = child.String
+			default:
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string or text"), "Host", key)
+			}
+		case "ratio":
+			if child.Type != ntgo.ValueTypeString {
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string"), "Ratio", key)
+			}
+			var parsed386 float64
+			if input387 := strings.TrimSpace(child.String); input387 != "" {
+				var err error
+				parsed386, err = strconv.ParseFloat(input387, 64)
+				if err != nil {
+					return ntgo.WrapDecodeError(&ntgo.DecodeError{Line: child.Line, File: child.File, Err: err}, "Ratio", key)
+				}
+			}
+			s.Ratio = parsed386
+		case "comment":
+			switch child.Type {
+			case ntgo.ValueTypeText:
+				s.Comment = strings.Join(child.Text, "")
+			case ntgo.ValueTypeString:
+				s.Comment = child.String
+			default:
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string or text"), "Comment", key)
+			}
+		}
+	}
+	if !found["port"] {
+		child388 := &ntgo.Value{Type: ntgo.ValueTypeString, String: "8080"}
+		if child388.Type != ntgo.ValueTypeString {
+			return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child388, "string"), "Port", "port")
+		}
+		var parsed389 int64
+		if input390 := strings.TrimSpace(child388.String); input390 != "" {
+			var err error
+			parsed389, err = strconv.ParseInt(input390, 10, 0)
+			if err != nil {
+				return ntgo.WrapDecodeError(&ntgo.DecodeError{Line: child388.Line, File: child388.File, Err: err}, "Port", "port")
+			}
+		}
+		s.Port = int(parsed389)
+	}
+	if !found["host"] {
+		child391 := &ntgo.Value{Type: ntgo.ValueTypeString, String: "localhost"}
+		switch child391.Type {
+		case ntgo.ValueTypeText:
+			s.Host = strings.Join(child391.Text, "")
+		case ntgo.ValueTypeString:
+			s.Host = child391.String
+		default:
+			return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child391, "string or text"), "Host", "host")
+		}
+	}
+	return nil
+}
+
+// MarshalNestedText implements ntgo.NestedTextMarshaler.
+func (s OptionStruct) MarshalNestedText() (*ntgo.Value, error) {
+	value := &ntgo.Value{Type: ntgo.ValueTypeDictionary}
+	{
+		var child392 *ntgo.Value
+		child392 = &ntgo.Value{Type: ntgo.ValueTypeString, String: strconv.FormatInt(int64(s.Port), 10)}
+		if child392 == nil {
+			child392 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("port", child392)
+	}
+	{
+		var child393 *ntgo.Value
+		if s.Host != "" {
+			if lines394 := strings.Split(s.Host, "\n"); len(lines394) > 1 {
+				child393 = ntgo.NewTextValue(lines394)
+			} else {
+				child393 = &ntgo.Value{Type: ntgo.ValueTypeString, String: s.Host}
+			}
+		}
+		if child393 == nil {
+			child393 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("host", child393)
+	}
+	{
+		var child395 *ntgo.Value
+		child395 = &ntgo.Value{Type: ntgo.ValueTypeString, String: fmt.Sprintf("%.2f", s.Ratio)}
+		if child395 == nil {
+			child395 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("ratio", child395)
+	}
+	{
+		var child396 *ntgo.Value
+		if s.Comment != "" {
+			if lines397 := strings.Split(s.Comment, "\n"); len(lines397) > 1 {
+				child396 = ntgo.NewTextValue(lines397)
+			} else {
+				child396 = &ntgo.Value{Type: ntgo.ValueTypeString, String: s.Comment}
+			}
+		}
+		if child396 != nil {
+			value.Set("comment", child396)
+		}
+	}
+	return value, nil
+}
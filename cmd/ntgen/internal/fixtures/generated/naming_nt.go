@@ -0,0 +1,123 @@
+// Code generated by ntgen. DO NOT EDIT.
+
+package generated
+
+import (
+	"strconv"
+	"strings"
+
+	ntgo "github.com/dolow/nt-go"
+)
+
+// UnmarshalNestedText implements ntgo.NestedTextUnmarshaler.
+func (s *NamingStruct) UnmarshalNestedText(value *ntgo.Value) error {
+	if value.Type != ntgo.ValueTypeDictionary {
+		if value.Type == ntgo.ValueTypeString && value.String == "" {
+			return nil
+		}
+		return ntgo.NewTypeMismatchError(value, "dictionary")
+	}
+
+	for _, key := range value.Keys() {
+		fieldKey, ok := ntgo.MatchFieldKey(key, []string{"server_name", "http_port", "TaggedKey", "dict"})
+		if !ok {
+			continue
+		}
+		child := value.Dictionary[key]
+		switch fieldKey {
+		case "server_name":
+			switch child.Type {
+			case ntgo.ValueTypeText:
+				s.ServerName = strings.Join(child.Text, "")
+			case ntgo.ValueTypeString:
+				s.ServerName = child.String
+			default:
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string or text"), "ServerName", key)
+			}
+		case "http_port":
+			if child.Type != ntgo.ValueTypeString {
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string"), "HTTPPort", key)
+			}
+			var parsed1 int64
+			if input2 := strings.TrimSpace(child.String); input2 != "" {
+				var err error
+				parsed1, err = strconv.ParseInt(input2, 10, 0)
+				if err != nil {
+					return ntgo.WrapDecodeError(&ntgo.DecodeError{Line: child.Line, File: child.File, Err: err}, "HTTPPort", key)
+				}
+			}
+			s.HTTPPort = int(parsed1)
+		case "TaggedKey":
+			switch child.Type {
+			case ntgo.ValueTypeText:
+				s.Tagged = strings.Join(child.Text, "")
+			case ntgo.ValueTypeString:
+				s.Tagged = child.String
+			default:
+				return ntgo.WrapDecodeError(ntgo.NewTypeMismatchError(child, "string or text"), "Tagged", key)
+			}
+		case "dict":
+			var decoded3 SampleDict
+			if err := decoded3.UnmarshalNestedText(child); err != nil {
+				return ntgo.WrapDecodeError(err, "Dict", key)
+			}
+			s.Dict = decoded3
+		}
+	}
+	return nil
+}
+
+// MarshalNestedText implements ntgo.NestedTextMarshaler.
+func (s NamingStruct) MarshalNestedText() (*ntgo.Value, error) {
+	value := &ntgo.Value{Type: ntgo.ValueTypeDictionary}
+	{
+		var child4 *ntgo.Value
+		if s.ServerName != "" {
+			if lines5 := strings.Split(s.ServerName, "\n"); len(lines5) > 1 {
+				child4 = ntgo.NewTextValue(lines5)
+			} else {
+				child4 = &ntgo.Value{Type: ntgo.ValueTypeString, String: s.ServerName}
+			}
+		}
+		if child4 == nil {
+			child4 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("server_name", child4)
+	}
+	{
+		var child6 *ntgo.Value
+		child6 = &ntgo.Value{Type: ntgo.ValueTypeString, String: strconv.FormatInt(int64(s.HTTPPort), 10)}
+		if child6 != nil {
+			value.Set("http_port", child6)
+		}
+	}
+	{
+		var child7 *ntgo.Value
+		if s.Tagged != "" {
+			if lines8 := strings.Split(s.Tagged, "\n"); len(lines8) > 1 {
+				child7 = ntgo.NewTextValue(lines8)
+			} else {
+				child7 = &ntgo.Value{Type: ntgo.ValueTypeString, String: s.Tagged}
+			}
+		}
+		if child7 == nil {
+			child7 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("TaggedKey", child7)
+	}
+	{
+		var child9 *ntgo.Value
+		encoded10, err := s.Dict.MarshalNestedText()
+		if err != nil {
+			return nil, err
+		}
+		if len(encoded10.Dictionary) > 0 {
+			child9 = encoded10
+		}
+		if child9 == nil {
+			child9 = &ntgo.Value{Type: ntgo.ValueTypeString}
+		}
+		value.Set("dict", child9)
+	}
+	return value, nil
+}
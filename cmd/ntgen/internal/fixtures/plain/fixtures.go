@@ -0,0 +1,113 @@
+// Package plain declares fixtures of the reflective Marshaller.
+// They must be kept identical to those of package generated.
+package plain
+
+import (
+	ntgo "github.com/dolow/nt-go"
+)
+
+type SampleDict struct {
+	DictString string                `nt:"dict_string"`
+	DictText   ntgo.MultilineStrings `nt:"dict_text,multilinestrings"`
+}
+
+type SampleMultilineString struct {
+	Str         string    `nt:"str,multilinestrings"`
+	StrPtr      *string   `nt:"str_ptr,multilinestrings"`
+	StrSlice    []string  `nt:"str_slice,multilinestrings"`
+	StrPtrSlice []*string `nt:"str_ptr_slice,multilinestrings"`
+}
+
+type SampleListElement struct {
+	ListString string `nt:"list_string"`
+}
+
+type SampleStruct struct {
+	String        string  `nt:"string"`
+	StringPointer *string `nt:"string_ptr"`
+
+	Text      []string              `nt:"text,multilinestrings"`
+	TextAlias ntgo.MultilineStrings `nt:"text_alias,multilinestrings"`
+
+	Dict          SampleDict  `nt:"dict"`
+	DictOfPointer *SampleDict `nt:"dict_ptr"`
+
+	ListOfStruct              []SampleListElement     `nt:"list_struct"`
+	ListOfStructPointer       []*SampleListElement    `nt:"list_ptr"`
+	ListOfListOfStruct        [][]SampleListElement   `nt:"list_of_list_struct"`
+	ListOfListOfStructPointer [][]*SampleListElement  `nt:"list_of_list_struct_pointer"`
+	ListOfText                []ntgo.MultilineStrings `nt:"list_text,multilinestrings"`
+	ListOfString              []string                `nt:"list_string"`
+	ListOfStringPointer       []*string               `nt:"list_string_pointer"`
+
+	OmitEmptyString    string `nt:"omit_string,omitempty"`
+	NotOmitEmptyString string `nt:"not_omit_string"`
+
+	NoTag string
+}
+
+type NumberStruct struct {
+	Int        int      `nt:"int"`
+	Float32    float32  `nt:"float"`
+	IntPtr     *int     `nt:"int_ptr"`
+	Float32Ptr *float32 `nt:"float_ptr"`
+
+	IntSlice        []int      `nt:"int_slice"`
+	Float32Slice    []float32  `nt:"float_slice"`
+	IntPtrSlice     []*int     `nt:"int_ptr_slice"`
+	Float32PtrSlice []*float32 `nt:"float_ptr_slice"`
+}
+
+type ScalarStruct struct {
+	Int8    int8    `nt:"int8"`
+	Uint    uint    `nt:"uint"`
+	Uint16  uint16  `nt:"uint16"`
+	Float64 float64 `nt:"float64"`
+	Bool    bool    `nt:"bool"`
+	BoolPtr *bool   `nt:"bool_ptr"`
+	Uints   []uint8 `nt:"uints"`
+	Bools   []*bool `nt:"bools"`
+	Ints    [][]int `nt:"ints"`
+}
+
+type LosslessNumberStruct struct {
+	Int        int        `nt:"int"`
+	Int8       int8       `nt:"int8"`
+	Uint       uint       `nt:"uint"`
+	Uint64     uint64     `nt:"uint64"`
+	Float32    float32    `nt:"float32"`
+	Float64    float64    `nt:"float64"`
+	Complex64  complex64  `nt:"complex64"`
+	Complex128 complex128 `nt:"complex128"`
+	Bool       bool       `nt:"bool"`
+	Floats     []float64  `nt:"floats"`
+}
+
+type MapService struct {
+	Host string `nt:"host"`
+	Port int    `nt:"port"`
+}
+
+// MapStruct is MapStruct of the root package without maps of non-string keys.
+type MapStruct struct {
+	Labels   map[string]string              `nt:"labels"`
+	Services map[string]*MapService         `nt:"services"`
+	Nested   map[string]map[string][]string `nt:"nested"`
+	Hosts    []map[string]string            `nt:"hosts"`
+}
+
+type OptionStruct struct {
+	Port    int     `nt:"port,default=8080"`
+	Host    string  `nt:"host,default=localhost"`
+	Ratio   float64 `nt:"ratio,format=%.2f"`
+	Comment string  `nt:"comment,omitempty"`
+	Ignored string  `nt:"-"`
+}
+
+// NamingStruct is generated with -naming snake.
+type NamingStruct struct {
+	ServerName string
+	HTTPPort   int    `nt:",omitempty"`
+	Tagged     string `nt:"TaggedKey"`
+	Dict       SampleDict
+}
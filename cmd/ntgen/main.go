@@ -0,0 +1,80 @@
+// Command ntgen generates MarshalNestedText and UnmarshalNestedText methods for structs with nt tags,
+// so that they are encoded and decoded without reflection.
+//
+// Usage:
+//
+//	//go:generate ntgen -type Config,Server
+//
+// Types are looked up in the package of the file given as an argument, or $GOFILE when run by go generate.
+// Methods are written to <file>_nt.go unless -output is specified.
+// Keys of fields without nt tag are field names, or derived with -naming in the same manner as NamingStrategy of ntgo.Marshaller.
+//
+// Generated UnmarshalNestedText methods decode as ntgo.Marshaller with default options.
+// DisallowUnknownFields, ReportMissingKeys, Merge and SliceMerge of ntgo.Marshaller are not applied to generated types,
+// including generated types of fields in structs decoded by ntgo.Marshaller.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	ntgo "github.com/dolow/nt-go"
+)
+
+var namingStrategies = map[string]ntgo.NamingStrategy{
+	"snake": ntgo.SnakeCase,
+	"kebab": ntgo.KebabCase,
+	"space": ntgo.SpaceSeparated,
+}
+
+func main() {
+	typeNames := flag.String("type", "", "comma separated list of struct type names; required")
+	output := flag.String("output", "", "output file name; default <file>_nt.go")
+	naming := flag.String("naming", "", "naming strategy of keys for fields without nt tag, one of snake, kebab and space; default field names")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: ntgen -type T[,T...] [-naming strategy] [-output file] [file]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if err := run(*typeNames, *naming, *output, flag.Args()); err != nil {
+		fmt.Fprintf(os.Stderr, "ntgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(typeNames string, naming string, output string, args []string) error {
+	if typeNames == "" {
+		flag.Usage()
+		return fmt.Errorf("-type is required")
+	}
+	strategy, ok := namingStrategies[naming]
+	if !ok && naming != "" {
+		return fmt.Errorf("unknown naming strategy %q", naming)
+	}
+
+	file := os.Getenv("GOFILE")
+	if len(args) > 0 {
+		file = args[0]
+	}
+	if file == "" {
+		return fmt.Errorf("no input file, give a file or run with go generate")
+	}
+
+	if output == "" {
+		output = strings.TrimSuffix(file, ".go") + "_nt.go"
+	} else if !filepath.IsAbs(output) {
+		output = filepath.Join(filepath.Dir(file), output)
+	}
+
+	source, err := generate(filepath.Dir(file), strings.Split(typeNames, ","), strategy)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(output, source, 0644)
+}
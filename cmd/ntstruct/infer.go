@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+	"unicode"
+
+	ntgo "github.com/dolow/nt-go"
+)
+
+type shapeKind int
+
+const (
+	shapeEmpty shapeKind = iota
+	shapeString
+	shapeText
+	shapeList
+	shapeDictionary
+	// shapeAny is a value whose kinds differ among samples
+	shapeAny
+)
+
+// shape is the structure of values merged from samples.
+type shape struct {
+	kind shapeKind
+	elem *shape
+
+	keys   []string
+	fields map[string]*shape
+	// counts is the number of dictionaries having the key, and samples is the number of dictionaries merged
+	counts  map[string]int
+	samples int
+}
+
+func shapeOf(value *ntgo.Value) *shape {
+	switch value.Type {
+	case ntgo.ValueTypeText:
+		return &shape{kind: shapeText}
+	case ntgo.ValueTypeList:
+		s := &shape{kind: shapeList, elem: &shape{kind: shapeEmpty}}
+		for _, child := range value.List {
+			s.elem = mergeShapes(s.elem, shapeOf(child))
+		}
+		return s
+	case ntgo.ValueTypeDictionary:
+		s := &shape{kind: shapeDictionary, fields: map[string]*shape{}, counts: map[string]int{}, samples: 1}
+		for _, key := range value.Keys() {
+			s.keys = append(s.keys, key)
+			s.fields[key] = shapeOf(value.Dictionary[key])
+			s.counts[key] = 1
+		}
+		return s
+	}
+	if value.String == "" {
+		return &shape{kind: shapeEmpty}
+	}
+	return &shape{kind: shapeString}
+}
+
+// mergeShapes returns the shape accepting both of a and b.
+func mergeShapes(a *shape, b *shape) *shape {
+	if a.kind == shapeEmpty {
+		return b
+	}
+	if b.kind == shapeEmpty {
+		return a
+	}
+
+	switch {
+	case a.kind == shapeDictionary && b.kind == shapeDictionary:
+		merged := &shape{kind: shapeDictionary, fields: map[string]*shape{}, counts: map[string]int{}, samples: a.samples + b.samples}
+		for _, s := range []*shape{a, b} {
+			for _, key := range s.keys {
+				if existing, ok := merged.fields[key]; ok {
+					merged.fields[key] = mergeShapes(existing, s.fields[key])
+				} else {
+					merged.keys = append(merged.keys, key)
+					merged.fields[key] = s.fields[key]
+				}
+				merged.counts[key] += s.counts[key]
+			}
+		}
+		return merged
+	case a.kind == shapeList && b.kind == shapeList:
+		return &shape{kind: shapeList, elem: mergeShapes(a.elem, b.elem)}
+	case isStringShape(a) && isStringShape(b):
+		if a.kind == shapeText {
+			return a
+		}
+		return b
+	case isStringShape(a) && isStringList(b):
+		return b
+	case isStringList(a) && isStringShape(b):
+		return a
+	}
+	return &shape{kind: shapeAny}
+}
+
+func isStringShape(s *shape) bool {
+	return s.kind == shapeString || s.kind == shapeText
+}
+
+// isStringList reports whether s is a list of which elements are decoded into strings.
+func isStringList(s *shape) bool {
+	return s.kind == shapeList && (s.elem.kind == shapeEmpty || isStringShape(s.elem))
+}
+
+type structField struct {
+	name string
+	typ  string
+	tag  string
+}
+
+type structDecl struct {
+	name   string
+	fields []structField
+	// done is false while fields are being declared
+	done bool
+}
+
+func (d *structDecl) body() string {
+	buf := &bytes.Buffer{}
+	for _, f := range d.fields {
+		fmt.Fprintf(buf, "\t%s %s `nt:%s`\n", f.name, f.typ, strconv.Quote(f.tag))
+	}
+	return buf.String()
+}
+
+// inferrer declares struct types for shapes.
+type inferrer struct {
+	// textLines declares Text as []string with multilinestrings option instead of string
+	textLines bool
+
+	decls []*structDecl
+	names map[string]bool
+}
+
+// infer returns Go source declaring typeName and types of nested dictionaries inferred from samples.
+func infer(samples []*ntgo.Value, pkgName string, typeName string, textLines bool) ([]byte, error) {
+	root := &shape{kind: shapeEmpty}
+	for _, sample := range samples {
+		if sample.Type != ntgo.ValueTypeDictionary {
+			return nil, fmt.Errorf("sample must be a dictionary, got %s", sample.Type)
+		}
+		root = mergeShapes(root, shapeOf(sample))
+	}
+
+	if err := checkKeys(root); err != nil {
+		return nil, err
+	}
+
+	inf := &inferrer{textLines: textLines, names: map[string]bool{}}
+	inf.declare(root, typeName, "")
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "package %s\n", pkgName)
+	for _, decl := range inf.decls {
+		fmt.Fprintf(buf, "\ntype %s struct {\n%s}\n", decl.name, decl.body())
+	}
+	return format.Source(buf.Bytes())
+}
+
+// checkKeys returns error for keys which cannot be written in struct tags.
+func checkKeys(s *shape) error {
+	for _, key := range s.keys {
+		if strings.ContainsAny(key, "`"+ntgo.MarshallerTagSeparator) {
+			return fmt.Errorf("key %q cannot be written in struct tag", key)
+		}
+		if err := checkKeys(s.fields[key]); err != nil {
+			return err
+		}
+	}
+	if s.elem != nil {
+		return checkKeys(s.elem)
+	}
+	return nil
+}
+
+// declare declares the struct for dictionary s and returns its name.
+// The struct of the same fields already declared is reused.
+func (inf *inferrer) declare(s *shape, name string, parent string) string {
+	decl := &structDecl{name: inf.reserve(name, parent)}
+	inf.decls = append(inf.decls, decl)
+
+	fieldNames := map[string]bool{}
+	for _, key := range s.keys {
+		fieldName := goName(key)
+		for i := 2; fieldNames[fieldName]; i++ {
+			fieldName = fmt.Sprintf("%s%d", goName(key), i)
+		}
+		fieldNames[fieldName] = true
+
+		optional := s.counts[key] < s.samples
+		f := structField{name: fieldName, tag: key}
+		f.typ = inf.typeOf(s.fields[key], fieldName, decl.name)
+		if optional {
+			f.tag += ",omitempty"
+			if s.fields[key].kind == shapeDictionary {
+				f.typ = "*" + f.typ
+			}
+		}
+		if inf.textLines && s.fields[key].kind == shapeText {
+			f.tag += ",multilinestrings"
+		}
+		decl.fields = append(decl.fields, f)
+	}
+
+	for _, existing := range inf.decls {
+		if existing.done && existing.body() == decl.body() {
+			inf.remove(decl)
+			return existing.name
+		}
+	}
+	decl.done = true
+	return decl.name
+}
+
+func (inf *inferrer) typeOf(s *shape, name string, parent string) string {
+	switch s.kind {
+	case shapeText:
+		if inf.textLines {
+			return "[]string"
+		}
+	case shapeList:
+		return "[]" + inf.typeOf(s.elem, singular(name), parent)
+	case shapeDictionary:
+		return inf.declare(s, name, parent)
+	case shapeAny:
+		return "interface{}"
+	}
+	return "string"
+}
+
+// reserve returns an unused type name, name is prefixed with parent on conflict.
+func (inf *inferrer) reserve(name string, parent string) string {
+	candidate := name
+	if inf.names[candidate] {
+		candidate = parent + name
+	}
+	for i := 2; inf.names[candidate]; i++ {
+		candidate = fmt.Sprintf("%s%s%d", parent, name, i)
+	}
+	inf.names[candidate] = true
+	return candidate
+}
+
+func (inf *inferrer) remove(decl *structDecl) {
+	for i, d := range inf.decls {
+		if d == decl {
+			inf.decls = append(inf.decls[:i], inf.decls[i+1:]...)
+			break
+		}
+	}
+	delete(inf.names, decl.name)
+}
+
+var initialisms = map[string]bool{
+	"API": true, "DNS": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true,
+	"JSON": true, "SQL": true, "SSH": true, "TCP": true, "TLS": true, "UDP": true, "UI": true,
+	"URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// goName converts key into an exported Go identifier, e.g. "additional roles" becomes AdditionalRoles.
+func goName(key string) string {
+	words := strings.FieldsFunc(key, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	name := ""
+	for _, word := range words {
+		if upper := strings.ToUpper(word); initialisms[upper] {
+			name += upper
+			continue
+		}
+		runes := []rune(word)
+		name += string(unicode.ToUpper(runes[0])) + string(runes[1:])
+	}
+
+	if name == "" {
+		return "Field"
+	}
+	if first := []rune(name)[0]; !unicode.IsUpper(first) {
+		return "X" + name
+	}
+	return name
+}
+
+// singular returns the name of element type for a list named name.
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies"):
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "sses"):
+		return strings.TrimSuffix(name, "es")
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss"):
+		return strings.TrimSuffix(name, "s")
+	}
+	return name + "Element"
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	ntgo "github.com/dolow/nt-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInfer(t *testing.T) {
+	// compact removes alignment of fields
+	compact := func(source []byte) string {
+		return strings.Join(strings.Fields(string(source)), " ")
+	}
+	parse := func(t *testing.T, contents ...string) []*ntgo.Value {
+		samples := []*ntgo.Value{}
+		for _, content := range contents {
+			sample := &ntgo.Value{}
+			assert.Nil(t, sample.Parse([]byte(content)))
+			samples = append(samples, sample)
+		}
+		return samples
+	}
+
+	t.Run("sample.nt", func(t *testing.T) {
+		data, err := ioutil.ReadFile("../../sample/sample.nt")
+		assert.Nil(t, err)
+
+		source, err := infer(parse(t, string(data)), "officers", "Officers", false)
+		assert.Nil(t, err)
+
+		t.Run("should name types of dictionaries after their keys", func(t *testing.T) {
+			assert.Equal(t, `package officers
+
+type Officers struct {
+	President     President     `+"`"+`nt:"president"`+"`"+`
+	VicePresident VicePresident `+"`"+`nt:"vice president"`+"`"+`
+	Treasurer     VicePresident `+"`"+`nt:"treasurer"`+"`"+`
+}
+
+type President struct {
+	Name            string   `+"`"+`nt:"name"`+"`"+`
+	Address         string   `+"`"+`nt:"address"`+"`"+`
+	Phone           Phone    `+"`"+`nt:"phone"`+"`"+`
+	Email           string   `+"`"+`nt:"email"`+"`"+`
+	AdditionalRoles []string `+"`"+`nt:"additional roles"`+"`"+`
+}
+
+type Phone struct {
+	Cell string `+"`"+`nt:"cell"`+"`"+`
+	Home string `+"`"+`nt:"home"`+"`"+`
+}
+
+type VicePresident struct {
+	Name            string   `+"`"+`nt:"name"`+"`"+`
+	Address         string   `+"`"+`nt:"address"`+"`"+`
+	Phone           string   `+"`"+`nt:"phone"`+"`"+`
+	Email           string   `+"`"+`nt:"email"`+"`"+`
+	AdditionalRoles []string `+"`"+`nt:"additional roles"`+"`"+`
+}
+`, string(source))
+		})
+	})
+
+	t.Run("when multiple samples are given", func(t *testing.T) {
+		samples := parse(t,
+			"name: a\nserver:\n  host: localhost\ntags: web",
+			"name: b\nport: 80\ntags:\n  - web\n  - db",
+		)
+
+		source, err := infer(samples, "main", "Config", false)
+		assert.Nil(t, err)
+
+		t.Run("keys absent in some samples should be optional", func(t *testing.T) {
+			assert.Contains(t, compact(source), "Server *Server `nt:\"server,omitempty\"`")
+			assert.Contains(t, compact(source), "Port string `nt:\"port,omitempty\"`")
+		})
+
+		t.Run("string and list of strings should be merged into []string", func(t *testing.T) {
+			assert.Contains(t, compact(source), "Tags []string `nt:\"tags\"`")
+		})
+	})
+
+	t.Run("list of dictionaries", func(t *testing.T) {
+		source, err := infer(parse(t, "entries:\n  -\n    id: 1\n    url: a\n  -\n    id: 2"), "main", "Config", false)
+		assert.Nil(t, err)
+
+		t.Run("should declare element type merging all elements", func(t *testing.T) {
+			assert.Contains(t, compact(source), "Entries []Entry `nt:\"entries\"`")
+			assert.Contains(t, compact(source), "type Entry struct { ID string `nt:\"id\"` URL string `nt:\"url,omitempty\"` }")
+		})
+	})
+
+	t.Run("text", func(t *testing.T) {
+		samples := parse(t, "address:\n  > line 1\n  > line 2")
+
+		t.Run("should be string by default", func(t *testing.T) {
+			source, err := infer(samples, "main", "Config", false)
+			assert.Nil(t, err)
+			assert.Contains(t, compact(source), "Address string `nt:\"address\"`")
+		})
+
+		t.Run("should be []string with multilinestrings when lines are preferred", func(t *testing.T) {
+			source, err := infer(samples, "main", "Config", true)
+			assert.Nil(t, err)
+			assert.Contains(t, compact(source), "Address []string `nt:\"address,multilinestrings\"`")
+		})
+	})
+
+	t.Run("values of different kinds", func(t *testing.T) {
+		source, err := infer(parse(t, "value: a", "value:\n  key: b"), "main", "Config", false)
+		assert.Nil(t, err)
+
+		t.Run("should be interface{}", func(t *testing.T) {
+			assert.Contains(t, compact(source), "Value interface{} `nt:\"value\"`")
+		})
+	})
+
+	t.Run("dictionaries of the same key in different places", func(t *testing.T) {
+		source, err := infer(parse(t, "a:\n  server:\n    host: x\nb:\n  server:\n    port: 1"), "main", "Config", false)
+		assert.Nil(t, err)
+
+		t.Run("should be prefixed with the parent type", func(t *testing.T) {
+			assert.Contains(t, compact(source), "type Server struct {")
+			assert.Contains(t, compact(source), "type BServer struct {")
+		})
+	})
+
+	t.Run("keys with special characters", func(t *testing.T) {
+		t.Run("should be quoted in struct tags", func(t *testing.T) {
+			source, err := infer(parse(t, "say \"hi\": hello\nback\\slash: x"), "main", "Config", false)
+			assert.Nil(t, err)
+			assert.Contains(t, string(source), "`nt:\"say \\\"hi\\\"\"`")
+			assert.Contains(t, string(source), "`nt:\"back\\\\slash\"`")
+		})
+
+		t.Run("should return error when they cannot be written in struct tags", func(t *testing.T) {
+			_, err := infer(parse(t, "a:\n  `quoted`: x"), "main", "Config", false)
+			assert.NotNil(t, err)
+			_, err = infer(parse(t, "a, b: x"), "main", "Config", false)
+			assert.NotNil(t, err)
+		})
+	})
+
+	t.Run("when sample is not a dictionary", func(t *testing.T) {
+		t.Run("should return error", func(t *testing.T) {
+			_, err := infer(parse(t, "- a"), "main", "Config", false)
+			assert.NotNil(t, err)
+		})
+	})
+}
+
+func TestGoName(t *testing.T) {
+	t.Run("should convert keys into exported identifiers", func(t *testing.T) {
+		assert.Equal(t, "AdditionalRoles", goName("additional roles"))
+		assert.Equal(t, "DictString", goName("dict_string"))
+		assert.Equal(t, "UserID", goName("user-id"))
+		assert.Equal(t, "X2fa", goName("2fa"))
+		assert.Equal(t, "Field", goName("--"))
+	})
+}
+
+func TestSingular(t *testing.T) {
+	t.Run("should derive element names of lists", func(t *testing.T) {
+		assert.Equal(t, "Entry", singular("Entries"))
+		assert.Equal(t, "Address", singular("Addresses"))
+		assert.Equal(t, "Server", singular("Servers"))
+		assert.Equal(t, "ClassElement", singular("Class"))
+	})
+}
@@ -0,0 +1,69 @@
+// Command ntstruct infers Go struct types with nt tags from sample NestedText documents.
+//
+// Usage:
+//
+//	ntstruct [-type Config] [-package main] [-text string|lines] [-output file] sample.nt...
+//
+// Samples are merged, keys absent in some of them are tagged with omitempty.
+// Types of nested dictionaries are named after their keys, e.g. "additional roles" becomes AdditionalRoles.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	ntgo "github.com/dolow/nt-go"
+)
+
+func main() {
+	typeName := flag.String("type", "Config", "name of the root struct type")
+	pkgName := flag.String("package", "main", "package name of the output")
+	text := flag.String("text", "string", "Go type of multiline text; string or lines for []string")
+	output := flag.String("output", "", "output file name; default standard output")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: ntstruct [flags] sample.nt...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if err := run(*typeName, *pkgName, *text, *output, flag.Args()); err != nil {
+		fmt.Fprintf(os.Stderr, "ntstruct: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(typeName string, pkgName string, text string, output string, files []string) error {
+	if len(files) == 0 {
+		flag.Usage()
+		return fmt.Errorf("no sample is given")
+	}
+	if text != "string" && text != "lines" {
+		return fmt.Errorf("-text must be string or lines, got %q", text)
+	}
+
+	samples := []*ntgo.Value{}
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		sample := &ntgo.Value{}
+		if err := sample.Parse(data); err != nil {
+			return fmt.Errorf("%s: %v", file, err)
+		}
+		samples = append(samples, sample)
+	}
+
+	source, err := infer(samples, pkgName, typeName, text == "lines")
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		_, err = os.Stdout.Write(source)
+		return err
+	}
+	return ioutil.WriteFile(output, source, 0644)
+}
@@ -0,0 +1,51 @@
+package ntgo
+
+import (
+	"strings"
+)
+
+// Functions in this file are used by code generated with cmd/ntgen.
+
+// NewTypeMismatchError returns DecodeError describing that value is not the expected type.
+func NewTypeMismatchError(value *Value, expected string) error {
+	return newTypeMismatchError(value, expected)
+}
+
+// WrapDecodeError prepends segments of struct field and key to the paths of err when it is DecodeError.
+func WrapDecodeError(err error, fieldSegment string, keySegment string) error {
+	return wrapDecodeError(err, fieldSegment, keySegment)
+}
+
+// WrapDecodeErrorWithIndex prepends index of list to the paths of err when it is DecodeError.
+func WrapDecodeErrorWithIndex(err error, index int) error {
+	return wrapDecodeErrorWithIndex(err, index)
+}
+
+// NewTextValue returns Text value consisting of lines without line breaks.
+func NewTextValue(lines []string) *Value {
+	text := make(MultilineStrings, len(lines))
+	for i, line := range lines {
+		text[i] = line
+		if i < len(lines)-1 {
+			text[i] += string(LF)
+		}
+	}
+	return &Value{Type: ValueTypeText, Text: text}
+}
+
+// MatchFieldKey returns the key of struct field in keys that receives key of dictionary.
+// Exact match is preferred, and the first key matching case-insensitively is used otherwise.
+func MatchFieldKey(key string, keys []string) (string, bool) {
+	for _, fieldKey := range keys {
+		if fieldKey == key {
+			return fieldKey, true
+		}
+	}
+	folded := strings.ToLower(key)
+	for _, fieldKey := range keys {
+		if strings.ToLower(fieldKey) == folded {
+			return fieldKey, true
+		}
+	}
+	return "", false
+}
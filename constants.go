@@ -24,4 +24,12 @@ const (
 
 	MarshallerTagFlagOmitEmpty = 1 << iota
 	MarshallerTagFlagMultilineStrings
+	MarshallerTagFlagInline
+	MarshallerTagFlagRequired
+
+	// declared after flags to keep values of them
+	MarshallerTagInline   = "inline"
+	MarshallerTagRequired = "required"
+	MarshallerTagDefault  = "default="
+	MarshallerTagFormat   = "format="
 )
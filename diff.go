@@ -0,0 +1,96 @@
+package ntgo
+
+import (
+	"fmt"
+)
+
+// ChangeType describes how a value differs between two documents.
+type ChangeType int
+
+const (
+	ChangeTypeAdded ChangeType = iota
+	ChangeTypeRemoved
+	ChangeTypeModified
+)
+
+func (t ChangeType) String() string {
+	switch t {
+	case ChangeTypeAdded:
+		return "added"
+	case ChangeTypeRemoved:
+		return "removed"
+	case ChangeTypeModified:
+		return "modified"
+	}
+	return "unknown"
+}
+
+// Change is a value added, removed or modified at KeyPath.
+type Change struct {
+	Type    ChangeType
+	KeyPath string
+	// Old is nil when the value is added
+	Old *Value
+	// New is nil when the value is removed
+	New *Value
+}
+
+func (c *Change) String() string {
+	return fmt.Sprintf("%s %s", c.Type, displayPath(c.KeyPath))
+}
+
+// Diff returns changes from old to new.
+// Order of keys, indentation and comments are ignored, and values of different types are modified as a whole.
+// Changes of keys in old come first in order of appearance, followed by keys added in new.
+func Diff(old *Value, new *Value) []*Change {
+	changes := []*Change{}
+	diffValue(old, new, "", &changes)
+	return changes
+}
+
+func diffValue(old *Value, new *Value, path string, changes *[]*Change) {
+	switch {
+	case old == nil && new == nil:
+		return
+	case old == nil:
+		*changes = append(*changes, &Change{Type: ChangeTypeAdded, KeyPath: path, New: new})
+		return
+	case new == nil:
+		*changes = append(*changes, &Change{Type: ChangeTypeRemoved, KeyPath: path, Old: old})
+		return
+	case old.Type != new.Type:
+		*changes = append(*changes, &Change{Type: ChangeTypeModified, KeyPath: path, Old: old, New: new})
+		return
+	}
+
+	switch old.Type {
+	case ValueTypeString:
+		if old.String != new.String {
+			*changes = append(*changes, &Change{Type: ChangeTypeModified, KeyPath: path, Old: old, New: new})
+		}
+	case ValueTypeText:
+		if old.Text.String() != new.Text.String() {
+			*changes = append(*changes, &Change{Type: ChangeTypeModified, KeyPath: path, Old: old, New: new})
+		}
+	case ValueTypeList:
+		for i := 0; i < len(old.List) || i < len(new.List); i++ {
+			var oldChild, newChild *Value
+			if i < len(old.List) {
+				oldChild = old.List[i]
+			}
+			if i < len(new.List) {
+				newChild = new.List[i]
+			}
+			diffValue(oldChild, newChild, appendIndexPath(path, i), changes)
+		}
+	case ValueTypeDictionary:
+		for _, key := range old.Keys() {
+			diffValue(old.Dictionary[key], new.Dictionary[key], appendKeyPath(path, key), changes)
+		}
+		for _, key := range new.Keys() {
+			if _, exists := old.Dictionary[key]; !exists {
+				diffValue(nil, new.Dictionary[key], appendKeyPath(path, key), changes)
+			}
+		}
+	}
+}
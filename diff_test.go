@@ -0,0 +1,70 @@
+package ntgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	subject := func(old string, new string) []string {
+		oldValue := &Value{}
+		oldValue.Parse([]byte(old))
+		newValue := &Value{}
+		newValue.Parse([]byte(new))
+		result := []string{}
+		for _, change := range Diff(oldValue, newValue) {
+			result = append(result, change.String())
+		}
+		return result
+	}
+
+	t.Run("same documents", func(t *testing.T) {
+		t.Run("should have no changes regardless of key order and comments", func(t *testing.T) {
+			assert.Equal(t, []string{}, subject("a: 1\nb:\n  - x", "# comment\nb:\n    - x\na: 1"))
+		})
+	})
+
+	t.Run("dictionary", func(t *testing.T) {
+		t.Run("should report keys in order of old keys and added keys", func(t *testing.T) {
+			assert.Equal(t, []string{
+				`removed "a"`,
+				`modified "b.c"`,
+				`added "d"`,
+			}, subject("a: 1\nb:\n  c: 2\n  e: 3", "b:\n  e: 3\n  c: 4\nd: 5"))
+		})
+	})
+
+	t.Run("list", func(t *testing.T) {
+		t.Run("should report elements by index", func(t *testing.T) {
+			assert.Equal(t, []string{`modified "list[1]"`, `added "list[2]"`}, subject("list:\n  - a\n  - b", "list:\n  - a\n  - c\n  - d"))
+			assert.Equal(t, []string{`removed "list[1]"`}, subject("list:\n  - a\n  - b", "list:\n  - a"))
+		})
+	})
+
+	t.Run("different types", func(t *testing.T) {
+		t.Run("should be modified as a whole", func(t *testing.T) {
+			assert.Equal(t, []string{`modified "a"`}, subject("a:\n  b: 1", "a:\n  - 1"))
+		})
+	})
+
+	t.Run("text", func(t *testing.T) {
+		t.Run("should be compared by content", func(t *testing.T) {
+			assert.Equal(t, []string{}, subject("a:\n  > x\n  > y", "a:\n    > x\n    > y"))
+			assert.Equal(t, []string{`modified "a"`}, subject("a:\n  > x\n  > y", "a:\n  > x"))
+		})
+	})
+
+	t.Run("change", func(t *testing.T) {
+		t.Run("should hold old and new values", func(t *testing.T) {
+			oldValue := &Value{}
+			oldValue.Parse([]byte("a: 1"))
+			newValue := &Value{}
+			newValue.Parse([]byte("a: 2"))
+			changes := Diff(oldValue, newValue)
+			assert.Equal(t, 1, len(changes))
+			assert.Equal(t, "1", changes[0].Old.String)
+			assert.Equal(t, "2", changes[0].New.String)
+		})
+	})
+}
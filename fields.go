@@ -0,0 +1,333 @@
+package ntgo
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// field describes a struct field mapped to a dictionary key.
+// Fields of embedded structs and structs tagged with inline are promoted to the enclosing struct.
+type field struct {
+	key     string
+	name    string
+	index   []int
+	typ     reflect.Type
+	tagFlag int
+	tagged  bool
+
+	defaultValue string
+	hasDefault   bool
+	format       string
+}
+
+// TagError describes nt tag that can not be applied to a struct field.
+type TagError struct {
+	Type  reflect.Type
+	Field string
+	Tag   string
+	Err   error
+}
+
+func (e *TagError) Error() string {
+	return fmt.Sprintf("ntgo: tag %q of %v.%s: %v", e.Tag, e.Type, e.Field, e.Err)
+}
+
+func (e *TagError) Unwrap() error {
+	return e.Err
+}
+
+// typeFields returns fields of typ to be mapped in order of declaration.
+// Keys of fields without nt tag are derived from their names with naming, or names as they are when it is nil.
+// Fields tagged with "-" are ignored.
+// Conflicts of keys are resolved in the same manner as encoding/json;
+// the shallowest field wins, tagged one is preferred among the same depth,
+// and all of them are ignored when it is still ambiguous.
+// Tags that can not be applied to fields are returned as *TagError.
+func typeFields(typ reflect.Type, naming NamingStrategy) ([]field, error) {
+	type candidate struct {
+		typ   reflect.Type
+		index []int
+		names []string
+	}
+
+	current := []candidate{}
+	next := []candidate{{typ: typ}}
+	visited := map[reflect.Type]bool{}
+
+	fields := []field{}
+
+	for len(next) > 0 {
+		current, next = next, current[:0]
+		count := map[reflect.Type]int{}
+		for _, c := range current {
+			count[c.typ]++
+		}
+
+		for _, c := range current {
+			if visited[c.typ] {
+				continue
+			}
+			visited[c.typ] = true
+
+			for i := 0; i < c.typ.NumField(); i++ {
+				fieldInfo := c.typ.Field(i)
+				tagValue := fieldInfo.Tag.Get(MarshallerTag)
+				if tagValue == "-" {
+					continue
+				}
+
+				fieldType := fieldInfo.Type
+				if fieldType.Kind() == reflect.Ptr {
+					fieldType = fieldType.Elem()
+				}
+
+				tagValues := strings.Split(tagValue, MarshallerTagSeparator)
+				key := tagValues[0]
+				tagFlag := getTagFlagFromTagValue(tagValues)
+
+				index := make([]int, len(c.index)+1)
+				copy(index, c.index)
+				index[len(c.index)] = i
+
+				names := make([]string, len(c.names)+1)
+				copy(names, c.names)
+				names[len(c.names)] = fieldInfo.Name
+
+				if (tagFlag&MarshallerTagFlagInline) == MarshallerTagFlagInline && fieldType.Kind() != reflect.Struct {
+					return nil, &TagError{Type: c.typ, Field: fieldInfo.Name, Tag: tagValue, Err: fmt.Errorf("%s requires struct type, got %v", MarshallerTagInline, fieldInfo.Type)}
+				}
+
+				promote := fieldType.Kind() == reflect.Struct &&
+					((fieldInfo.Anonymous && tagValue == "") || (key == "" && (tagFlag&MarshallerTagFlagInline) == MarshallerTagFlagInline))
+
+				if promote {
+					if fieldInfo.PkgPath != "" && fieldInfo.Type.Kind() == reflect.Ptr {
+						// pointer to unexported struct can not be allocated
+						continue
+					}
+					next = append(next, candidate{typ: fieldType, index: index, names: names})
+					continue
+				}
+
+				if fieldInfo.PkgPath != "" {
+					continue
+				}
+
+				tagged := key != ""
+				if !tagged {
+					key = fieldInfo.Name
+					if naming != nil {
+						key = naming(key)
+					}
+				}
+
+				f := field{
+					key:     key,
+					name:    strings.Join(names, "."),
+					index:   index,
+					typ:     fieldInfo.Type,
+					tagFlag: tagFlag,
+					tagged:  tagged,
+				}
+				f.defaultValue, f.hasDefault = getTagOptionFromTagValue(tagValues, MarshallerTagDefault)
+				f.format, _ = getTagOptionFromTagValue(tagValues, MarshallerTagFormat)
+				if err := checkFormat(fieldInfo.Type, f.format); err != nil {
+					return nil, &TagError{Type: c.typ, Field: fieldInfo.Name, Tag: tagValue, Err: err}
+				}
+				fields = append(fields, f)
+				if count[c.typ] > 1 {
+					// the same struct appears multiple times at the same depth,
+					// duplicate it to make it ambiguous.
+					fields = append(fields, f)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(fields, func(i, j int) bool {
+		if fields[i].key != fields[j].key {
+			return fields[i].key < fields[j].key
+		}
+		if len(fields[i].index) != len(fields[j].index) {
+			return len(fields[i].index) < len(fields[j].index)
+		}
+		return fields[i].tagged && !fields[j].tagged
+	})
+
+	dominants := fields[:0]
+	for begin := 0; begin < len(fields); {
+		end := begin + 1
+		for end < len(fields) && fields[end].key == fields[begin].key {
+			end++
+		}
+		if dominant, ok := dominantField(fields[begin:end]); ok {
+			dominants = append(dominants, dominant)
+		}
+		begin = end
+	}
+
+	sort.Slice(dominants, func(i, j int) bool {
+		x := dominants[i].index
+		y := dominants[j].index
+		for k := 0; k < len(x) && k < len(y); k++ {
+			if x[k] != y[k] {
+				return x[k] < y[k]
+			}
+		}
+		return len(x) < len(y)
+	})
+
+	return dominants, nil
+}
+
+// formatVerbPattern matches a single verb of fmt package, groups are flags, width and the verb.
+var formatVerbPattern = regexp.MustCompile(`^%([+0#]*)([0-9]*)(?:\.[0-9]*)?([a-zA-Z])$`)
+
+// checkFormat returns error when scalars of typ written with format can not be decoded by strconv.
+// Only a single verb without padding by spaces is accepted.
+func checkFormat(typ reflect.Type, format string) error {
+	if format == "" {
+		return nil
+	}
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array || typ.Kind() == reflect.Map {
+		typ = typ.Elem()
+	}
+	if !isScalarKind(typ.Kind()) {
+		return nil
+	}
+
+	verbs, flags := "", ""
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		verbs, flags = "dv", "+0"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		verbs, flags = "dv", "0"
+	case reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		verbs, flags = "eEfFgGv", "+0#"
+	case reflect.Bool:
+		verbs = "tv"
+	}
+
+	match := formatVerbPattern.FindStringSubmatch(format)
+	if match == nil || !strings.Contains(verbs, match[3]) ||
+		strings.Trim(match[1], flags) != "" || (match[2] != "" && !strings.Contains(match[1], "0")) ||
+		(typ.Kind() == reflect.Bool && format != "%"+match[3]) {
+		return fmt.Errorf("%s%s can not be decoded into %v", MarshallerTagFormat, format, typ)
+	}
+	return nil
+}
+
+// dominantField picks a field from fields sharing the same key sorted by depth and tagged.
+func dominantField(fields []field) (field, bool) {
+	if len(fields) > 1 && len(fields[0].index) == len(fields[1].index) && fields[0].tagged == fields[1].tagged {
+		return field{}, false
+	}
+	return fields[0], true
+}
+
+// structFields is the plan for mapping a struct type, built once per type and naming strategy.
+// Encoding and decoding functions are not part of the plan,
+// since they depend on options of Marshaller and dynamic types of values.
+type structFields struct {
+	list        []field
+	byKey       map[string]int
+	byFoldedKey map[string]int
+	// err is the error of tags, cached as well
+	err error
+}
+
+type fieldCacheKey struct {
+	typ    reflect.Type
+	naming uintptr
+}
+
+var fieldCache sync.Map // map[fieldCacheKey]*structFields
+
+// cachedTypeFields is like typeFields but caches the result.
+// Plans are cached only without naming strategy or with predefined ones,
+// since functions can not be compared and closures created by the same function share their code.
+func cachedTypeFields(typ reflect.Type, naming NamingStrategy) (*structFields, error) {
+	id, cacheable := namingStrategyID(naming)
+	if !cacheable {
+		fields := newStructFields(typ, naming)
+		return fields, fields.err
+	}
+
+	key := fieldCacheKey{typ: typ, naming: id}
+	if cached, ok := fieldCache.Load(key); ok {
+		fields := cached.(*structFields)
+		return fields, fields.err
+	}
+
+	cached, _ := fieldCache.LoadOrStore(key, newStructFields(typ, naming))
+	fields := cached.(*structFields)
+	return fields, fields.err
+}
+
+// namingStrategyID identifies nil and predefined naming strategies by their functions.
+func namingStrategyID(naming NamingStrategy) (uintptr, bool) {
+	if naming == nil {
+		return 0, true
+	}
+	id := reflect.ValueOf(naming).Pointer()
+	for _, predefined := range []NamingStrategy{SnakeCase, KebabCase, SpaceSeparated} {
+		if reflect.ValueOf(predefined).Pointer() == id {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+func newStructFields(typ reflect.Type, naming NamingStrategy) *structFields {
+	list, err := typeFields(typ, naming)
+	fields := &structFields{
+		list:        list,
+		byKey:       map[string]int{},
+		byFoldedKey: map[string]int{},
+		err:         err,
+	}
+	for i, f := range fields.list {
+		fields.byKey[f.key] = i
+		folded := strings.ToLower(f.key)
+		if _, exists := fields.byFoldedKey[folded]; !exists {
+			fields.byFoldedKey[folded] = i
+		}
+	}
+	return fields
+}
+
+// lookup returns the field for key, case-insensitive match is used when no field has exactly the same key.
+func (s *structFields) lookup(key string) (field, bool) {
+	if i, ok := s.byKey[key]; ok {
+		return s.list[i], true
+	}
+	if i, ok := s.byFoldedKey[strings.ToLower(key)]; ok {
+		return s.list[i], true
+	}
+	return field{}, false
+}
+
+// fieldByIndex returns the field of ref specified with index.
+// Nil embedded pointers are allocated when alloc is true, otherwise false is returned.
+func fieldByIndex(ref reflect.Value, index []int, alloc bool) (reflect.Value, bool, error) {
+	for i, x := range index {
+		if i > 0 && ref.Kind() == reflect.Ptr {
+			if ref.IsNil() {
+				if !alloc {
+					return reflect.Value{}, false, nil
+				}
+				if !ref.CanSet() {
+					return reflect.Value{}, false, fmt.Errorf("can not set embedded pointer to unexported struct %v", ref.Type().Elem())
+				}
+				ref.Set(reflect.New(ref.Type().Elem()))
+			}
+			ref = ref.Elem()
+		}
+		ref = ref.Field(x)
+	}
+	return ref, true, nil
+}
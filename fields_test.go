@@ -0,0 +1,243 @@
+package ntgo
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type EmbeddedCommon struct {
+	Name    string `nt:"name"`
+	Version string `nt:"version"`
+}
+
+type EmbeddedConflictA struct {
+	Label string `nt:"label"`
+}
+
+type EmbeddedConflictB struct {
+	Label string `nt:"label"`
+}
+
+type embeddedUnexported struct {
+	Secret string `nt:"secret"`
+}
+
+func TestTypeFields(t *testing.T) {
+	keys := func(typ reflect.Type) []string {
+		ret := []string{}
+		fields, _ := typeFields(typ, nil)
+		for _, f := range fields {
+			ret = append(ret, f.key)
+		}
+		return ret
+	}
+
+	t.Run("embedded struct", func(t *testing.T) {
+		typ := reflect.TypeOf(struct {
+			ID string `nt:"id"`
+			EmbeddedCommon
+			Port string `nt:"port"`
+		}{})
+
+		t.Run("should promote fields in order of declaration", func(t *testing.T) {
+			assert.Equal(t, []string{"id", "name", "version", "port"}, keys(typ))
+		})
+
+		t.Run("should have index and name through embedded struct", func(t *testing.T) {
+			fields, _ := typeFields(typ, nil)
+			f := fields[1]
+			assert.Equal(t, []int{1, 0}, f.index)
+			assert.Equal(t, "EmbeddedCommon.Name", f.name)
+		})
+	})
+
+	t.Run("tagged embedded struct", func(t *testing.T) {
+		typ := reflect.TypeOf(struct {
+			EmbeddedCommon `nt:"common"`
+		}{})
+
+		t.Run("should be treated as a dictionary", func(t *testing.T) {
+			assert.Equal(t, []string{"common"}, keys(typ))
+		})
+	})
+
+	t.Run("inline", func(t *testing.T) {
+		typ := reflect.TypeOf(struct {
+			Common  EmbeddedCommon     `nt:",inline"`
+			Pointer *EmbeddedConflictA `nt:",inline"`
+		}{})
+
+		t.Run("should promote fields of named field", func(t *testing.T) {
+			assert.Equal(t, []string{"name", "version", "label"}, keys(typ))
+		})
+
+		t.Run("should be rejected for non-struct field", func(t *testing.T) {
+			typ := reflect.TypeOf(struct {
+				Name  string `nt:"name"`
+				Ports []int  `nt:",inline"`
+			}{})
+			_, err := typeFields(typ, nil)
+			tagErr, ok := err.(*TagError)
+			assert.True(t, ok)
+			assert.Equal(t, "Ports", tagErr.Field)
+			assert.Equal(t, ",inline", tagErr.Tag)
+
+			_, err = (&Marshaller{}).Unmarshal(reflect.New(typ).Interface())
+			assert.Equal(t, tagErr, err)
+			err = Marshal("name: a", reflect.New(typ).Interface())
+			assert.Equal(t, tagErr, err)
+		})
+	})
+
+	t.Run("format", func(t *testing.T) {
+		t.Run("should accept verbs decoded by strconv", func(t *testing.T) {
+			_, err := typeFields(reflect.TypeOf(struct {
+				Int     int       `nt:"int,format=%+05d"`
+				Uint    *uint     `nt:"uint,format=%d"`
+				Float   []float64 `nt:"float,format=%.3e"`
+				Complex complex64 `nt:"complex,format=%g"`
+				Bool    bool      `nt:"bool,format=%t"`
+				Name    string    `nt:"name,format=%q"`
+			}{}), nil)
+			assert.Nil(t, err)
+		})
+
+		t.Run("should reject verbs not decoded by strconv", func(t *testing.T) {
+			cases := []struct {
+				value  interface{}
+				format string
+			}{
+				{0, "%x"}, {0, "%5d"}, {0, "%-5d"}, {0, "% d"}, {0, "%d px"}, {uint(0), "%+d"}, {0.0, "%8.2f"}, {false, "%5t"},
+			}
+			for _, c := range cases {
+				typ := reflect.StructOf([]reflect.StructField{
+					{Name: "Field", Type: reflect.TypeOf(c.value), Tag: reflect.StructTag(`nt:"field,format=` + c.format + `"`)},
+				})
+				_, err := typeFields(typ, nil)
+				assert.IsType(t, &TagError{}, err, c.format)
+			}
+		})
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		t.Run("should prefer shallower field", func(t *testing.T) {
+			typ := reflect.TypeOf(struct {
+				EmbeddedCommon
+				Name string `nt:"name"`
+			}{})
+			fields, _ := typeFields(typ, nil)
+			assert.Equal(t, []string{"version", "name"}, keys(typ))
+			assert.Equal(t, []int{1}, fields[1].index)
+		})
+
+		t.Run("should ignore fields of the same struct at the same depth", func(t *testing.T) {
+			typ := reflect.TypeOf(struct {
+				EmbeddedCommon
+				Owner EmbeddedCommon `nt:",inline"`
+			}{})
+			assert.Equal(t, []string{}, keys(typ))
+		})
+
+		t.Run("should ignore ambiguous fields", func(t *testing.T) {
+			typ := reflect.TypeOf(struct {
+				EmbeddedConflictA
+				EmbeddedConflictB
+				Other string `nt:"other"`
+			}{})
+			assert.Equal(t, []string{"other"}, keys(typ))
+		})
+	})
+
+	t.Run("unexported", func(t *testing.T) {
+		typ := reflect.TypeOf(struct {
+			embeddedUnexported
+			hidden string `nt:"hidden"`
+		}{})
+
+		t.Run("should promote fields of unexported embedded struct but ignore unexported fields", func(t *testing.T) {
+			assert.Equal(t, []string{"secret"}, keys(typ))
+		})
+	})
+}
+
+// plan returns the result of cachedTypeFields ignoring errors.
+func plan(typ reflect.Type, naming NamingStrategy) *structFields {
+	fields, _ := cachedTypeFields(typ, naming)
+	return fields
+}
+
+func TestCachedTypeFields(t *testing.T) {
+	typ := reflect.TypeOf(UntaggedOfficer{})
+
+	t.Run("should return the same plan for the same type", func(t *testing.T) {
+		assert.True(t, plan(typ, nil) == plan(typ, nil))
+	})
+
+	t.Run("should build plans for each naming strategy", func(t *testing.T) {
+		assert.Equal(t, "AdditionalRoles", plan(typ, nil).list[3].key)
+		assert.Equal(t, "additional_roles", plan(typ, SnakeCase).list[3].key)
+		assert.Equal(t, "additional-roles", plan(typ, KebabCase).list[3].key)
+	})
+
+	t.Run("should not share plans between closures of the same function", func(t *testing.T) {
+		prefix := func(p string) NamingStrategy {
+			return func(fieldName string) string { return p + fieldName }
+		}
+		assert.Equal(t, "a_Name", plan(typ, prefix("a_")).list[0].key)
+		assert.Equal(t, "b_Name", plan(typ, prefix("b_")).list[0].key)
+
+		s := &UntaggedOfficer{}
+		err := (&Marshaller{NamingStrategy: prefix("b_")}).Marshal("b_Name: smith", s)
+		assert.Nil(t, err)
+		assert.Equal(t, "smith", s.Name)
+	})
+
+	t.Run("should be safe for concurrent use", func(t *testing.T) {
+		typ := reflect.TypeOf(struct {
+			EmbeddedCommon
+			Port string `nt:"port"`
+		}{})
+
+		plans := make([]*structFields, 8)
+		wg := sync.WaitGroup{}
+		for i := range plans {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				plans[i] = plan(typ, nil)
+			}(i)
+		}
+		wg.Wait()
+
+		for _, plan := range plans {
+			assert.True(t, plans[0] == plan)
+		}
+	})
+}
+
+func TestStructFieldsLookup(t *testing.T) {
+	fields := plan(reflect.TypeOf(struct {
+		Name  string
+		Lower string `nt:"name"`
+	}{}), nil)
+
+	t.Run("should prefer exact match", func(t *testing.T) {
+		f, ok := fields.lookup("name")
+		assert.True(t, ok)
+		assert.Equal(t, "Lower", f.name)
+	})
+
+	t.Run("should match case-insensitively", func(t *testing.T) {
+		f, ok := fields.lookup("NAME")
+		assert.True(t, ok)
+		assert.Equal(t, "Name", f.name)
+	})
+
+	t.Run("should report absence", func(t *testing.T) {
+		_, ok := fields.lookup("other")
+		assert.False(t, ok)
+	})
+}
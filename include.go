@@ -0,0 +1,153 @@
+package ntgo
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// IncludeDirective prefixes string values replaced with the document of the file following it,
+// e.g. "database: !include database.nt".
+// Paths are relative to the including file, or to the root of file system when they start with a slash.
+const IncludeDirective = "!include "
+
+var (
+	IncludeCycleError = errors.New("ntgo: include cycle")
+)
+
+// ParseError describes a document that could not be read or parsed.
+type ParseError struct {
+	File string
+	// Line is the line of the cause in File, 0 when unknown
+	Line int
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	location := e.File
+	if e.Line > 0 {
+		location = fmt.Sprintf("%s:%d", location, e.Line)
+	}
+	return fmt.Sprintf("ntgo: %s: %s", location, strings.TrimPrefix(e.Err.Error(), "ntgo: "))
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ParseFile reads and parses name in fsys, see ParseNamed.
+func ParseFile(fsys fs.FS, name string) (*Value, error) {
+	content, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, &ParseError{File: name, Err: err}
+	}
+
+	return ParseNamed(name, content)
+}
+
+// ParseNamed parses content read from name.
+// Errors are returned as *ParseError with the line where content stops being a valid document.
+func ParseNamed(name string, content []byte) (*Value, error) {
+	value := &Value{}
+	if err := value.parse(content, contentLines(content)); err != nil {
+		parseErr := &ParseError{File: name, Err: err}
+		if located, ok := err.(*syntaxError); ok {
+			parseErr.Line = located.line
+			parseErr.Err = located.err
+		}
+		return nil, parseErr
+	}
+	setValueFile(value, name)
+	return value, nil
+}
+
+func setValueFile(value *Value, name string) {
+	value.File = name
+	for _, child := range value.List {
+		setValueFile(child, name)
+	}
+	for _, child := range value.Dictionary {
+		setValueFile(child, name)
+	}
+}
+
+// ParseFileWithIncludes works like ParseFile and replaces include directives with documents of included files.
+func ParseFileWithIncludes(fsys fs.FS, name string) (*Value, error) {
+	value, err := ParseFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := value.ResolveIncludes(fsys, name); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// ResolveIncludes replaces include directives in v parsed from name in fsys with documents of included files.
+// Included files may include other files, cycles are returned as *ParseError wrapping IncludeCycleError.
+func (v *Value) ResolveIncludes(fsys fs.FS, name string) error {
+	return v.resolveIncludes(fsys, name, []string{path.Clean(name)})
+}
+
+func (v *Value) resolveIncludes(fsys fs.FS, name string, chain []string) error {
+	switch v.Type {
+	case ValueTypeString:
+		if !strings.HasPrefix(v.String, IncludeDirective) {
+			return nil
+		}
+		return v.include(fsys, name, strings.TrimSpace(strings.TrimPrefix(v.String, IncludeDirective)), chain)
+	case ValueTypeList:
+		for _, child := range v.List {
+			if err := child.resolveIncludes(fsys, name, chain); err != nil {
+				return err
+			}
+		}
+	case ValueTypeDictionary:
+		for _, key := range v.Keys() {
+			if err := v.Dictionary[key].resolveIncludes(fsys, name, chain); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// include replaces v with the document of target included from name.
+func (v *Value) include(fsys fs.FS, name string, target string, chain []string) error {
+	if strings.HasPrefix(target, "/") {
+		target = path.Clean(strings.TrimPrefix(target, "/"))
+	} else {
+		target = path.Join(path.Dir(name), target)
+	}
+	if !fs.ValidPath(target) {
+		return &ParseError{File: name, Line: v.Line, Err: fmt.Errorf("invalid include path %q", target)}
+	}
+
+	for _, including := range chain {
+		if including == target {
+			return &ParseError{
+				File: name,
+				Line: v.Line,
+				Err:  fmt.Errorf("%w: %s", IncludeCycleError, strings.Join(append(chain, target), " -> ")),
+			}
+		}
+	}
+
+	included, err := ParseFile(fsys, target)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return &ParseError{File: name, Line: v.Line, Err: fmt.Errorf("included file %s does not exist", target)}
+		}
+		return err
+	}
+	nextChain := append(append([]string{}, chain...), target)
+	if err := included.resolveIncludes(fsys, target, nextChain); err != nil {
+		return err
+	}
+
+	setValueDepth(included, v.Depth)
+	*v = *included
+	return nil
+}
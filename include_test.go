@@ -0,0 +1,208 @@
+package ntgo
+
+import (
+	"errors"
+	"io/fs"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFileWithIncludes(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app.nt":              {Data: []byte("name: app\ndatabase: !include config/database.nt\nservers:\n  - !include config/server.nt\n  - local")},
+		"config/database.nt":  {Data: []byte("host: localhost\nauth: !include auth/user.nt")},
+		"config/auth/user.nt": {Data: []byte("user: admin\nroles: !include /roles.nt")},
+		"config/server.nt":    {Data: []byte("- a.example.com\n- b.example.com")},
+		"roles.nt":            {Data: []byte("- read\n- write")},
+		"plain.nt":            {Data: []byte("command: !include app.nt")},
+		"cycle/a.nt":          {Data: []byte("b: !include b.nt")},
+		"cycle/b.nt":          {Data: []byte("\na: !include a.nt")},
+		"missing.nt":          {Data: []byte("key:\n  nested: !include none.nt")},
+		"outside.nt":          {Data: []byte("key: !include ../secret.nt")},
+		"broken/root.nt":      {Data: []byte("key: !include broken.nt")},
+		"broken/broken.nt":    {Data: []byte("key: value\n  - element")},
+	}
+
+	t.Run("included files", func(t *testing.T) {
+		t.Run("should be spliced into the tree", func(t *testing.T) {
+			value, err := ParseFileWithIncludes(fsys, "app.nt")
+			assert.Nil(t, err)
+			assert.Equal(t, map[string]interface{}{
+				"name": "app",
+				"database": map[string]interface{}{
+					"host": "localhost",
+					"auth": map[string]interface{}{
+						"user":  "admin",
+						"roles": []interface{}{"read", "write"},
+					},
+				},
+				"servers": []interface{}{
+					[]interface{}{"a.example.com", "b.example.com"},
+					"local",
+				},
+			}, value.ToInterface())
+		})
+
+		t.Run("should be written at the depth of the directive", func(t *testing.T) {
+			value, _ := ParseFileWithIncludes(fsys, "config/database.nt")
+			assert.Equal(t, "host: localhost\nauth:\n  user: admin\n  roles:\n    - read\n    - write\n", value.ToNestedText())
+		})
+	})
+
+	t.Run("ParseFile", func(t *testing.T) {
+		t.Run("should leave directives as they are", func(t *testing.T) {
+			value, err := ParseFile(fsys, "plain.nt")
+			assert.Nil(t, err)
+			assert.Equal(t, "!include app.nt", value.Dictionary["command"].String)
+		})
+	})
+
+	t.Run("include cycle", func(t *testing.T) {
+		t.Run("should be reported with the file and line of the directive", func(t *testing.T) {
+			_, err := ParseFileWithIncludes(fsys, "cycle/a.nt")
+			assert.True(t, errors.Is(err, IncludeCycleError))
+			assert.Equal(t, &ParseError{File: "cycle/b.nt", Line: 2, Err: errors.Unwrap(err)}, err)
+			assert.Equal(t, "ntgo: cycle/b.nt:2: include cycle: cycle/a.nt -> cycle/b.nt -> cycle/a.nt", err.Error())
+		})
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		t.Run("should be reported with the file and line of the directive", func(t *testing.T) {
+			_, err := ParseFileWithIncludes(fsys, "missing.nt")
+			assert.Equal(t, "ntgo: missing.nt:2: included file none.nt does not exist", err.Error())
+		})
+
+		t.Run("should be reported with the name when it is the root document", func(t *testing.T) {
+			_, err := ParseFileWithIncludes(fsys, "none.nt")
+			assert.True(t, errors.Is(err, fs.ErrNotExist))
+			assert.Equal(t, "none.nt", err.(*ParseError).File)
+		})
+	})
+
+	t.Run("path outside of file system", func(t *testing.T) {
+		t.Run("should be rejected", func(t *testing.T) {
+			_, err := ParseFileWithIncludes(fsys, "outside.nt")
+			assert.Equal(t, "ntgo: outside.nt:1: invalid include path \"../secret.nt\"", err.Error())
+		})
+	})
+
+	t.Run("invalid included file", func(t *testing.T) {
+		t.Run("should be reported with the name of the included file", func(t *testing.T) {
+			_, err := ParseFileWithIncludes(fsys, "broken/root.nt")
+			parseErr, ok := err.(*ParseError)
+			assert.True(t, ok)
+			assert.Equal(t, "broken/broken.nt", parseErr.File)
+			assert.Equal(t, 2, parseErr.Line)
+		})
+	})
+}
+
+func TestParseNamed(t *testing.T) {
+	t.Run("invalid content", func(t *testing.T) {
+		t.Run("should be reported with the line where it becomes invalid", func(t *testing.T) {
+			cases := []struct {
+				content string
+				line    int
+				err     error
+			}{
+				{content: "  key: value", line: 1, err: RootLevelHasIndentError},
+				{content: "# comment\nkey: value\n\nkey: other", line: 4, err: DictionaryDuplicateKeyError},
+				{content: "a:\n  b: 1\n  c: 2\n - d", line: 4},
+				{content: "list:\n  - a\n  - b\n  c: d", line: 4},
+				{content: "a: 1\r\nb: 2\r\n\tc: 3", line: 3, err: TabInIndentationError},
+				{content: "a:\n  b:\n\n    # comment\n    c: 1\n    c: 2", line: 6, err: DictionaryDuplicateKeyError},
+				{content: "a:\r\n  b:\r\n\r\n    c: 1\r\n    - d", line: 5, err: DifferentTypesOnTheSameLevelError},
+				{content: "a:\n b: 1\n    > t\n  c: d", line: 4, err: DifferentLevelOnSameChildError},
+			}
+			for _, c := range cases {
+				_, err := ParseNamed("file.nt", []byte(c.content))
+				parseErr, ok := err.(*ParseError)
+				assert.True(t, ok, c.content)
+				assert.Equal(t, "file.nt", parseErr.File)
+				assert.Equal(t, c.line, parseErr.Line, c.content)
+				if c.err != nil {
+					assert.Equal(t, c.err, parseErr.Err)
+				}
+			}
+		})
+
+		t.Run("should not have line when it is empty", func(t *testing.T) {
+			_, err := ParseNamed("file.nt", []byte("# comment only"))
+			assert.Equal(t, &ParseError{File: "file.nt", Err: EmptyDataError}, err)
+			assert.Equal(t, "ntgo: file.nt: data can not be empty", err.Error())
+		})
+	})
+}
+
+func TestMarshallerMarshalFile(t *testing.T) {
+	type Config struct {
+		Name  string   `nt:"name"`
+		Hosts []string `nt:"hosts"`
+	}
+	type Server struct {
+		Host string `nt:"host,required"`
+		Port int    `nt:"port"`
+	}
+	type Servers struct {
+		Name    string    `nt:"name"`
+		Servers []*Server `nt:"servers"`
+	}
+	fsys := fstest.MapFS{
+		"config.nt":  {Data: []byte("name: app\nhosts: !include hosts.nt")},
+		"hosts.nt":   {Data: []byte("- a\n- b")},
+		"servers.nt": {Data: []byte("name: app\nservers:\n  - !include server.nt\n  -\n    host: b\n    user: root")},
+		"server.nt":  {Data: []byte("# server\nport: none\nuser: admin")},
+	}
+
+	t.Run("with Include", func(t *testing.T) {
+		t.Run("should decode included files", func(t *testing.T) {
+			config := &Config{}
+			err := (&Marshaller{Include: true}).MarshalFile(fsys, "config.nt", config)
+			assert.Nil(t, err)
+			assert.Equal(t, &Config{Name: "app", Hosts: []string{"a", "b"}}, config)
+		})
+
+		t.Run("should report the included file of the value that failed to be decoded", func(t *testing.T) {
+			err := (&Marshaller{Include: true}).MarshalFile(fsys, "servers.nt", &Servers{})
+			decodeErr, ok := err.(*DecodeError)
+			assert.True(t, ok)
+			assert.Equal(t, "server.nt", decodeErr.File)
+			assert.Equal(t, 2, decodeErr.Line)
+			assert.Equal(t, `ntgo: struct field Servers[0].Port: strconv.ParseInt: parsing "none": invalid syntax (key "servers[0].port", server.nt:2)`, err.Error())
+		})
+
+		t.Run("should report files of unknown and missing keys in order of appearance", func(t *testing.T) {
+			m := &Marshaller{Include: true, DisallowUnknownFields: true}
+			err := m.MarshalFile(fstest.MapFS{
+				"servers.nt": fsys["servers.nt"],
+				"server.nt":  {Data: []byte("# server\nport: 80\nuser: admin")},
+			}, "servers.nt", &Servers{})
+			unknownErr, ok := err.(*UnknownKeysError)
+			assert.True(t, ok)
+			assert.Equal(t, []*UnknownKeyError{
+				{KeyPath: "servers[0].user", Line: 3, File: "server.nt"},
+				{KeyPath: "servers[1].user", Line: 6, File: "servers.nt"},
+			}, unknownErr.Keys)
+			assert.Equal(t, []*MissingKeyError{
+				{KeyPath: "servers[0].host", Field: "Host", Struct: reflect.TypeOf(Server{}), Line: 2, File: "server.nt"},
+			}, unknownErr.Missing.Keys)
+			assert.Equal(t, "ntgo: 2 unknown key(s)\n"+
+				"\tunknown key \"servers[0].user\" (server.nt:3)\n"+
+				"\tunknown key \"servers[1].user\" (servers.nt:6)\n"+
+				"1 missing key(s)\n"+
+				"\tmissing key \"servers[0].host\" for field Host of ntgo.Server (server.nt:2)", err.Error())
+		})
+	})
+
+	t.Run("without Include", func(t *testing.T) {
+		t.Run("should decode directives as strings", func(t *testing.T) {
+			config := &Config{}
+			err := (&Marshaller{}).MarshalFile(fsys, "config.nt", config)
+			assert.Nil(t, err)
+			assert.Equal(t, &Config{Name: "app", Hosts: []string{"!include hosts.nt"}}, config)
+		})
+	})
+}
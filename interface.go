@@ -0,0 +1,178 @@
+package ntgo
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// TextConversion describes how Text values are represented as generic Go values.
+type TextConversion int
+
+const (
+	// TextConversionJoin represents Text as a single string joined with its line breaks.
+	TextConversionJoin TextConversion = iota
+	// TextConversionLines represents Text as []string, one element per line without line breaks.
+	TextConversionLines
+)
+
+// UnsupportedTypeError is returned by FromInterface when a value can not be
+// represented in NestedText.
+type UnsupportedTypeError struct {
+	Path string
+	Type reflect.Type
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	return fmt.Sprintf("ntgo: unsupported type %v at %s", e.Type, displayPath(e.Path))
+}
+
+// ToInterface converts value into a tree of map[string]interface{}, []interface{} and string.
+// Text is joined into a single string.
+func (v *Value) ToInterface() interface{} {
+	return v.ToInterfaceWithTextConversion(TextConversionJoin)
+}
+
+// ToInterfaceWithTextConversion works like ToInterface with Text represented as described by conversion.
+func (v *Value) ToInterfaceWithTextConversion(conversion TextConversion) interface{} {
+	switch v.Type {
+	case ValueTypeString:
+		return v.String
+	case ValueTypeText:
+		if conversion == TextConversionLines {
+			lines := make([]string, len(v.Text))
+			for i, line := range v.Text {
+				removeStringTrailingLineBreaks(&line)
+				// CRLF
+				removeStringTrailingLineBreaks(&line)
+				lines[i] = line
+			}
+			return lines
+		}
+		return v.Text.String()
+	case ValueTypeList:
+		list := make([]interface{}, len(v.List))
+		for i, child := range v.List {
+			list[i] = child.ToInterfaceWithTextConversion(conversion)
+		}
+		return list
+	case ValueTypeDictionary:
+		dict := make(map[string]interface{}, len(v.Dictionary))
+		for key, child := range v.Dictionary {
+			dict[key] = child.ToInterfaceWithTextConversion(conversion)
+		}
+		return dict
+	}
+	return nil
+}
+
+// FromInterface replaces value with the content of i.
+// Strings, slices, arrays and maps with string keys are supported, strings with line breaks become Text.
+func (v *Value) FromInterface(i interface{}) error {
+	return v.FromInterfaceWithTextConversion(i, TextConversionJoin)
+}
+
+// FromInterfaceWithTextConversion works like FromInterface.
+// When conversion is TextConversionLines, []string is converted into Text instead of list.
+func (v *Value) FromInterfaceWithTextConversion(i interface{}, conversion TextConversion) error {
+	return v.fromReflectValue(reflect.ValueOf(i), conversion, "")
+}
+
+func (v *Value) fromReflectValue(ref reflect.Value, conversion TextConversion, path string) error {
+	for ref.IsValid() && (ref.Kind() == reflect.Interface || ref.Kind() == reflect.Ptr) {
+		if ref.IsNil() {
+			return &UnsupportedTypeError{Path: path, Type: ref.Type()}
+		}
+		ref = ref.Elem()
+	}
+
+	if !ref.IsValid() {
+		return &UnsupportedTypeError{Path: path}
+	}
+
+	v.String = ""
+	v.Text = nil
+	v.List = nil
+	v.Dictionary = nil
+	v.keys = nil
+
+	switch ref.Kind() {
+	case reflect.String:
+		str := ref.String()
+		if strings.ContainsAny(str, string([]byte{CR, LF})) {
+			v.Type = ValueTypeText
+			v.Text = splitLines(str)
+		} else {
+			v.Type = ValueTypeString
+			v.String = str
+		}
+	case reflect.Slice, reflect.Array:
+		if conversion == TextConversionLines && ref.Type().Elem().Kind() == reflect.String {
+			v.Type = ValueTypeText
+			v.Text = make(MultilineStrings, ref.Len())
+			for i := 0; i < ref.Len(); i++ {
+				v.Text[i] = ref.Index(i).String()
+				if i < ref.Len()-1 {
+					v.Text[i] += string(LF)
+				}
+			}
+			return nil
+		}
+
+		v.Type = ValueTypeList
+		v.List = make([]*Value, ref.Len())
+		for i := 0; i < ref.Len(); i++ {
+			child := &Value{IndentSize: v.IndentSize, Depth: v.Depth + 1}
+			if err := child.fromReflectValue(ref.Index(i), conversion, appendIndexPath(path, i)); err != nil {
+				return err
+			}
+			v.List[i] = child
+		}
+	case reflect.Map:
+		if ref.Type().Key().Kind() != reflect.String {
+			return &UnsupportedTypeError{Path: path, Type: ref.Type()}
+		}
+
+		keys := make([]string, 0, ref.Len())
+		for _, key := range ref.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+
+		v.Type = ValueTypeDictionary
+		v.Dictionary = make(map[string]*Value, len(keys))
+		for _, key := range keys {
+			child := &Value{IndentSize: v.IndentSize, Depth: v.Depth + 1}
+			element := ref.MapIndex(reflect.ValueOf(key).Convert(ref.Type().Key()))
+			if err := child.fromReflectValue(element, conversion, appendKeyPath(path, key)); err != nil {
+				return err
+			}
+			v.setDictionaryValue(key, child)
+		}
+	default:
+		return &UnsupportedTypeError{Path: path, Type: ref.Type()}
+	}
+
+	return nil
+}
+
+// splitLines splits str into lines keeping their line breaks in the same manner as parsed Text.
+func splitLines(str string) MultilineStrings {
+	lines := MultilineStrings{}
+	begin := 0
+	for i := 0; i < len(str); i++ {
+		switch str[i] {
+		case CR:
+			if i+1 < len(str) && str[i+1] == LF {
+				i++
+			}
+		case LF:
+		default:
+			continue
+		}
+		lines = append(lines, str[begin:i+1])
+		begin = i + 1
+	}
+	return append(lines, str[begin:])
+}
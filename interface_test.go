@@ -0,0 +1,142 @@
+package ntgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToInterface(t *testing.T) {
+	var value *Value
+
+	prepare := func(content string) {
+		value = &Value{}
+		value.Parse([]byte(content))
+	}
+
+	t.Run("nested structure", func(t *testing.T) {
+		prepare(`str: hello
+text:
+  > multi
+  > line
+list:
+  - first
+  -
+    - nested
+dict:
+  key: value`)
+
+		t.Run("should convert into generic values", func(t *testing.T) {
+			expect := map[string]interface{}{
+				"str":  "hello",
+				"text": "multi\nline",
+				"list": []interface{}{"first", []interface{}{"nested"}},
+				"dict": map[string]interface{}{"key": "value"},
+			}
+			assert.Equal(t, expect, value.ToInterface())
+		})
+
+		t.Run("with TextConversionLines", func(t *testing.T) {
+			t.Run("should convert text into string slice without line breaks", func(t *testing.T) {
+				ret := value.ToInterfaceWithTextConversion(TextConversionLines).(map[string]interface{})
+				assert.Equal(t, []string{"multi", "line"}, ret["text"])
+			})
+		})
+	})
+
+	t.Run("text with crlf", func(t *testing.T) {
+		value = &Value{Type: ValueTypeText, Text: MultilineStrings{"line 1\r\n", "line 2"}}
+
+		t.Run("should remove both of line break characters", func(t *testing.T) {
+			assert.Equal(t, []string{"line 1", "line 2"}, value.ToInterfaceWithTextConversion(TextConversionLines))
+		})
+	})
+}
+
+func TestFromInterface(t *testing.T) {
+	var input interface{}
+
+	subject := func(conversion TextConversion) (*Value, error) {
+		value := &Value{}
+		err := value.FromInterfaceWithTextConversion(input, conversion)
+		return value, err
+	}
+
+	t.Run("nested structure", func(t *testing.T) {
+		input = map[string]interface{}{
+			"str":  "hello",
+			"text": "multi\nline",
+			"list": []interface{}{"first", []string{"nested"}},
+			"dict": map[string]string{"key": "value"},
+		}
+
+		t.Run("should build value tree", func(t *testing.T) {
+			value, err := subject(TextConversionJoin)
+			assert.Nil(t, err)
+			assert.Equal(t, ValueTypeDictionary, value.Type)
+			assert.Equal(t, "hello", value.Dictionary["str"].String)
+			assert.Equal(t, ValueTypeText, value.Dictionary["text"].Type)
+			assert.Equal(t, MultilineStrings{"multi\n", "line"}, value.Dictionary["text"].Text)
+			assert.Equal(t, ValueTypeList, value.Dictionary["list"].Type)
+			assert.Equal(t, "nested", value.Dictionary["list"].List[1].List[0].String)
+			assert.Equal(t, "value", value.Dictionary["dict"].Dictionary["key"].String)
+		})
+
+		t.Run("should set depth for stringifying", func(t *testing.T) {
+			value, _ := subject(TextConversionJoin)
+			assert.Equal(t, 2, value.Dictionary["dict"].Dictionary["key"].Depth)
+		})
+
+		t.Run("should be reverted by ToInterface", func(t *testing.T) {
+			value, _ := subject(TextConversionJoin)
+			expect := map[string]interface{}{
+				"str":  "hello",
+				"text": "multi\nline",
+				"list": []interface{}{"first", []interface{}{"nested"}},
+				"dict": map[string]interface{}{"key": "value"},
+			}
+			assert.Equal(t, expect, value.ToInterface())
+		})
+	})
+
+	t.Run("string slice with TextConversionLines", func(t *testing.T) {
+		input = map[string]interface{}{"text": []string{"multi", "line"}}
+
+		t.Run("should convert into text", func(t *testing.T) {
+			value, err := subject(TextConversionLines)
+			assert.Nil(t, err)
+			assert.Equal(t, ValueTypeText, value.Dictionary["text"].Type)
+			assert.Equal(t, MultilineStrings{"multi\n", "line"}, value.Dictionary["text"].Text)
+		})
+	})
+
+	t.Run("unsupported type", func(t *testing.T) {
+		input = map[string]interface{}{"list": []interface{}{"ok", 1}}
+
+		t.Run("should return UnsupportedTypeError with path", func(t *testing.T) {
+			_, err := subject(TextConversionJoin)
+			typeErr, ok := err.(*UnsupportedTypeError)
+			assert.True(t, ok)
+			assert.Equal(t, "list[1]", typeErr.Path)
+			assert.Equal(t, `ntgo: unsupported type int at "list[1]"`, err.Error())
+		})
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		input = map[string]interface{}{"key": nil}
+
+		t.Run("should return UnsupportedTypeError", func(t *testing.T) {
+			_, err := subject(TextConversionJoin)
+			assert.IsType(t, &UnsupportedTypeError{}, err)
+		})
+	})
+}
+
+func TestSplitLines(t *testing.T) {
+	t.Run("should keep line breaks", func(t *testing.T) {
+		assert.Equal(t, MultilineStrings{"a\r\n", "b\n", "c\r", "d"}, splitLines("a\r\nb\nc\rd"))
+	})
+	t.Run("should keep trailing empty line", func(t *testing.T) {
+		assert.Equal(t, MultilineStrings{"a\n", ""}, splitLines("a\n"))
+	})
+}
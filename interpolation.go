@@ -0,0 +1,206 @@
+package ntgo
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// InterpolationError describes a string value that could not be interpolated.
+type InterpolationError struct {
+	KeyPath string
+	Line    int
+	Err     error
+}
+
+func (e *InterpolationError) Error() string {
+	location := displayPath(e.KeyPath)
+	if e.Line > 0 {
+		location = fmt.Sprintf("%s (line %d)", location, e.Line)
+	}
+	return fmt.Sprintf("ntgo: interpolation of %s: %v", location, e.Err)
+}
+
+func (e *InterpolationError) Unwrap() error {
+	return e.Err
+}
+
+// interpolator resolves ${...} in strings and texts of root.
+type interpolator struct {
+	root      *Value
+	lookupEnv func(string) (string, bool)
+
+	paths    map[*Value]string
+	resolved map[*Value]bool
+	// visiting is the chain of values being resolved to detect cycles
+	visiting []*Value
+}
+
+// Interpolate replaces ${name} in strings and text lines of v with the value of name.
+// Name is a path to a string or text in v, e.g. ${database.host} or ${servers[0].port},
+// or an environment variable looked up with lookupEnv when no value exists at the path.
+// os.LookupEnv is used when lookupEnv is nil.
+//
+// ${name:-default} gives default when the value is absent or empty, and $$ is replaced with $.
+// Undefined names, values with line breaks such as texts of multiple lines, and circular references are returned as *InterpolationError.
+func (v *Value) Interpolate(lookupEnv func(string) (string, bool)) error {
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+
+	in := &interpolator{
+		root:      v,
+		lookupEnv: lookupEnv,
+		paths:     map[*Value]string{},
+		resolved:  map[*Value]bool{},
+	}
+	collectValuePaths(v, "", in.paths)
+	return in.walk(v)
+}
+
+func (in *interpolator) walk(value *Value) error {
+	switch value.Type {
+	case ValueTypeString, ValueTypeText:
+		return in.resolve(value)
+	case ValueTypeList:
+		for _, child := range value.List {
+			if err := in.walk(child); err != nil {
+				return err
+			}
+		}
+	case ValueTypeDictionary:
+		for _, key := range value.Keys() {
+			if err := in.walk(value.Dictionary[key]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resolve interpolates value after values it refers to.
+func (in *interpolator) resolve(value *Value) error {
+	if in.resolved[value] {
+		return nil
+	}
+	for i, visiting := range in.visiting {
+		if visiting == value {
+			chain := []string{}
+			for _, v := range append(in.visiting[i:], value) {
+				chain = append(chain, displayPath(in.paths[v]))
+			}
+			return &InterpolationError{
+				KeyPath: in.paths[value],
+				Line:    value.Line,
+				Err:     fmt.Errorf("circular reference %s", strings.Join(chain, " -> ")),
+			}
+		}
+	}
+
+	in.visiting = append(in.visiting, value)
+	defer func() { in.visiting = in.visiting[:len(in.visiting)-1] }()
+
+	var err error
+	if value.Type == ValueTypeString {
+		value.String, err = in.expand(value.String)
+	} else {
+		for i := range value.Text {
+			if value.Text[i], err = in.expand(value.Text[i]); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		if _, ok := err.(*InterpolationError); ok {
+			return err
+		}
+		return &InterpolationError{KeyPath: in.paths[value], Line: value.Line, Err: err}
+	}
+
+	in.resolved[value] = true
+	return nil
+}
+
+// expand replaces ${...} and $$ in str.
+func (in *interpolator) expand(str string) (string, error) {
+	if !strings.Contains(str, "$") {
+		return str, nil
+	}
+
+	result := strings.Builder{}
+	for i := 0; i < len(str); i++ {
+		if str[i] != '$' || i+1 >= len(str) {
+			result.WriteByte(str[i])
+			continue
+		}
+
+		switch str[i+1] {
+		case '$':
+			result.WriteByte('$')
+			i++
+		case '{':
+			end := strings.IndexByte(str[i:], '}')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated ${ in %q", str)
+			}
+			replacement, err := in.lookup(str[i+2 : i+end])
+			if err != nil {
+				return "", err
+			}
+			result.WriteString(replacement)
+			i += end
+		default:
+			result.WriteByte(str[i])
+		}
+	}
+	return result.String(), nil
+}
+
+// lookup returns the value of expression, a name optionally followed by :- and default.
+func (in *interpolator) lookup(expression string) (string, error) {
+	name := expression
+	defaultValue := ""
+	hasDefault := false
+	if i := strings.Index(expression, ":-"); i >= 0 {
+		name = expression[:i]
+		defaultValue = expression[i+2:]
+		hasDefault = true
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("empty name in ${%s}", expression)
+	}
+
+	found := false
+	str := ""
+	if segments, err := splitPath(name); err == nil {
+		if target := lookupPath(in.root, segments); target != nil {
+			if target.Type != ValueTypeString && target.Type != ValueTypeText {
+				return "", fmt.Errorf("%s refers to %s", displayPath(name), target.Type)
+			}
+			if err := in.resolve(target); err != nil {
+				return "", err
+			}
+			found = true
+			str = target.String
+			if target.Type == ValueTypeText {
+				str = target.Text.String()
+			}
+		}
+	}
+	if !found {
+		str, found = in.lookupEnv(name)
+	}
+
+	if hasDefault && str == "" {
+		return defaultValue, nil
+	}
+	if !found {
+		return "", fmt.Errorf("undefined %s", displayPath(name))
+	}
+	if strings.ContainsAny(str, "\r\n") {
+		// strings and lines of texts can not be split
+		return "", fmt.Errorf("%s has line break", displayPath(name))
+	}
+	return str, nil
+}
@@ -0,0 +1,163 @@
+package ntgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterpolate(t *testing.T) {
+	env := map[string]string{"HOME": "/home/nt", "EMPTY": ""}
+	lookupEnv := func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+	subject := func(content string) (*Value, error) {
+		value := &Value{}
+		value.Parse([]byte(content))
+		return value, value.Interpolate(lookupEnv)
+	}
+
+	t.Run("environment variable", func(t *testing.T) {
+		t.Run("should be replaced with its value", func(t *testing.T) {
+			value, err := subject("data: ${HOME}/data")
+			assert.Nil(t, err)
+			assert.Equal(t, "/home/nt/data", value.Dictionary["data"].String)
+		})
+	})
+
+	t.Run("default", func(t *testing.T) {
+		t.Run("should be used when variable is absent or empty", func(t *testing.T) {
+			value, err := subject("a: ${UNDEFINED:-x}\nb: ${EMPTY:-y}\nc: ${HOME:-z}\nd: ${UNDEFINED:-}")
+			assert.Nil(t, err)
+			assert.Equal(t, "x", value.Dictionary["a"].String)
+			assert.Equal(t, "y", value.Dictionary["b"].String)
+			assert.Equal(t, "/home/nt", value.Dictionary["c"].String)
+			assert.Equal(t, "", value.Dictionary["d"].String)
+		})
+	})
+
+	t.Run("reference", func(t *testing.T) {
+		content := `url: http://${database.host}:${database.port}/
+database:
+  host: ${servers[1]}
+  port: 5432
+servers:
+  - a.example.com
+  - b.example.com
+notes:
+  > port is ${database.port}
+  > cost is $$5`
+
+		t.Run("should be replaced with the interpolated value at the path", func(t *testing.T) {
+			value, err := subject(content)
+			assert.Nil(t, err)
+			assert.Equal(t, "http://b.example.com:5432/", value.Dictionary["url"].String)
+			assert.Equal(t, "b.example.com", value.Dictionary["database"].Dictionary["host"].String)
+		})
+
+		t.Run("should be applied to text lines", func(t *testing.T) {
+			value, _ := subject(content)
+			assert.Equal(t, "port is 5432\ncost is $5", value.Dictionary["notes"].Text.String())
+		})
+
+		t.Run("should take precedence over environment variable", func(t *testing.T) {
+			value, err := subject("HOME: /root\npath: ${HOME}")
+			assert.Nil(t, err)
+			assert.Equal(t, "/root", value.Dictionary["path"].String)
+		})
+	})
+
+	t.Run("escaped dollar", func(t *testing.T) {
+		t.Run("should be a literal", func(t *testing.T) {
+			value, err := subject("price: $$${HOME} and $$$${HOME} and $ alone")
+			assert.Nil(t, err)
+			assert.Equal(t, "$/home/nt and $${HOME} and $ alone", value.Dictionary["price"].String)
+		})
+	})
+
+	t.Run("when name is undefined", func(t *testing.T) {
+		t.Run("should return InterpolationError", func(t *testing.T) {
+			_, err := subject("key:\n  nested: ${UNDEFINED}")
+			assert.EqualError(t, err, `ntgo: interpolation of "key.nested" (line 2): undefined "UNDEFINED"`)
+		})
+	})
+
+	t.Run("when references are circular", func(t *testing.T) {
+		t.Run("should return error describing the cycle", func(t *testing.T) {
+			_, err := subject("a: ${b}\nb: ${c}\nc: ${a}")
+			assert.IsType(t, &InterpolationError{}, err)
+			assert.Contains(t, err.Error(), `circular reference "a" -> "b" -> "c" -> "a"`)
+		})
+
+		t.Run("should detect reference to itself", func(t *testing.T) {
+			_, err := subject("a: x${a}")
+			assert.Contains(t, err.Error(), `circular reference "a" -> "a"`)
+		})
+	})
+
+	t.Run("when reference points list", func(t *testing.T) {
+		t.Run("should return error", func(t *testing.T) {
+			_, err := subject("list:\n  - a\nref: ${list}")
+			assert.EqualError(t, err, `ntgo: interpolation of "ref" (line 3): "list" refers to list`)
+		})
+	})
+
+	t.Run("when value has line break", func(t *testing.T) {
+		t.Run("should be inserted if text has single line", func(t *testing.T) {
+			value, err := subject("note:\n  > single\nref: ${note}")
+			assert.Nil(t, err)
+			assert.Equal(t, "single", value.Dictionary["ref"].String)
+		})
+
+		t.Run("should return error for text of multiple lines", func(t *testing.T) {
+			_, err := subject("notes:\n  > first\n  > second\nref: (${notes})")
+			assert.EqualError(t, err, `ntgo: interpolation of "ref" (line 4): "notes" has line break`)
+		})
+
+		t.Run("should return error for text lines as well", func(t *testing.T) {
+			_, err := subject("notes:\n  > first\n  > second\ncopy:\n  > ${notes}")
+			assert.EqualError(t, err, `ntgo: interpolation of "copy" (line 4): "notes" has line break`)
+		})
+
+		t.Run("should return error for environment variable", func(t *testing.T) {
+			env["MULTILINE"] = "a\nb"
+			defer delete(env, "MULTILINE")
+			_, err := subject("key: ${MULTILINE}")
+			assert.EqualError(t, err, `ntgo: interpolation of "key" (line 1): "MULTILINE" has line break`)
+		})
+	})
+
+	t.Run("when ${ is not terminated", func(t *testing.T) {
+		t.Run("should return error", func(t *testing.T) {
+			_, err := subject("a: ${HOME")
+			assert.IsType(t, &InterpolationError{}, err)
+		})
+	})
+}
+
+func TestMarshallerInterpolate(t *testing.T) {
+	type Config struct {
+		Home string `nt:"home"`
+		Data string `nt:"data"`
+	}
+
+	lookupEnv := func(key string) (string, bool) { return "/home/nt", key == "HOME" }
+
+	t.Run("when Interpolate is enabled", func(t *testing.T) {
+		t.Run("should interpolate before decoding", func(t *testing.T) {
+			c := &Config{}
+			m := &Marshaller{Interpolate: true, LookupEnv: lookupEnv}
+			assert.Nil(t, m.Marshal("home: ${HOME}\ndata: ${home}/data", c))
+			assert.Equal(t, "/home/nt/data", c.Data)
+		})
+	})
+
+	t.Run("when Interpolate is disabled", func(t *testing.T) {
+		t.Run("should keep strings as they are", func(t *testing.T) {
+			c := &Config{}
+			assert.Nil(t, Marshal("home: ${HOME}", c))
+			assert.Equal(t, "${HOME}", c.Home)
+		})
+	})
+}
@@ -0,0 +1,193 @@
+package ntgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var (
+	UnexpectedJSONTokenError = errors.New("ntgo: unexpected JSON token")
+)
+
+// MarshalJSON implements json.Marshaler.
+// Strings and Text become JSON strings, lists become arrays and dictionaries become objects keeping key order.
+// Value with unknown type becomes null.
+func (v *Value) MarshalJSON() ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	if err := v.writeJSON(buffer); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func (v *Value) writeJSON(buffer *bytes.Buffer) error {
+	switch v.Type {
+	case ValueTypeString:
+		return writeJSONString(buffer, v.String)
+	case ValueTypeText:
+		return writeJSONString(buffer, v.Text.String())
+	case ValueTypeList:
+		buffer.WriteByte('[')
+		for i, child := range v.List {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+			if err := child.writeJSON(buffer); err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte(']')
+	case ValueTypeDictionary:
+		buffer.WriteByte('{')
+		for i, key := range v.Keys() {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+			if err := writeJSONString(buffer, key); err != nil {
+				return err
+			}
+			buffer.WriteByte(':')
+			if err := v.Dictionary[key].writeJSON(buffer); err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte('}')
+	default:
+		buffer.WriteString("null")
+	}
+	return nil
+}
+
+func writeJSONString(buffer *bytes.Buffer, str string) error {
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(str); err != nil {
+		return err
+	}
+	// Encode appends line break
+	buffer.Truncate(buffer.Len() - 1)
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+// Since NestedText only has strings, JSON scalars are converted as below;
+//
+//	string: string, or Text when it contains line breaks
+//	number: string with the literal representation in JSON, e.g. 1.50 becomes "1.50"
+//	boolean: "true" or "false"
+//	null: empty string
+//
+// Object keys keep order of appearance.
+func (v *Value) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	if err := v.readJSON(decoder); err != nil {
+		return err
+	}
+
+	if _, err := decoder.Token(); err != io.EOF {
+		return UnexpectedJSONTokenError
+	}
+
+	return nil
+}
+
+func (v *Value) readJSON(decoder *json.Decoder) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	v.String = ""
+	v.Text = nil
+	v.List = nil
+	v.Dictionary = nil
+	v.keys = nil
+
+	switch t := token.(type) {
+	case json.Delim:
+		switch t {
+		case '[':
+			v.Type = ValueTypeList
+			v.List = []*Value{}
+			for decoder.More() {
+				child := &Value{IndentSize: v.IndentSize, Depth: v.Depth + 1}
+				if err := child.readJSON(decoder); err != nil {
+					return err
+				}
+				v.List = append(v.List, child)
+			}
+		case '{':
+			v.Type = ValueTypeDictionary
+			v.Dictionary = map[string]*Value{}
+			for decoder.More() {
+				keyToken, err := decoder.Token()
+				if err != nil {
+					return err
+				}
+				key, ok := keyToken.(string)
+				if !ok {
+					return UnexpectedJSONTokenError
+				}
+				if strings.ContainsAny(key, "\r\n") {
+					return fmt.Errorf("%w: %q", DictionaryKeyWithLineBreakError, key)
+				}
+				child := &Value{IndentSize: v.IndentSize, Depth: v.Depth + 1}
+				if err := child.readJSON(decoder); err != nil {
+					return err
+				}
+				v.setDictionaryValue(key, child)
+			}
+		default:
+			return UnexpectedJSONTokenError
+		}
+		// closing delimiter
+		if _, err := decoder.Token(); err != nil {
+			return err
+		}
+	case string:
+		v.FromInterface(t)
+	case json.Number:
+		v.Type = ValueTypeString
+		v.String = t.String()
+	case bool:
+		v.Type = ValueTypeString
+		v.String = fmt.Sprintf("%t", t)
+	case nil:
+		v.Type = ValueTypeString
+	default:
+		return UnexpectedJSONTokenError
+	}
+
+	return nil
+}
+
+// NestedTextToJSON converts NestedText document into JSON.
+func NestedTextToJSON(content []byte) ([]byte, error) {
+	value := &Value{}
+	if err := value.Parse(content); err != nil {
+		return nil, err
+	}
+	return value.MarshalJSON()
+}
+
+// JSONToNestedText converts JSON document into NestedText.
+// Conversion of JSON scalars follows UnmarshalJSON of Value.
+// Empty arrays and objects can not be distinguished from empty string in NestedText,
+// they are written as empty values.
+// Root level of JSON must be either of an array, an object or a string with line breaks.
+func JSONToNestedText(content []byte) ([]byte, error) {
+	value := &Value{}
+	if err := value.UnmarshalJSON(content); err != nil {
+		return nil, err
+	}
+	if value.Type == ValueTypeString {
+		return nil, RootStringError
+	}
+	return []byte(value.ToNestedText()), nil
+}
@@ -0,0 +1,173 @@
+package ntgo
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	var content string
+
+	subject := func() ([]byte, error) {
+		value := &Value{}
+		value.Parse([]byte(content))
+		return value.MarshalJSON()
+	}
+
+	t.Run("nested structure", func(t *testing.T) {
+		content = `z: last letter
+a:
+  > multi
+  > line
+list:
+  - <first>
+  -
+    key: "value"`
+
+		t.Run("should keep key order", func(t *testing.T) {
+			ret, err := subject()
+			assert.Nil(t, err)
+			assert.Equal(t, `{"z":"last letter","a":"multi\nline","list":["<first>",{"key":"\"value\""}]}`, string(ret))
+		})
+	})
+
+	t.Run("dictionary constructed manually", func(t *testing.T) {
+		value := &Value{
+			Type: ValueTypeDictionary,
+			Dictionary: map[string]*Value{
+				"b": &Value{Type: ValueTypeString, String: "2"},
+				"a": &Value{Type: ValueTypeString, String: "1"},
+			},
+		}
+
+		t.Run("should sort keys", func(t *testing.T) {
+			ret, err := value.MarshalJSON()
+			assert.Nil(t, err)
+			assert.Equal(t, `{"a":"1","b":"2"}`, string(ret))
+		})
+	})
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	var content string
+
+	subject := func() (*Value, error) {
+		value := &Value{}
+		err := value.UnmarshalJSON([]byte(content))
+		return value, err
+	}
+
+	t.Run("scalars", func(t *testing.T) {
+		content = `{"str":"s","multi":"a\nb","num":1.50,"yes":true,"nothing":null}`
+
+		t.Run("should convert into strings", func(t *testing.T) {
+			value, err := subject()
+			assert.Nil(t, err)
+			assert.Equal(t, []string{"str", "multi", "num", "yes", "nothing"}, value.Keys())
+			assert.Equal(t, "s", value.Dictionary["str"].String)
+			assert.Equal(t, ValueTypeText, value.Dictionary["multi"].Type)
+			assert.Equal(t, MultilineStrings{"a\n", "b"}, value.Dictionary["multi"].Text)
+			assert.Equal(t, "1.50", value.Dictionary["num"].String)
+			assert.Equal(t, "true", value.Dictionary["yes"].String)
+			assert.Equal(t, ValueTypeString, value.Dictionary["nothing"].Type)
+			assert.Equal(t, "", value.Dictionary["nothing"].String)
+		})
+	})
+
+	t.Run("nested structure", func(t *testing.T) {
+		content = `[{"key":["a","b"]}]`
+
+		t.Run("should build value tree with depth", func(t *testing.T) {
+			value, err := subject()
+			assert.Nil(t, err)
+			child := value.List[0].Dictionary["key"]
+			assert.Equal(t, ValueTypeList, child.Type)
+			assert.Equal(t, "b", child.List[1].String)
+			assert.Equal(t, 3, child.List[1].Depth)
+		})
+	})
+
+	t.Run("trailing data", func(t *testing.T) {
+		content = `{} {}`
+
+		t.Run("should return error", func(t *testing.T) {
+			_, err := subject()
+			assert.NotNil(t, err)
+		})
+	})
+
+	t.Run("via json.Unmarshal", func(t *testing.T) {
+		t.Run("should be called as json.Unmarshaler", func(t *testing.T) {
+			value := &Value{}
+			err := json.Unmarshal([]byte(`["a"]`), value)
+			assert.Nil(t, err)
+			assert.Equal(t, "a", value.List[0].String)
+		})
+	})
+}
+
+func TestNestedTextToJSON(t *testing.T) {
+	t.Run("should convert document", func(t *testing.T) {
+		ret, err := NestedTextToJSON([]byte("key: value\nlist:\n  - a"))
+		assert.Nil(t, err)
+		assert.Equal(t, `{"key":"value","list":["a"]}`, string(ret))
+	})
+
+	t.Run("should return parse error", func(t *testing.T) {
+		_, err := NestedTextToJSON([]byte("string"))
+		assert.Equal(t, RootStringError, err)
+	})
+}
+
+func TestJSONToNestedText(t *testing.T) {
+	t.Run("should convert document keeping key order", func(t *testing.T) {
+		ret, err := JSONToNestedText([]byte(`{"name":"smith","age":42,"profile":{"address":"Japan\nTokyo","tags":["a","b"]}}`))
+		assert.Nil(t, err)
+		assert.Equal(t, `name: smith
+age: 42
+profile:
+  address:
+    > Japan
+    > Tokyo
+  tags:
+    - a
+    - b
+`, string(ret))
+	})
+
+	t.Run("should reject root string", func(t *testing.T) {
+		_, err := JSONToNestedText([]byte(`"str"`))
+		assert.Equal(t, RootStringError, err)
+	})
+
+	t.Run("should write empty list and dictionary as empty values", func(t *testing.T) {
+		ret, err := JSONToNestedText([]byte(`{"list":[],"dict":{},"nested":[[],{}],"last":"x"}`))
+		assert.Nil(t, err)
+		assert.Equal(t, "list:\ndict:\nnested:\n  -\n  -\nlast: x\n", string(ret))
+	})
+
+	t.Run("should reject keys with line break", func(t *testing.T) {
+		_, err := JSONToNestedText([]byte(`{"a\nb":"c"}`))
+		assert.True(t, errors.Is(err, DictionaryKeyWithLineBreakError))
+	})
+}
+
+func TestKeys(t *testing.T) {
+	t.Run("should return keys in order of appearance", func(t *testing.T) {
+		value := &Value{}
+		value.Parse([]byte("b: 1\na: 2\nc: 3"))
+		assert.Equal(t, []string{"b", "a", "c"}, value.Keys())
+	})
+
+	t.Run("should place keys added directly at the end", func(t *testing.T) {
+		value := &Value{}
+		value.Parse([]byte("b: 1\na: 2"))
+		value.Dictionary["d"] = &Value{Type: ValueTypeString}
+		value.Dictionary["c"] = &Value{Type: ValueTypeString}
+		delete(value.Dictionary, "a")
+		assert.Equal(t, []string{"b", "c", "d"}, value.Keys())
+	})
+}
@@ -0,0 +1,332 @@
+package ntgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// JSONSchemaDraft is the dialect of documents generated by JSONSchema.
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// jsonSchema is a subset of JSON Schema keywords, fields are written in order of declaration.
+type jsonSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Ref                  string                 `json:"$ref,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Const                *string                `json:"const,omitempty"`
+	Default              *string                `json:"default,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	MinItems             *int                   `json:"minItems,omitempty"`
+	MaxItems             *int                   `json:"maxItems,omitempty"`
+	Properties           *jsonSchemaProperties  `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties interface{}            `json:"additionalProperties,omitempty"`
+	AnyOf                []*jsonSchema          `json:"anyOf,omitempty"`
+	Defs                 map[string]*jsonSchema `json:"$defs,omitempty"`
+}
+
+// jsonSchemaProperties keeps properties in order of struct fields.
+type jsonSchemaProperties struct {
+	keys    []string
+	schemas map[string]*jsonSchema
+}
+
+func (p *jsonSchemaProperties) MarshalJSON() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	for i, key := range p.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		encodedKey, _ := json.Marshal(key)
+		buf.Write(encodedKey)
+		buf.WriteByte(':')
+		encoded, err := json.Marshal(p.schemas[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(encoded)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// patterns of scalars also accept empty value, which is decoded as zero value
+var (
+	floatPattern   = `^([+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)([eE][+-]?[0-9]+)?|[+-]?([Ii]nf|INF)(inity)?|NaN)?$`
+	complexPattern = `^(` + complexBody + `|\(` + complexBody + `\))?$`
+	boolValues     = []string{"1", "t", "T", "TRUE", "true", "True", "0", "f", "F", "FALSE", "false", "False", ""}
+)
+
+const (
+	finiteFloat = `([0-9]+(\.[0-9]*)?|\.[0-9]+)([eE][+-]?[0-9]+)?`
+	complexBody = `[+-]?` + finiteFloat + `([+-]` + finiteFloat + `i)?|[+-]?` + finiteFloat + `i`
+)
+
+// integerPattern matches integers of bits in decimal, leading zeros are allowed as they are parsed.
+func integerPattern(bits int) string {
+	max := uint64(1)<<uint(bits-1) - 1
+	return fmt.Sprintf("^(\\+?0*(%s|0)|-0*(%s|0))?$", rangePattern(max), rangePattern(max+1))
+}
+
+// unsignedPattern matches unsigned integers of bits in decimal.
+func unsignedPattern(bits int) string {
+	max := ^uint64(0) >> uint(64-bits)
+	return fmt.Sprintf("^(0*(%s|0))?$", rangePattern(max))
+}
+
+// rangePattern matches decimal numbers from 0 to max without leading zeros.
+func rangePattern(max uint64) string {
+	digits := strconv.FormatUint(max, 10)
+	alternatives := []string{}
+	if len(digits) > 1 {
+		alternatives = append(alternatives, fmt.Sprintf("[1-9][0-9]{0,%d}", len(digits)-2))
+	}
+	// same number of digits, less than max at i-th digit
+	for i := 0; i < len(digits); i++ {
+		lowest := byte('0')
+		if i == 0 && len(digits) > 1 {
+			lowest = '1'
+		}
+		if digits[i] <= lowest {
+			continue
+		}
+		alternative := digits[:i] + string(lowest)
+		if digits[i]-1 > lowest {
+			alternative = fmt.Sprintf("%s[%c-%c]", digits[:i], lowest, digits[i]-1)
+		}
+		switch rest := len(digits) - i - 1; {
+		case rest == 1:
+			alternative += "[0-9]"
+		case rest > 1:
+			alternative += fmt.Sprintf("[0-9]{%d}", rest)
+		}
+		alternatives = append(alternatives, alternative)
+	}
+	alternatives = append(alternatives, digits)
+	return strings.Join(alternatives, "|")
+}
+
+// jsonSchemaBuilder collects definitions of struct types.
+type jsonSchemaBuilder struct {
+	m    *Marshaller
+	root reflect.Type
+	defs map[string]*jsonSchema
+	refs map[reflect.Type]string
+}
+
+// JSONSchema returns JSON Schema describing documents decoded into v, as they are converted into JSON by NestedTextToJSON.
+// Since NestedText only has strings, numbers and booleans are described as strings with pattern.
+// Empty value is accepted wherever it is decoded as zero value or nil.
+// Integers are limited to the range of their types, while floating point numbers out of range are not detected.
+func JSONSchema(v interface{}) ([]byte, error) {
+	return (&Marshaller{}).JSONSchema(v)
+}
+
+// JSONSchema works like JSONSchema function with NamingStrategy of m.
+// Unknown keys are disallowed by the schema when DisallowUnknownFields is enabled.
+func (m *Marshaller) JSONSchema(v interface{}) ([]byte, error) {
+	typ := reflect.TypeOf(v)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil {
+		return nil, &UnsupportedTypeError{}
+	}
+
+	b := &jsonSchemaBuilder{m: m, root: typ, defs: map[string]*jsonSchema{}, refs: map[reflect.Type]string{}}
+	// document can not be empty
+	schema, err := b.buildType(typ, "")
+	if err != nil {
+		return nil, err
+	}
+	if schema.Ref != "" && typ.Kind() == reflect.Struct {
+		// root struct is written in place
+		name := b.refs[typ]
+		schema = b.defs[name]
+		delete(b.defs, name)
+	}
+	schema.Schema = JSONSchemaDraft
+	if len(b.defs) > 0 {
+		schema.Defs = b.defs
+	}
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// build returns schema of values decoded into typ, including empty value when the decoder accepts it.
+func (b *jsonSchemaBuilder) build(typ reflect.Type, path string) (*jsonSchema, error) {
+	schema, err := b.buildType(typ, path)
+	if err != nil {
+		return nil, err
+	}
+	// empty value is decoded as zero value or nil unless typ decodes itself
+	if !hasCustomUnmarshaler(typ) && !schema.acceptsEmpty() {
+		empty := ""
+		schema = &jsonSchema{AnyOf: []*jsonSchema{schema, {Const: &empty}}}
+	}
+	return schema, nil
+}
+
+func (b *jsonSchemaBuilder) buildType(typ reflect.Type, path string) (*jsonSchema, error) {
+	switch {
+	case reflect.PtrTo(typ).Implements(nestedTextUnmarshalerType):
+		// representation is decided by the type itself
+		return &jsonSchema{}, nil
+	case reflect.PtrTo(typ).Implements(textUnmarshalerType):
+		return &jsonSchema{Type: "string"}, nil
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		return &jsonSchema{Type: "string"}, nil
+	case reflect.Complex64, reflect.Complex128:
+		return &jsonSchema{Type: "string", Pattern: complexPattern}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &jsonSchema{Type: "string", Pattern: integerPattern(typ.Bits())}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &jsonSchema{Type: "string", Pattern: unsignedPattern(typ.Bits())}, nil
+	case reflect.Float32, reflect.Float64:
+		return &jsonSchema{Type: "string", Pattern: floatPattern}, nil
+	case reflect.Bool:
+		return &jsonSchema{Type: "string", Enum: boolValues}, nil
+	case reflect.Interface:
+		return &jsonSchema{}, nil
+	case reflect.Ptr:
+		return b.build(typ.Elem(), path)
+	case reflect.Slice, reflect.Array:
+		elem := typ.Elem()
+		if elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		itemType := typ.Elem()
+		if itemType.Kind() == reflect.Ptr && hasCustomUnmarshaler(elem) {
+			// elements decoding themselves are not nil for empty value
+			itemType = elem
+		}
+		items, err := b.build(itemType, appendIndexPath(path, 0))
+		if err != nil {
+			return nil, err
+		}
+		schema := &jsonSchema{Type: "array", Items: items}
+		if typ.Kind() == reflect.Array {
+			length := typ.Len()
+			schema.MinItems = &length
+			schema.MaxItems = &length
+		}
+		if acceptsSingleString(elem) && (typ.Kind() == reflect.Slice || typ.Len() == 1) {
+			// a string or text is decoded as a single element, texts of multilinestrings are strings in JSON as well
+			return &jsonSchema{AnyOf: []*jsonSchema{schema, items}}, nil
+		}
+		return schema, nil
+	case reflect.Map:
+		values, err := b.build(typ.Elem(), appendKeyPath(path, "*"))
+		if err != nil {
+			return nil, err
+		}
+		return &jsonSchema{Type: "object", AdditionalProperties: values}, nil
+	case reflect.Struct:
+		return b.buildStruct(typ, path)
+	}
+	return nil, &UnsupportedTypeError{Path: path, Type: typ}
+}
+
+// buildStruct defines typ in $defs and returns the reference to it.
+func (b *jsonSchemaBuilder) buildStruct(typ reflect.Type, path string) (*jsonSchema, error) {
+	if typ == b.root && path != "" {
+		return &jsonSchema{Ref: "#"}, nil
+	}
+	if name, ok := b.refs[typ]; ok {
+		return &jsonSchema{Ref: "#/$defs/" + name}, nil
+	}
+
+	base := typ.Name()
+	if base == "" {
+		base = "Struct"
+	}
+	name := base
+	for i := 2; b.defs[name] != nil; i++ {
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+	schema := &jsonSchema{
+		Type:       "object",
+		Properties: &jsonSchemaProperties{schemas: map[string]*jsonSchema{}},
+	}
+	b.defs[name] = schema
+	b.refs[typ] = name
+
+	fields, err := cachedTypeFields(typ, b.m.NamingStrategy)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range fields.list {
+		property, err := b.build(f.typ, appendKeyPath(path, f.key))
+		if err != nil {
+			return nil, err
+		}
+
+		if f.hasDefault {
+			defaultValue := f.defaultValue
+			property.Default = &defaultValue
+		}
+		if (f.tagFlag & MarshallerTagFlagRequired) == MarshallerTagFlagRequired {
+			schema.Required = append(schema.Required, f.key)
+		}
+
+		schema.Properties.keys = append(schema.Properties.keys, f.key)
+		schema.Properties.schemas[f.key] = property
+	}
+
+	if b.m.DisallowUnknownFields {
+		schema.AdditionalProperties = false
+	}
+	return &jsonSchema{Ref: "#/$defs/" + name}, nil
+}
+
+// acceptsSingleString reports whether slices of elem decode a string as a single element.
+func acceptsSingleString(elem reflect.Type) bool {
+	if hasCustomUnmarshaler(elem) {
+		return false
+	}
+	switch elem.Kind() {
+	case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128, reflect.Bool:
+		return true
+	}
+	return false
+}
+
+// acceptsEmpty reports whether empty string is valid for s.
+func (s *jsonSchema) acceptsEmpty() bool {
+	if len(s.AnyOf) > 0 {
+		for _, schema := range s.AnyOf {
+			if schema.acceptsEmpty() {
+				return true
+			}
+		}
+		return false
+	}
+	if s.Ref != "" || (s.Type != "" && s.Type != "string") || (s.Const != nil && *s.Const != "") {
+		return false
+	}
+	if s.Pattern != "" && !regexp.MustCompile(s.Pattern).MatchString("") {
+		return false
+	}
+	if len(s.Enum) > 0 {
+		for _, value := range s.Enum {
+			if value == "" {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,248 @@
+package ntgo
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type JSONSchemaServer struct {
+	Host string `nt:"host,required"`
+	Port uint16 `nt:"port,default=80"`
+}
+
+type JSONSchemaConfig struct {
+	Name     string                       `nt:"name"`
+	Address  []string                     `nt:"address,multilinestrings"`
+	Tags     []string                     `nt:"tags,omitempty"`
+	Server   *JSONSchemaServer            `nt:"server"`
+	Backends map[string]*JSONSchemaServer `nt:"backends,omitempty"`
+	Point    [2]float64                   `nt:"point"`
+	Enabled  bool                         `nt:"enabled"`
+	Updated  time.Time                    `nt:"updated"`
+	Extra    interface{}                  `nt:"extra"`
+	Parent   *JSONSchemaConfig            `nt:"parent,omitempty"`
+}
+
+type JSONSchemaValues struct {
+	Int      int               `nt:"int"`
+	Uint     uint8             `nt:"uint"`
+	Float    float64           `nt:"float"`
+	Complex  complex128        `nt:"complex"`
+	Bool     bool              `nt:"bool"`
+	Pointer  *int              `nt:"pointer,omitempty"`
+	Server   *JSONSchemaServer `nt:"server"`
+	Nested   JSONSchemaServer  `nt:"nested"`
+	Ports    []int             `nt:"ports,omitempty"`
+	Names    []string          `nt:"names"`
+	Single   [1]bool           `nt:"single"`
+	Point    [2]float64        `nt:"point"`
+	Backends map[string]int    `nt:"backends"`
+}
+
+// jsonSchemaAcceptsString reports whether string value is valid for schema generated by JSONSchema.
+func jsonSchemaAcceptsString(schema map[string]interface{}, value string) bool {
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		for _, s := range anyOf {
+			if jsonSchemaAcceptsString(s.(map[string]interface{}), value) {
+				return true
+			}
+		}
+		return false
+	}
+	if _, ok := schema["$ref"]; ok {
+		return false
+	}
+	if typ, ok := schema["type"]; ok && typ != "string" {
+		return false
+	}
+	if constant, ok := schema["const"]; ok && constant != value {
+		return false
+	}
+	if pattern, ok := schema["pattern"].(string); ok && !regexp.MustCompile(pattern).MatchString(value) {
+		return false
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		for _, e := range enum {
+			if e == value {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func TestJSONSchema(t *testing.T) {
+	generate := func(t *testing.T, m *Marshaller, v interface{}) map[string]interface{} {
+		data, err := m.JSONSchema(v)
+		assert.Nil(t, err)
+		schema := map[string]interface{}{}
+		assert.Nil(t, json.Unmarshal(data, &schema))
+		return schema
+	}
+	orEmpty := func(schema map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{"anyOf": []interface{}{schema, map[string]interface{}{"const": ""}}}
+	}
+
+	t.Run("struct", func(t *testing.T) {
+		schema := generate(t, &Marshaller{}, &JSONSchemaConfig{})
+		properties := schema["properties"].(map[string]interface{})
+
+		t.Run("should be object with properties in place", func(t *testing.T) {
+			assert.Equal(t, JSONSchemaDraft, schema["$schema"])
+			assert.Equal(t, "object", schema["type"])
+			assert.Equal(t, map[string]interface{}{"type": "string"}, properties["name"])
+		})
+
+		t.Run("multiline strings should be a string or an array", func(t *testing.T) {
+			assert.Equal(t, map[string]interface{}{"anyOf": []interface{}{
+				map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+				map[string]interface{}{"type": "string"},
+			}}, properties["address"])
+		})
+
+		t.Run("slice of strings should also accept a string as a single element", func(t *testing.T) {
+			assert.Equal(t, properties["address"], properties["tags"])
+		})
+
+		t.Run("slice of numbers should also accept a number as a single element", func(t *testing.T) {
+			schema := generate(t, &Marshaller{}, &JSONSchemaValues{})
+			ports := schema["properties"].(map[string]interface{})["ports"].(map[string]interface{})["anyOf"].([]interface{})
+			assert.Equal(t, "array", ports[0].(map[string]interface{})["type"])
+			assert.Equal(t, integerPattern(strconv.IntSize), ports[1].(map[string]interface{})["pattern"])
+		})
+
+		t.Run("pointer should also accept empty value", func(t *testing.T) {
+			assert.Equal(t, orEmpty(map[string]interface{}{"$ref": "#/$defs/JSONSchemaServer"}), properties["server"])
+		})
+
+		t.Run("map should be an object of values", func(t *testing.T) {
+			assert.Equal(t, orEmpty(map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": orEmpty(map[string]interface{}{"$ref": "#/$defs/JSONSchemaServer"}),
+			}), properties["backends"])
+		})
+
+		t.Run("array should have fixed length", func(t *testing.T) {
+			point := properties["point"].(map[string]interface{})["anyOf"].([]interface{})[0].(map[string]interface{})
+			assert.Equal(t, "array", point["type"])
+			assert.Equal(t, 2.0, point["minItems"])
+			assert.Equal(t, 2.0, point["maxItems"])
+		})
+
+		t.Run("scalars should be strings", func(t *testing.T) {
+			assert.Equal(t, "string", properties["enabled"].(map[string]interface{})["type"])
+			assert.Contains(t, properties["enabled"].(map[string]interface{})["enum"], "true")
+			assert.Contains(t, properties["enabled"].(map[string]interface{})["enum"], "")
+		})
+
+		t.Run("TextUnmarshaler should be a string", func(t *testing.T) {
+			assert.Equal(t, map[string]interface{}{"type": "string"}, properties["updated"])
+		})
+
+		t.Run("interface should accept anything", func(t *testing.T) {
+			assert.Equal(t, map[string]interface{}{}, properties["extra"])
+		})
+
+		t.Run("recursive reference to root should refer to the document", func(t *testing.T) {
+			assert.Equal(t, orEmpty(map[string]interface{}{"$ref": "#"}), properties["parent"])
+		})
+
+		t.Run("nested struct should be defined with required and default", func(t *testing.T) {
+			server := schema["$defs"].(map[string]interface{})["JSONSchemaServer"].(map[string]interface{})
+			assert.Equal(t, []interface{}{"host"}, server["required"])
+			port := server["properties"].(map[string]interface{})["port"].(map[string]interface{})
+			assert.Equal(t, "80", port["default"])
+			assert.Equal(t, unsignedPattern(16), port["pattern"])
+		})
+	})
+
+	t.Run("properties", func(t *testing.T) {
+		t.Run("should be written in order of fields", func(t *testing.T) {
+			data, err := JSONSchema(JSONSchemaServer{})
+			assert.Nil(t, err)
+			assert.Equal(t, `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "properties": {
+    "host": {
+      "type": "string"
+    },
+    "port": {
+      "type": "string",
+      "pattern": "^(0*([1-9][0-9]{0,3}|[1-5][0-9]{4}|6[0-4][0-9]{3}|65[0-4][0-9]{2}|655[0-2][0-9]|6553[0-4]|65535|0))?$",
+      "default": "80"
+    }
+  },
+  "required": [
+    "host"
+  ]
+}`, string(data))
+		})
+	})
+
+	t.Run("anonymous structs", func(t *testing.T) {
+		t.Run("should be defined with numbered names", func(t *testing.T) {
+			type Anonymous struct {
+				A struct{ X string } `nt:"a"`
+				B struct{ Y string } `nt:"b"`
+				C struct{ Z string } `nt:"c"`
+			}
+			schema := generate(t, &Marshaller{}, &Anonymous{})
+			defs := schema["$defs"].(map[string]interface{})
+			assert.Contains(t, defs, "Struct")
+			assert.Contains(t, defs, "Struct_2")
+			assert.Contains(t, defs, "Struct_3")
+			assert.Equal(t, orEmpty(map[string]interface{}{"$ref": "#/$defs/Struct_2"}), schema["properties"].(map[string]interface{})["b"])
+		})
+	})
+
+	t.Run("with Marshaller options", func(t *testing.T) {
+		schema := generate(t, &Marshaller{NamingStrategy: SnakeCase, DisallowUnknownFields: true}, &UntaggedOfficer{})
+
+		t.Run("keys should follow NamingStrategy", func(t *testing.T) {
+			assert.Contains(t, schema["properties"], "additional_roles")
+		})
+
+		t.Run("unknown keys should be disallowed", func(t *testing.T) {
+			assert.Equal(t, false, schema["additionalProperties"])
+		})
+	})
+
+	t.Run("values accepted by schema", func(t *testing.T) {
+		t.Run("should be decoded", func(t *testing.T) {
+			schema := generate(t, &Marshaller{}, &JSONSchemaValues{})
+			properties := schema["properties"].(map[string]interface{})
+			examples := []string{
+				"", "0", "-0", "007", "42", "+7", "-7", "255", "256", "300", "1.5", ".5", "1.", "-2e3", "1e", "Inf", "-Infinity", "NaN", "nan",
+				"1+2i", "(1-2.5i)", "3i", "(3i", "i", "0x10", "1_000", " 1", "true", "False", "t", "yes", "value",
+			}
+			for key, property := range properties {
+				accepted := 0
+				for _, example := range examples {
+					if !jsonSchemaAcceptsString(property.(map[string]interface{}), example) {
+						continue
+					}
+					accepted++
+					err := Marshal(key+": "+example, &JSONSchemaValues{})
+					assert.Nil(t, err, "%s: %q", key, example)
+				}
+				// empty value at least
+				assert.NotZero(t, accepted, key)
+			}
+		})
+	})
+
+	t.Run("when unsupported type is given", func(t *testing.T) {
+		t.Run("should return UnsupportedTypeError", func(t *testing.T) {
+			_, err := JSONSchema(&UnsupportedStruct{})
+			assert.IsType(t, &UnsupportedTypeError{}, err)
+			assert.Equal(t, "f", err.(*UnsupportedTypeError).Path)
+		})
+	})
+}
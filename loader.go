@@ -0,0 +1,235 @@
+package ntgo
+
+import (
+	"context"
+	"crypto/sha256"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ReloadEvent describes a configuration replaced by Loader.
+type ReloadEvent struct {
+	// Old and New are values returned by newValue of Loader
+	Old interface{}
+	New interface{}
+	// Changes are differences between the documents, see Diff
+	Changes []*Change
+}
+
+// Loader decodes a NestedText file and reloads it when the file changes.
+// Changes are detected by polling modification times and sizes of files, and confirmed by hashes of their contents.
+type Loader struct {
+	// Marshaller decodes documents, Marshaller with default options is used when it is nil.
+	// Files included with Include option are watched as well.
+	Marshaller *Marshaller
+
+	fsys     fs.FS
+	name     string
+	newValue func() interface{}
+
+	// reloading serializes loads and guards document and files
+	reloading sync.Mutex
+	document  *Value
+	files     map[string]fileStamp
+
+	mu             sync.RWMutex
+	current        interface{}
+	callbacks      []func(*ReloadEvent)
+	errorCallbacks []func(error)
+}
+
+// fileStamp is the state of a file read by Loader.
+type fileStamp struct {
+	exists  bool
+	modTime time.Time
+	size    int64
+	sum     [sha256.Size]byte
+}
+
+// NewLoader returns Loader decoding name in fsys into values returned by newValue, which must be pointers.
+func NewLoader(fsys fs.FS, name string, newValue func() interface{}) *Loader {
+	return &Loader{fsys: fsys, name: name, newValue: newValue}
+}
+
+// NewFileLoader returns Loader decoding the file at path of the OS.
+// Include directives are resolved in the directory of the file.
+func NewFileLoader(path string, newValue func() interface{}) *Loader {
+	return NewLoader(os.DirFS(filepath.Dir(path)), filepath.Base(path), newValue)
+}
+
+// OnReload registers callback invoked with the old and new values after the file changes.
+// Callbacks are not invoked by Load, or when the file changes without differences in the document.
+func (l *Loader) OnReload(callback func(event *ReloadEvent)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.callbacks = append(l.callbacks, callback)
+}
+
+// OnError registers callback invoked with errors of reloads by Watch.
+func (l *Loader) OnError(callback func(err error)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.errorCallbacks = append(l.errorCallbacks, callback)
+}
+
+// Current returns the last value decoded successfully, nil before Load succeeds.
+func (l *Loader) Current() interface{} {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.current
+}
+
+// Load reads and decodes the file regardless of changes.
+// The current value is kept when the file cannot be read or decoded.
+func (l *Loader) Load() error {
+	l.reloading.Lock()
+	defer l.reloading.Unlock()
+	_, err := l.load()
+	return err
+}
+
+// Check reloads the file when it or included files have changed since the last load, and reports whether the value is replaced.
+// Reload callbacks are invoked before Check returns, after the reload is finished so that they may call Load or Check.
+// The current value is kept when the changed file cannot be decoded, and the same contents are not reported again.
+func (l *Loader) Check() (bool, error) {
+	event, err := l.check()
+	if event == nil {
+		return false, err
+	}
+
+	if event.Old != nil {
+		l.mu.RLock()
+		callbacks := l.callbacks
+		l.mu.RUnlock()
+		for _, callback := range callbacks {
+			callback(event)
+		}
+	}
+	return true, nil
+}
+
+// check reloads the changed file, and returns the event when the value is replaced.
+func (l *Loader) check() (*ReloadEvent, error) {
+	l.reloading.Lock()
+	defer l.reloading.Unlock()
+
+	if l.files != nil && !l.modified() {
+		return nil, nil
+	}
+	return l.load()
+}
+
+// Watch checks the file every interval until ctx is done, errors are passed to callbacks registered with OnError.
+// It loads the file first when it has not been loaded.
+func (l *Loader) Watch(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := l.Check(); err != nil {
+			l.mu.RLock()
+			callbacks := l.errorCallbacks
+			l.mu.RUnlock()
+			for _, callback := range callbacks {
+				callback(err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// load decodes the file into a new value and replaces the current one, the event is nil when the value is kept.
+func (l *Loader) load() (*ReloadEvent, error) {
+	m := l.Marshaller
+	if m == nil {
+		m = &Marshaller{}
+	}
+	fsys := &stampingFS{FS: l.fsys, files: map[string]fileStamp{}}
+
+	parse := ParseFile
+	if m.Include {
+		parse = ParseFileWithIncludes
+	}
+	document, err := parse(fsys, l.name)
+	var value interface{}
+	if err == nil {
+		value = l.newValue()
+		err = m.marshalDocument(document, value)
+	}
+
+	// files are updated even on error not to report the same contents again
+	l.files = fsys.files
+	if err != nil {
+		return nil, err
+	}
+
+	changes := Diff(l.document, document)
+	if l.document != nil && len(changes) == 0 {
+		return nil, nil
+	}
+	l.document = document
+
+	l.mu.Lock()
+	event := &ReloadEvent{Old: l.current, New: value, Changes: changes}
+	l.current = value
+	l.mu.Unlock()
+	return event, nil
+}
+
+// modified reports whether any of files read by the last load has changed.
+func (l *Loader) modified() bool {
+	for name, stamp := range l.files {
+		info, err := fs.Stat(l.fsys, name)
+		if err != nil {
+			if stamp.exists {
+				return true
+			}
+			continue
+		}
+		if !stamp.exists {
+			return true
+		}
+		if info.ModTime().Equal(stamp.modTime) && info.Size() == stamp.size {
+			continue
+		}
+
+		data, err := fs.ReadFile(l.fsys, name)
+		if err != nil || sha256.Sum256(data) != stamp.sum {
+			return true
+		}
+		// touched without changes
+		stamp.modTime = info.ModTime()
+		stamp.size = info.Size()
+		l.files[name] = stamp
+	}
+	return false
+}
+
+// stampingFS records states of files read through it.
+type stampingFS struct {
+	fs.FS
+	files map[string]fileStamp
+}
+
+func (f *stampingFS) ReadFile(name string) ([]byte, error) {
+	info, err := fs.Stat(f.FS, name)
+	if err != nil {
+		f.files[name] = fileStamp{}
+		return nil, err
+	}
+	data, err := fs.ReadFile(f.FS, name)
+	if err != nil {
+		f.files[name] = fileStamp{}
+		return nil, err
+	}
+	f.files[name] = fileStamp{exists: true, modTime: info.ModTime(), size: info.Size(), sum: sha256.Sum256(data)}
+	return data, nil
+}
@@ -0,0 +1,165 @@
+package ntgo
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type loaderConfig struct {
+	Host string   `nt:"host"`
+	Port int      `nt:"port"`
+	Tags []string `nt:"tags"`
+}
+
+func TestLoader(t *testing.T) {
+	newConfig := func() interface{} { return &loaderConfig{} }
+	base := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	setup := func() (fstest.MapFS, *Loader, *[]*ReloadEvent) {
+		fsys := fstest.MapFS{
+			"app.nt": {Data: []byte("host: localhost\nport: 80"), ModTime: base},
+		}
+		loader := NewLoader(fsys, "app.nt", newConfig)
+		events := &[]*ReloadEvent{}
+		loader.OnReload(func(event *ReloadEvent) { *events = append(*events, event) })
+		assert.Nil(t, loader.Load())
+		return fsys, loader, events
+	}
+
+	t.Run("Load", func(t *testing.T) {
+		t.Run("should decode the file", func(t *testing.T) {
+			_, loader, events := setup()
+			assert.Equal(t, &loaderConfig{Host: "localhost", Port: 80}, loader.Current())
+			assert.Equal(t, 0, len(*events))
+		})
+	})
+
+	t.Run("Check", func(t *testing.T) {
+		t.Run("should not reload unchanged file", func(t *testing.T) {
+			_, loader, events := setup()
+			current := loader.Current()
+			changed, err := loader.Check()
+			assert.False(t, changed)
+			assert.Nil(t, err)
+			assert.Same(t, current, loader.Current())
+			assert.Equal(t, 0, len(*events))
+		})
+
+		t.Run("should reload changed file and invoke callbacks with changes", func(t *testing.T) {
+			fsys, loader, events := setup()
+			fsys["app.nt"] = &fstest.MapFile{Data: []byte("host: example.com\nport: 80"), ModTime: base.Add(time.Second)}
+			changed, err := loader.Check()
+			assert.True(t, changed)
+			assert.Nil(t, err)
+			assert.Equal(t, &loaderConfig{Host: "example.com", Port: 80}, loader.Current())
+			assert.Equal(t, 1, len(*events))
+			assert.Equal(t, &loaderConfig{Host: "localhost", Port: 80}, (*events)[0].Old)
+			assert.Equal(t, &loaderConfig{Host: "example.com", Port: 80}, (*events)[0].New)
+			assert.Equal(t, 1, len((*events)[0].Changes))
+			assert.Equal(t, `modified "host"`, (*events)[0].Changes[0].String())
+		})
+
+		t.Run("should allow callbacks to call the loader", func(t *testing.T) {
+			fsys, loader, _ := setup()
+			var errs []error
+			loader.OnReload(func(event *ReloadEvent) {
+				errs = append(errs, loader.Load())
+				_, err := loader.Check()
+				errs = append(errs, err)
+			})
+			fsys["app.nt"] = &fstest.MapFile{Data: []byte("host: example.com\nport: 80"), ModTime: base.Add(time.Second)}
+			changed, err := loader.Check()
+			assert.True(t, changed)
+			assert.Nil(t, err)
+			assert.Equal(t, []error{nil, nil}, errs)
+			assert.Equal(t, &loaderConfig{Host: "example.com", Port: 80}, loader.Current())
+		})
+
+		t.Run("should not invoke callbacks when the document is the same", func(t *testing.T) {
+			fsys, loader, events := setup()
+			fsys["app.nt"] = &fstest.MapFile{Data: []byte("port: 80\nhost: localhost"), ModTime: base.Add(time.Second)}
+			changed, err := loader.Check()
+			assert.False(t, changed)
+			assert.Nil(t, err)
+			assert.Equal(t, 0, len(*events))
+		})
+
+		t.Run("should keep the last good value when the file is invalid", func(t *testing.T) {
+			fsys, loader, events := setup()
+			fsys["app.nt"] = &fstest.MapFile{Data: []byte("host: example.com\nport: eighty"), ModTime: base.Add(time.Second)}
+			changed, err := loader.Check()
+			assert.False(t, changed)
+			assert.NotNil(t, err)
+			assert.Equal(t, &loaderConfig{Host: "localhost", Port: 80}, loader.Current())
+
+			_, err = loader.Check()
+			assert.Nil(t, err)
+
+			fsys["app.nt"] = &fstest.MapFile{Data: []byte("host: example.com\nport: 8080"), ModTime: base.Add(2 * time.Second)}
+			changed, err = loader.Check()
+			assert.True(t, changed)
+			assert.Nil(t, err)
+			assert.Equal(t, &loaderConfig{Host: "example.com", Port: 8080}, loader.Current())
+			assert.Equal(t, &loaderConfig{Host: "localhost", Port: 80}, (*events)[0].Old)
+		})
+
+		t.Run("should report missing file", func(t *testing.T) {
+			fsys, loader, _ := setup()
+			delete(fsys, "app.nt")
+			_, err := loader.Check()
+			assert.NotNil(t, err)
+			assert.Equal(t, &loaderConfig{Host: "localhost", Port: 80}, loader.Current())
+		})
+
+		t.Run("should detect changes of included files", func(t *testing.T) {
+			fsys := fstest.MapFS{
+				"app.nt":  {Data: []byte("host: localhost\ntags: !include tags.nt"), ModTime: base},
+				"tags.nt": {Data: []byte("- a"), ModTime: base},
+			}
+			loader := NewLoader(fsys, "app.nt", newConfig)
+			loader.Marshaller = &Marshaller{Include: true}
+			assert.Nil(t, loader.Load())
+
+			fsys["tags.nt"] = &fstest.MapFile{Data: []byte("- a\n- b"), ModTime: base.Add(time.Second)}
+			changed, err := loader.Check()
+			assert.True(t, changed)
+			assert.Nil(t, err)
+			assert.Equal(t, &loaderConfig{Host: "localhost", Tags: []string{"a", "b"}}, loader.Current())
+		})
+	})
+
+	t.Run("Watch", func(t *testing.T) {
+		t.Run("should reload the file of the OS until context is done", func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "ntgo")
+			assert.Nil(t, err)
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "app.nt")
+			assert.Nil(t, ioutil.WriteFile(path, []byte("host: localhost\nport: 80"), 0644))
+
+			loader := NewFileLoader(path, newConfig)
+			reloaded := make(chan *ReloadEvent, 1)
+			loader.OnReload(func(event *ReloadEvent) { reloaded <- event })
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error)
+			go func() { done <- loader.Watch(ctx, 10*time.Millisecond) }()
+
+			assert.Eventually(t, func() bool { return loader.Current() != nil }, time.Second, 10*time.Millisecond)
+			assert.Nil(t, ioutil.WriteFile(path, []byte("host: localhost\nport: 8080"), 0644))
+
+			select {
+			case event := <-reloaded:
+				assert.Equal(t, &loaderConfig{Host: "localhost", Port: 8080}, event.New)
+			case <-time.After(5 * time.Second):
+				assert.Fail(t, "file was not reloaded")
+			}
+			cancel()
+			assert.Equal(t, context.Canceled, <-done)
+		})
+	})
+}
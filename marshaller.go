@@ -1,9 +1,13 @@
 package ntgo
 
 import (
+	"encoding"
 	"errors"
 	"fmt"
+	"io/fs"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -11,33 +15,326 @@ var (
 	ValueIsNotPointerError = errors.New("ntgo: marshaling target must be pointer")
 )
 
-func Marshal(content string, v interface{}) error {
+// DecodeError describes a NestedText value that could not be stored in a Go value.
+type DecodeError struct {
+	// FieldPath is the path of Go struct fields, e.g. "Profile.Roles[1]"
+	FieldPath string
+	// KeyPath is the path of NestedText keys, e.g. "profile.roles[1]"
+	KeyPath string
+	// Line is the line of the value in the source, 0 when unknown
+	Line int
+	// File is the file of the value, empty unless the document is parsed by ParseNamed or included
+	File string
+
+	// Expected and Actual describe type mismatch
+	Expected string
+	Actual   ValueType
+
+	// Err is the cause other than type mismatch
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	location := fmt.Sprintf("key %s", displayPath(e.KeyPath))
+	if source := sourceLocation(e.File, e.Line); source != "" {
+		location = fmt.Sprintf("%s, %s", location, source)
+	}
+
+	if e.Err != nil {
+		if e.FieldPath != "" {
+			return fmt.Sprintf("ntgo: struct field %s: %v (%s)", e.FieldPath, e.Err, location)
+		}
+		return fmt.Sprintf("ntgo: %v (%s)", e.Err, location)
+	}
+
+	field := ""
+	if e.FieldPath != "" {
+		field = fmt.Sprintf(" for struct field %s", e.FieldPath)
+	}
+	return fmt.Sprintf("ntgo: expected %s%s, got %s (%s)", e.Expected, field, e.Actual, location)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+func newTypeMismatchError(value *Value, expected string) error {
+	return &DecodeError{Line: value.Line, File: value.File, Expected: expected, Actual: value.Type}
+}
+
+// newDecodeError returns DecodeError caused by err at value.
+func newDecodeError(value *Value, err error) *DecodeError {
+	return &DecodeError{Line: value.Line, File: value.File, Err: err}
+}
+
+// sourceLocation describes the position in file as "file:line", either of them may be absent.
+func sourceLocation(file string, line int) string {
+	switch {
+	case file != "" && line > 0:
+		return fmt.Sprintf("%s:%d", file, line)
+	case file != "":
+		return file
+	case line > 0:
+		return fmt.Sprintf("line %d", line)
+	}
+	return ""
+}
+
+// wrapDecodeError prepends segments of field and key to the paths of DecodeError.
+func wrapDecodeError(err error, fieldSegment string, keySegment string) error {
+	if decodeErr, ok := err.(*DecodeError); ok {
+		decodeErr.FieldPath = prependPath(fieldSegment, decodeErr.FieldPath)
+		decodeErr.KeyPath = prependPath(keySegment, decodeErr.KeyPath)
+	}
+	return err
+}
+
+func wrapDecodeErrorWithIndex(err error, index int) error {
+	segment := appendIndexPath("", index)
+	return wrapDecodeError(err, segment, segment)
+}
+
+// isEmptyValue reports whether value is an empty string, e.g. dictionary key without value.
+// Empty value is accepted as zero value of any type.
+func isEmptyValue(value *Value) bool {
+	return value.Type == ValueTypeString && value.String == ""
+}
+
+// NestedTextMarshaler is implemented by types that provide their own NestedText representation.
+type NestedTextMarshaler interface {
+	MarshalNestedText() (*Value, error)
+}
+
+// NestedTextUnmarshaler is implemented by types that decode NestedText representation of themselves.
+type NestedTextUnmarshaler interface {
+	UnmarshalNestedText(*Value) error
+}
+
+var (
+	nestedTextMarshalerType   = reflect.TypeOf((*NestedTextMarshaler)(nil)).Elem()
+	nestedTextUnmarshalerType = reflect.TypeOf((*NestedTextUnmarshaler)(nil)).Elem()
+	textMarshalerType         = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType       = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// EncodeError describes a Go value that could not be stringified into NestedText.
+type EncodeError struct {
+	// FieldPath is the path of Go struct fields, e.g. "Profile.Roles[1]"
+	FieldPath string
+	Err       error
+}
+
+func (e *EncodeError) Error() string {
+	if e.FieldPath == "" {
+		return fmt.Sprintf("ntgo: %v", e.Err)
+	}
+	return fmt.Sprintf("ntgo: struct field %s: %v", e.FieldPath, e.Err)
+}
+
+func (e *EncodeError) Unwrap() error {
+	return e.Err
+}
+
+func wrapEncodeError(err error, fieldSegment string) error {
+	if encodeErr, ok := err.(*EncodeError); ok {
+		encodeErr.FieldPath = prependPath(fieldSegment, encodeErr.FieldPath)
+	}
+	return err
+}
+
+// UnmarshalNestedText implements NestedTextUnmarshaler, value is copied as it is.
+func (v *Value) UnmarshalNestedText(value *Value) error {
+	*v = *value
+	return nil
+}
+
+// MarshalNestedText implements NestedTextMarshaler, v itself is used.
+func (v *Value) MarshalNestedText() (*Value, error) {
+	return v, nil
+}
+
+// Marshaller converts between NestedText documents and Go values.
+// The zero value is ready to use.
+type Marshaller struct {
+	// InterfaceAsValue makes interface{} receive *Value as it is,
+	// instead of string, []interface{} and map[string]interface{} converted by ToInterface.
+	InterfaceAsValue bool
+	// NamingStrategy derives keys of struct fields without nt tag.
+	// Field names are used as they are when it is nil.
+	NamingStrategy NamingStrategy
+	// DisallowUnknownFields makes Marshal return *UnknownKeysError
+	// when the document has keys without corresponding struct fields.
+	DisallowUnknownFields bool
+	// ReportMissingKeys makes Marshal return *MissingKeysError
+	// when keys of struct fields without default option are absent in dictionaries decoded into structs.
+	// Fields with required option are always reported.
+	// They are held in Missing of *UnknownKeysError when unknown keys are found as well.
+	ReportMissingKeys bool
+	// Merge makes Marshal update existing structs, pointers, maps and slices in place
+	// instead of replacing them with new instances.
+	// Fields already holding non-zero values are treated as present for default and required options.
+	Merge bool
+	// SliceMerge describes how slices are updated when Merge is enabled.
+	SliceMerge SliceMerge
+	// Interpolate makes Marshal interpolate ${...} in the document before decoding, see Interpolate of Value.
+	Interpolate bool
+	// LookupEnv looks up environment variables for Interpolate, os.LookupEnv is used when it is nil.
+	LookupEnv func(key string) (string, bool)
+	// Include makes MarshalFile replace include directives with included files, see IncludeDirective.
+	Include bool
+
+	state *decodeState
+}
+
+// SliceMerge describes how existing slices are updated by Marshaller with Merge.
+type SliceMerge int
+
+const (
+	// SliceMergeReplace replaces existing elements with elements in the document.
+	SliceMergeReplace SliceMerge = iota
+	// SliceMergeAppend appends elements in the document to existing elements.
+	SliceMergeAppend
+)
+
+// Marshal parses content and stores the result in the value pointed to by v.
+// Type mismatches between the content and fields of v are returned as *DecodeError.
+func (m *Marshaller) Marshal(content string, v interface{}) error {
 	typ := reflect.TypeOf(v)
-	if typ.Kind() != reflect.Ptr {
+	if typ == nil || typ.Kind() != reflect.Ptr {
 		return ValueIsNotPointerError
 	}
 
 	value := &Value{}
-	value.Parse([]byte(content))
+	if err := value.Parse([]byte(content)); err != nil {
+		return err
+	}
+	return m.marshalDocument(value, v)
+}
+
+// MarshalFile works like Marshal with the content of name in fsys.
+// Errors reading or parsing files are returned as *ParseError.
+func (m *Marshaller) MarshalFile(fsys fs.FS, name string, v interface{}) error {
+	typ := reflect.TypeOf(v)
+	if typ == nil || typ.Kind() != reflect.Ptr {
+		return ValueIsNotPointerError
+	}
+
+	parse := ParseFile
+	if m.Include {
+		parse = ParseFileWithIncludes
+	}
+	value, err := parse(fsys, name)
+	if err != nil {
+		return err
+	}
+	return m.marshalDocument(value, v)
+}
+
+// marshalDocument stores parsed value in the value pointed to by v.
+func (m *Marshaller) marshalDocument(value *Value, v interface{}) error {
+	if m.Interpolate {
+		if err := value.Interpolate(m.LookupEnv); err != nil {
+			return err
+		}
+	}
+
+	if unmarshaler, ok := v.(NestedTextUnmarshaler); ok {
+		return callNestedTextUnmarshaler(unmarshaler, value)
+	}
+
+	decoder := *m
+	decoder.state = &decodeState{}
 
 	ref := reflect.ValueOf(v)
-	typ = typ.Elem()
+	typ := reflect.TypeOf(v).Elem()
 
-	marshal(value, typ, &ref)
+	var err error
+	if typ.Kind() != reflect.Struct {
+		err = decoder.marshalField(value, typ, ref.Elem())
+	} else {
+		err = decoder.marshal(value, typ, &ref)
+	}
+	if err != nil {
+		return err
+	}
 
-	return nil
+	return decoder.stateError(value)
 }
 
-func Unmarshal(v interface{}) string {
+// Unmarshal stringifies v into NestedText document.
+// Errors returned by NestedTextMarshaler are returned as *EncodeError.
+func (m *Marshaller) Unmarshal(v interface{}) (string, error) {
 	value := reflect.ValueOf(v)
 	typ := reflect.TypeOf(v)
 
-	result, _ := unmarshal(typ, &value, 0, 0)
+	result, _, err := m.unmarshal(typ, &value, 0, 0, "")
+
+	return result, err
+}
+
+// Marshal parses content and stores the result in the struct pointed to by v.
+// Type mismatches between the content and fields of v are returned as *DecodeError.
+func Marshal(content string, v interface{}) error {
+	return (&Marshaller{}).Marshal(content, v)
+}
 
+// Unmarshal stringifies v into NestedText document.
+// Errors are ignored, use Unmarshal of Marshaller to receive them.
+func Unmarshal(v interface{}) string {
+	result, _ := (&Marshaller{}).Unmarshal(v)
 	return result
 }
 
-func marshalSlice(value *Value, elementType reflect.Type, sliceRef *reflect.Value) {
+// callNestedTextUnmarshaler decodes value with NestedTextUnmarshaler.
+// Errors other than DecodeError are wrapped with the line of value.
+func callNestedTextUnmarshaler(unmarshaler NestedTextUnmarshaler, value *Value) error {
+	err := unmarshaler.UnmarshalNestedText(value)
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*DecodeError); ok {
+		return err
+	}
+	return newDecodeError(value, err)
+}
+
+// callTextUnmarshaler decodes string or text value with encoding.TextUnmarshaler.
+func callTextUnmarshaler(unmarshaler encoding.TextUnmarshaler, value *Value) error {
+	var text string
+	switch value.Type {
+	case ValueTypeString:
+		text = value.String
+	case ValueTypeText:
+		text = value.Text.String()
+	default:
+		return newTypeMismatchError(value, "string or text")
+	}
+
+	if err := unmarshaler.UnmarshalText([]byte(text)); err != nil {
+		return newDecodeError(value, err)
+	}
+	return nil
+}
+
+// hasCustomUnmarshaler reports whether pointer of typ implements NestedTextUnmarshaler or encoding.TextUnmarshaler.
+func hasCustomUnmarshaler(typ reflect.Type) bool {
+	ptr := reflect.PtrTo(typ)
+	return ptr.Implements(nestedTextUnmarshalerType) || ptr.Implements(textUnmarshalerType)
+}
+
+func (m *Marshaller) marshalSlice(value *Value, elementType reflect.Type, sliceRef *reflect.Value) error {
+	// elements decoding themselves
+	if hasCustomUnmarshaler(elementType) {
+		return m.marshalListElements(value, elementType, sliceRef, false)
+	}
+	if elementType.Kind() == reflect.Ptr && hasCustomUnmarshaler(elementType.Elem()) {
+		return m.marshalListElements(value, elementType.Elem(), sliceRef, true)
+	}
+
+	if isEmptyValue(value) && elementType.Kind() != reflect.String {
+		return nil
+	}
+
 	// type of slice element
 	switch elementType.Kind() {
 	case reflect.String:
@@ -51,139 +348,459 @@ func marshalSlice(value *Value, elementType reflect.Type, sliceRef *reflect.Valu
 					*sliceRef = reflect.Append(*sliceRef, reflect.ValueOf(line))
 				}
 			case ValueTypeList:
-				for _, child := range value.List {
+				for i, child := range value.List {
+					if child.Type != ValueTypeString {
+						return wrapDecodeErrorWithIndex(newTypeMismatchError(child, "string"), i)
+					}
 					*sliceRef = reflect.Append(*sliceRef, reflect.ValueOf(child.String))
 				}
+			default:
+				return newTypeMismatchError(value, "list, text or string")
 			}
 		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128, reflect.Bool:
+		{
+			return marshalScalarSlice(value, elementType, sliceRef, false)
+		}
 	case reflect.Slice:
 		{
-			for _, child := range value.List {
+			if value.Type != ValueTypeList {
+				return newTypeMismatchError(value, "list")
+			}
+			for i, child := range value.List {
 				childWork := reflect.MakeSlice(elementType, 0, cap(child.List))
-				marshalSlice(child, elementType.Elem(), &childWork)
+				if err := m.marshalSlice(child, elementType.Elem(), &childWork); err != nil {
+					return wrapDecodeErrorWithIndex(err, i)
+				}
 				*sliceRef = reflect.Append(*sliceRef, childWork)
 			}
 		}
 	case reflect.Struct:
 		{
-			for _, child := range value.List {
+			if value.Type != ValueTypeList {
+				return newTypeMismatchError(value, "list")
+			}
+			for i, child := range value.List {
 				elementInstance := reflect.New(elementType).Elem()
-				marshal(child, elementType, &elementInstance)
+				if err := m.marshal(child, elementType, &elementInstance); err != nil {
+					return wrapDecodeErrorWithIndex(err, i)
+				}
 				*sliceRef = reflect.Append(*sliceRef, elementInstance)
 			}
 		}
+	case reflect.Array, reflect.Map, reflect.Interface:
+		{
+			return m.marshalListElements(value, elementType, sliceRef, false)
+		}
 	case reflect.Ptr:
 		{
-			switch value.Type {
-			case ValueTypeText:
-				for i, _ := range value.Text {
-					// using value occurs late binding
-					*sliceRef = reflect.Append(*sliceRef, reflect.ValueOf(&value.Text[i]))
-				}
-			case ValueTypeList:
-				elementType := elementType.Elem()
-				switch elementType.Kind() {
-				case reflect.String:
-					for _, child := range value.List {
+			elementType := elementType.Elem()
+			switch elementType.Kind() {
+			case reflect.String:
+				switch value.Type {
+				case ValueTypeString:
+					*sliceRef = reflect.Append(*sliceRef, reflect.ValueOf(&value.String))
+				case ValueTypeText:
+					for i, _ := range value.Text {
+						// using value occurs late binding
+						*sliceRef = reflect.Append(*sliceRef, reflect.ValueOf(&value.Text[i]))
+					}
+				case ValueTypeList:
+					for i, child := range value.List {
+						if child.Type != ValueTypeString {
+							return wrapDecodeErrorWithIndex(newTypeMismatchError(child, "string"), i)
+						}
 						*sliceRef = reflect.Append(*sliceRef, reflect.ValueOf(&child.String))
 					}
-				case reflect.Struct:
-					for _, child := range value.List {
-						elementInstance := reflect.New(elementType)
-						marshal(child, elementType, &elementInstance)
-						*sliceRef = reflect.Append(*sliceRef, elementInstance)
+				default:
+					return newTypeMismatchError(value, "list, text or string")
+				}
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+				reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+				reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128, reflect.Bool:
+				return marshalScalarSlice(value, elementType, sliceRef, true)
+			case reflect.Struct:
+				if value.Type != ValueTypeList {
+					return newTypeMismatchError(value, "list")
+				}
+				for i, child := range value.List {
+					elementInstance := reflect.New(elementType)
+					if err := m.marshal(child, elementType, &elementInstance); err != nil {
+						return wrapDecodeErrorWithIndex(err, i)
 					}
+					*sliceRef = reflect.Append(*sliceRef, elementInstance)
 				}
+			default:
+				return m.marshalListElements(value, elementType, sliceRef, true)
+			}
+		}
+	}
+
+	return nil
+}
+
+// marshalListElements appends each element of list value decoded as elementType to sliceRef.
+func (m *Marshaller) marshalListElements(value *Value, elementType reflect.Type, sliceRef *reflect.Value, pointer bool) error {
+	if value.Type != ValueTypeList {
+		return newTypeMismatchError(value, "list")
+	}
+
+	for i, child := range value.List {
+		elementInstance := reflect.New(elementType)
+		if err := m.marshalField(child, elementType, elementInstance.Elem()); err != nil {
+			return wrapDecodeErrorWithIndex(err, i)
+		}
+		if pointer {
+			*sliceRef = reflect.Append(*sliceRef, elementInstance)
+		} else {
+			*sliceRef = reflect.Append(*sliceRef, elementInstance.Elem())
+		}
+	}
+
+	return nil
+}
+
+// marshalScalarSlice appends numbers or booleans parsed from value to sliceRef.
+// A string is treated as a list with single element.
+func marshalScalarSlice(value *Value, elementType reflect.Type, sliceRef *reflect.Value, pointer bool) error {
+	var elements []*Value
+	switch value.Type {
+	case ValueTypeString:
+		elements = []*Value{value}
+	case ValueTypeList:
+		elements = value.List
+	default:
+		return newTypeMismatchError(value, "list or string")
+	}
+
+	for i, child := range elements {
+		elementInstance := reflect.New(elementType)
+		if err := marshalScalar(child, elementInstance.Elem()); err != nil {
+			if value.Type == ValueTypeList {
+				return wrapDecodeErrorWithIndex(err, i)
 			}
+			return err
 		}
+		if pointer {
+			*sliceRef = reflect.Append(*sliceRef, elementInstance)
+		} else {
+			*sliceRef = reflect.Append(*sliceRef, elementInstance.Elem())
+		}
+	}
+
+	return nil
+}
+
+// marshalScalar parses string value and stores it into ref of number or boolean.
+// Leading and trailing spaces are ignored, and empty value is stored as zero value.
+func marshalScalar(value *Value, ref reflect.Value) error {
+	if value.Type != ValueTypeString {
+		return newTypeMismatchError(value, "string")
 	}
+
+	str := strings.TrimSpace(value.String)
+	if str == "" {
+		ref.Set(reflect.Zero(ref.Type()))
+		return nil
+	}
+	var err error
+
+	switch ref.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		if i, err = strconv.ParseInt(str, 10, ref.Type().Bits()); err == nil {
+			ref.SetInt(i)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		if u, err = strconv.ParseUint(str, 10, ref.Type().Bits()); err == nil {
+			ref.SetUint(u)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(str, ref.Type().Bits()); err == nil {
+			ref.SetFloat(f)
+		}
+	case reflect.Complex64, reflect.Complex128:
+		var c complex128
+		if c, err = strconv.ParseComplex(str, ref.Type().Bits()); err == nil {
+			ref.SetComplex(c)
+		}
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(str); err == nil {
+			ref.SetBool(b)
+		}
+	}
+
+	if err != nil {
+		return newDecodeError(value, err)
+	}
+	return nil
 }
 
-func marshal(value *Value, typ reflect.Type, ref *reflect.Value) {
+// marshalMapKey converts dictionary key into the key type of map.
+// Strings, types implementing encoding.TextUnmarshaler, numbers and booleans are supported.
+// Errors are located at the value of the key.
+func marshalMapKey(key string, keyType reflect.Type, at *Value) (reflect.Value, error) {
+	if keyType.Kind() == reflect.String {
+		return reflect.ValueOf(key).Convert(keyType), nil
+	}
+
+	keyInstance := reflect.New(keyType)
+	if unmarshaler, ok := keyInstance.Interface().(encoding.TextUnmarshaler); ok {
+		if err := unmarshaler.UnmarshalText([]byte(key)); err != nil {
+			return keyInstance, newDecodeError(at, err)
+		}
+		return keyInstance.Elem(), nil
+	}
+
+	switch keyType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128, reflect.Bool:
+		err := marshalScalar(&Value{Type: ValueTypeString, String: key, Line: at.Line, File: at.File}, keyInstance.Elem())
+		return keyInstance.Elem(), err
+	}
+
+	return keyInstance, newDecodeError(at, fmt.Errorf("unsupported map key type %v", keyType))
+}
+
+func (m *Marshaller) marshal(value *Value, typ reflect.Type, ref *reflect.Value) error {
+	if value.Type != ValueTypeDictionary {
+		if isEmptyValue(value) {
+			return nil
+		}
+		return newTypeMismatchError(value, "dictionary")
+	}
+
 	substance := *ref
 	if ref.Type().Kind() == reflect.Ptr {
 		substance = substance.Elem()
 	}
 
-	for i := 0; i < typ.NumField(); i++ {
-		fieldInfo := typ.Field(i)
-		fieldRef := substance.Field(i)
-		tagValue := fieldInfo.Tag.Get(MarshallerTag)
+	fields, err := cachedTypeFields(typ, m.NamingStrategy)
+	if err != nil {
+		return err
+	}
+	found := map[string]bool{}
 
-		if tagValue == "" {
+	for _, key := range value.Keys() {
+		f, exists := fields.lookup(key)
+		if !exists {
 			continue
 		}
+		found[f.key] = true
+		childValue := value.Dictionary[key]
 
-		tagValues := strings.Split(tagValue, MarshallerTagSeparator)
-		key := tagValues[0]
+		fieldRef, _, err := fieldByIndex(substance, f.index, true)
+		if err != nil {
+			return wrapDecodeError(newDecodeError(childValue, err), f.name, key)
+		}
 
-		childValue, exists := value.Dictionary[key]
-		if !exists {
+		if err := m.marshalField(childValue, f.typ, fieldRef); err != nil {
+			return wrapDecodeError(err, f.name, key)
+		}
+	}
+
+	if m.Merge {
+		for _, f := range fields.list {
+			if found[f.key] {
+				continue
+			}
+			if fieldRef, ok, _ := fieldByIndex(substance, f.index, false); ok && !fieldRef.IsZero() {
+				found[f.key] = true
+			}
+		}
+	}
+
+	for _, f := range fields.list {
+		if found[f.key] || !f.hasDefault {
 			continue
 		}
 
-		fieldType := fieldInfo.Type
+		fieldRef, _, err := fieldByIndex(substance, f.index, true)
+		if err == nil {
+			// default value is converted in the same manner as values in the document
+			err = m.marshalField(&Value{Type: ValueTypeString, String: f.defaultValue}, f.typ, fieldRef)
+		}
+		if err != nil {
+			return wrapDecodeError(err, f.name, f.key)
+		}
+	}
 
-		switch fieldType.Kind() {
-		case reflect.String:
-			{
-				if childValue.Type == ValueTypeText {
-					fieldRef.SetString(strings.Join(childValue.Text, ""))
-				} else {
-					fieldRef.SetString(childValue.String)
+	m.checkKeys(value, typ, fields, found)
+
+	return nil
+}
+
+func (m *Marshaller) marshalField(childValue *Value, fieldType reflect.Type, fieldRef reflect.Value) error {
+	if fieldRef.CanAddr() && fieldType.Kind() != reflect.Ptr {
+		switch unmarshaler := fieldRef.Addr().Interface().(type) {
+		case NestedTextUnmarshaler:
+			return callNestedTextUnmarshaler(unmarshaler, childValue)
+		case encoding.TextUnmarshaler:
+			return callTextUnmarshaler(unmarshaler, childValue)
+		}
+	}
+
+	switch fieldType.Kind() {
+	case reflect.String:
+		{
+			switch childValue.Type {
+			case ValueTypeText:
+				fieldRef.SetString(strings.Join(childValue.Text, ""))
+			case ValueTypeString:
+				fieldRef.SetString(childValue.String)
+			default:
+				return newTypeMismatchError(childValue, "string or text")
+			}
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128, reflect.Bool:
+		{
+			return marshalScalar(childValue, fieldRef)
+		}
+	case reflect.Slice:
+		{
+			work := reflect.MakeSlice(fieldRef.Type(), 0, cap(childValue.List))
+			if m.Merge && m.SliceMerge == SliceMergeAppend {
+				work = fieldRef
+			}
+			if err := m.marshalSlice(childValue, fieldType.Elem(), &work); err != nil {
+				return err
+			}
+			fieldRef.Set(work)
+		}
+	case reflect.Array:
+		{
+			if isEmptyValue(childValue) {
+				return nil
+			}
+
+			// decoded as slice, then its length is checked
+			work := reflect.MakeSlice(reflect.SliceOf(fieldType.Elem()), 0, fieldType.Len())
+			if err := m.marshalSlice(childValue, fieldType.Elem(), &work); err != nil {
+				return err
+			}
+			if work.Len() != fieldType.Len() {
+				return newDecodeError(childValue, fmt.Errorf("expected %d elements for %v, got %d", fieldType.Len(), fieldType, work.Len()))
+			}
+			reflect.Copy(fieldRef, work)
+		}
+	case reflect.Struct:
+		{
+			fieldInstance := reflect.New(fieldType).Elem()
+			if m.Merge {
+				fieldInstance.Set(fieldRef)
+			}
+			if err := m.marshal(childValue, fieldType, &fieldInstance); err != nil {
+				return err
+			}
+			fieldRef.Set(fieldInstance)
+		}
+	case reflect.Map:
+		{
+			if childValue.Type != ValueTypeDictionary {
+				if isEmptyValue(childValue) {
+					return nil
 				}
+				return newTypeMismatchError(childValue, "dictionary")
+			}
+
+			work := reflect.MakeMapWithSize(fieldType, len(childValue.Dictionary))
+			if m.Merge && !fieldRef.IsNil() {
+				work = fieldRef
 			}
-		case reflect.Slice:
-			{
-				work := reflect.MakeSlice(fieldRef.Type(), 0, cap(childValue.List))
-				marshalSlice(childValue, fieldType.Elem(), &work)
-				fieldRef.Set(work)
-			}
-		case reflect.Struct:
-			{
-				fieldInstance := reflect.New(fieldType).Elem()
-				marshal(childValue, fieldType, &fieldInstance)
-				fieldRef.Set(fieldInstance)
-			}
-		case reflect.Ptr:
-			{
-				fieldType := fieldType.Elem()
-				fieldInstance := reflect.New(fieldType)
-				switch fieldType.Kind() {
-				case reflect.Struct:
-					marshal(childValue, fieldType, &fieldInstance)
-					fieldRef.Set(fieldInstance)
-				case reflect.String:
-					fieldRef.Set(reflect.ValueOf(&childValue.String))
+			for _, key := range childValue.Keys() {
+				elementValue := childValue.Dictionary[key]
+				keyRef, err := marshalMapKey(key, fieldType.Key(), elementValue)
+				if err == nil {
+					elementInstance := reflect.New(fieldType.Elem()).Elem()
+					if existing := work.MapIndex(keyRef); m.Merge && existing.IsValid() {
+						// map elements are not addressable, merged into a copy
+						elementInstance.Set(existing)
+					}
+					if err = m.marshalField(elementValue, fieldType.Elem(), elementInstance); err == nil {
+						work.SetMapIndex(keyRef, elementInstance)
+					}
+				}
+				if err != nil {
+					return wrapDecodeError(err, fmt.Sprintf("[%q]", key), key)
 				}
 			}
+			fieldRef.Set(work)
+		}
+	case reflect.Ptr:
+		{
+			// empty value is nil as it is written for nil pointer
+			if isEmptyValue(childValue) {
+				fieldRef.Set(reflect.Zero(fieldType))
+				return nil
+			}
+			fieldInstance := reflect.New(fieldType.Elem())
+			if m.Merge && !fieldRef.IsNil() {
+				fieldInstance = fieldRef
+			}
+			if err := m.marshalField(childValue, fieldType.Elem(), fieldInstance.Elem()); err != nil {
+				return err
+			}
+			fieldRef.Set(fieldInstance)
+		}
+	case reflect.Interface:
+		{
+			if fieldType.NumMethod() > 0 {
+				return newDecodeError(childValue, fmt.Errorf("unsupported type %v", fieldType))
+			}
+			if m.InterfaceAsValue {
+				fieldRef.Set(reflect.ValueOf(childValue))
+			} else {
+				fieldRef.Set(reflect.ValueOf(childValue.ToInterface()))
+			}
 		}
 	}
+
+	return nil
 }
 
-func unmarshal(typ reflect.Type, ref *reflect.Value, depth int, tagFlag int) (string, bool) {
+func (m *Marshaller) unmarshal(typ reflect.Type, ref *reflect.Value, depth int, tagFlag int, format string) (string, bool, error) {
+	if value, ok, err := customMarshalerValue(*ref); err != nil {
+		return "", false, err
+	} else if ok {
+		content, _, exists := renderCustomValue(value, depth)
+		return content, exists, nil
+	}
 
 	switch typ.Kind() {
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return fmt.Sprintf("%d%s", ref.Int(), string(LF)), true
-	case reflect.Float32, reflect.Float64:
-		return fmt.Sprintf("%f%s", ref.Float(), string(LF)), true
+	case reflect.Interface:
+		if ref.IsNil() {
+			return "", false, nil
+		}
+		elem := ref.Elem()
+		return m.unmarshal(elem.Type(), &elem, depth, tagFlag, format)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128, reflect.Bool:
+		return formatScalar(*ref, format) + string(LF), true, nil
 	case reflect.String:
 		value := ref.String()
 		lines := strings.Split(value, string(LF))
 		if len(lines) == 1 {
 			if (tagFlag & MarshallerTagFlagMultilineStrings) == MarshallerTagFlagMultilineStrings {
-				return fmt.Sprintf("%s%s %s%s", fmt.Sprintf("%*s", depth*UnmarshalDefaultIndentSize, ""), string(TextToken), value, string(LF)), value != ""
+				return fmt.Sprintf("%s%s %s%s", fmt.Sprintf("%*s", depth*UnmarshalDefaultIndentSize, ""), string(TextToken), value, string(LF)), value != "", nil
 			}
-			return fmt.Sprintf("%s%s", value, string(LF)), value != ""
+			return fmt.Sprintf("%s%s", value, string(LF)), value != "", nil
 		}
 
 		result := ""
 		for _, line := range lines {
 			result += fmt.Sprintf("%s%s %s%s", fmt.Sprintf("%*s", depth*UnmarshalDefaultIndentSize, ""), string(TextToken), line, string(LF))
 		}
-		return result, true
-	case reflect.Slice:
+		return result, true, nil
+	case reflect.Slice, reflect.Array:
 		{
 			var result string
 			var lineBreakAfterKey string
@@ -197,10 +814,10 @@ func unmarshal(typ reflect.Type, ref *reflect.Value, depth int, tagFlag int) (st
 				sliceElementPointingType = sliceElementType.Elem()
 			}
 
-			switch sliceElementPointingType.Kind() {
-			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Float32, reflect.Float64:
+			switch kind := sliceElementPointingType.Kind(); {
+			case isScalarKind(kind):
 				lineBreakAfterKey = string(Space)
-			case reflect.String:
+			case kind == reflect.String:
 				if (tagFlag & MarshallerTagFlagMultilineStrings) == MarshallerTagFlagMultilineStrings {
 					childDepth = depth
 					lineBreakAfterKey = ""
@@ -216,88 +833,304 @@ func unmarshal(typ reflect.Type, ref *reflect.Value, depth int, tagFlag int) (st
 			for i := 0; i < ref.Len(); i++ {
 				childRef := ref.Index(i)
 
-				childContent, _ := unmarshal(sliceElementType, &childRef, childDepth, tagFlag)
-				result += fmt.Sprintf("%s%s%s%s", fmt.Sprintf("%*s", indentSize, ""), valueToken, lineBreakAfterKey, childContent)
+				// dynamic type decides the form of each element
+				if sliceElementType.Kind() == reflect.Interface {
+					entry, _, err := m.unmarshalEntry(string(ListToken), childRef.Type(), childRef, depth, tagFlag, format)
+					if err != nil {
+						return "", false, wrapEncodeError(err, appendIndexPath("", i))
+					}
+					result = appendEntry(result, entry)
+					continue
+				}
+
+				value, custom, err := customMarshalerValue(childRef)
+				if err != nil {
+					return "", false, wrapEncodeError(err, appendIndexPath("", i))
+				}
+				if custom {
+					childContent, lineBreak, _ := renderCustomValue(value, depth+1)
+					result = appendEntry(result, fmt.Sprintf("%s%s%s%s", fmt.Sprintf("%*s", depth*UnmarshalDefaultIndentSize, ""), string(ListToken), lineBreak, childContent))
+					continue
+				}
+
+				childContent, _, err := m.unmarshal(sliceElementType, &childRef, childDepth, tagFlag, format)
+				if err != nil {
+					return "", false, wrapEncodeError(err, appendIndexPath("", i))
+				}
+				result = appendEntry(result, fmt.Sprintf("%s%s%s%s", fmt.Sprintf("%*s", indentSize, ""), valueToken, lineBreakAfterKey, childContent))
 			}
-			return result, ref.Len() > 0
+			return result, ref.Len() > 0, nil
 		}
 	case reflect.Struct:
 		{
 			substance := *ref
 			var result string
-			for i := 0; i < typ.NumField(); i++ {
-				fieldInfo := typ.Field(i)
-				fieldRef := substance.Field(i)
-				tagValue := fieldInfo.Tag.Get(MarshallerTag)
-				if tagValue == "" {
+			fields, err := cachedTypeFields(typ, m.NamingStrategy)
+			if err != nil {
+				return "", false, err
+			}
+			for _, f := range fields.list {
+				// fields of nil embedded pointer are not written
+				fieldRef, ok, _ := fieldByIndex(substance, f.index, false)
+				if !ok {
 					continue
 				}
 
-				fieldType := fieldInfo.Type
-				tagValues := strings.Split(tagValue, MarshallerTagSeparator)
-				key := tagValues[0]
-
-				childTagFlag := getTagFlagFromTagValue(tagValues)
-
-				var lineBreakAfterKey string
-
-				switch fieldType.Kind() {
-				case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Float32, reflect.Float64:
-					lineBreakAfterKey = string(Space)
-				case reflect.String:
-					lineBreakAfterKey = string(Space)
-
-					lines := strings.Split(fieldRef.String(), string(LF))
-					if len(lines) > 1 {
-						lineBreakAfterKey = string(LF)
-					} else {
-						if (childTagFlag & MarshallerTagFlagMultilineStrings) == MarshallerTagFlagMultilineStrings {
-							lineBreakAfterKey = string(LF)
-						}
-					}
-				case reflect.Ptr:
-					switch fieldRef.Type().Elem().Kind() {
-					case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Float32, reflect.Float64:
-						lineBreakAfterKey = string(Space)
-
-						lines := strings.Split(fieldRef.Elem().String(), string(LF))
-						if len(lines) > 1 {
-							lineBreakAfterKey = string(LF)
-						} else {
-							if (childTagFlag & MarshallerTagFlagMultilineStrings) == MarshallerTagFlagMultilineStrings {
-								lineBreakAfterKey = string(LF)
-							}
-						}
-					default:
-						lineBreakAfterKey = string(LF)
-					}
-				default:
-					lineBreakAfterKey = string(LF)
+				entry, exists, err := m.unmarshalDictionaryEntry(f.key, f.typ, fieldRef, depth, f.tagFlag, f.format)
+				if err != nil {
+					return "", false, wrapEncodeError(err, f.name)
+				}
+				if !exists && (f.tagFlag&MarshallerTagFlagOmitEmpty) == MarshallerTagFlagOmitEmpty {
+					continue
 				}
+				result = appendEntry(result, entry)
+			}
+			return result, true, nil
+		}
+	case reflect.Map:
+		{
+			if ref.Len() == 0 {
+				return "", false, nil
+			}
 
-				marshalizedValue, exists := unmarshal(fieldType, &fieldRef, depth+1, childTagFlag)
+			keys, err := sortedMapKeys(*ref)
+			if err != nil {
+				return "", false, err
+			}
 
-				if !exists {
-					if (childTagFlag & MarshallerTagFlagOmitEmpty) == MarshallerTagFlagOmitEmpty {
-						continue
-					}
-					marshalizedValue = ""
+			var result string
+			for _, key := range keys {
+				entry, _, err := m.unmarshalDictionaryEntry(key.name, typ.Elem(), ref.MapIndex(key.ref), depth, tagFlag, format)
+				if err != nil {
+					return "", false, wrapEncodeError(err, fmt.Sprintf("[%q]", key.name))
 				}
-				result += fmt.Sprintf("%s%s:%s%s", fmt.Sprintf("%*s", depth*UnmarshalDefaultIndentSize, ""), key, lineBreakAfterKey, marshalizedValue)
+				result = appendEntry(result, entry)
 			}
-			return result, true
+			return result, true, nil
 		}
 	case reflect.Ptr:
 		{
 			if ref.IsNil() {
-				return "", false
+				return "", false, nil
 			}
 
 			elem := ref.Elem()
-			return unmarshal(typ.Elem(), &elem, depth, tagFlag)
+			return m.unmarshal(typ.Elem(), &elem, depth, tagFlag, format)
 		}
 	}
-	return "", false
+	return "", false, nil
+}
+
+// unmarshalDictionaryEntry stringifies a pair of key and value as an element of dictionary.
+// Value is written as empty when it does not exist.
+func (m *Marshaller) unmarshalDictionaryEntry(key string, fieldType reflect.Type, fieldRef reflect.Value, depth int, tagFlag int, format string) (string, bool, error) {
+	return m.unmarshalEntry(fmt.Sprintf("%s%c", formatDictionaryKey(key), DictionaryKeySeparator), fieldType, fieldRef, depth, tagFlag, format)
+}
+
+// unmarshalEntry stringifies value following token, either of dictionary key or list token.
+// The form of value is decided by dynamic type when fieldType is interface.
+func (m *Marshaller) unmarshalEntry(token string, fieldType reflect.Type, fieldRef reflect.Value, depth int, tagFlag int, format string) (string, bool, error) {
+	indent := fmt.Sprintf("%*s", depth*UnmarshalDefaultIndentSize, "")
+
+	if fieldType.Kind() == reflect.Interface && !fieldRef.IsNil() {
+		fieldRef = fieldRef.Elem()
+		fieldType = fieldRef.Type()
+	}
+
+	value, custom, err := customMarshalerValue(fieldRef)
+	if err != nil {
+		return "", false, err
+	}
+	if custom {
+		content, lineBreak, exists := renderCustomValue(value, depth+1)
+		return fmt.Sprintf("%s%s%s%s", indent, token, lineBreak, content), exists, nil
+	}
+
+	var lineBreakAfterKey string
+
+	switch kind := fieldType.Kind(); {
+	case isScalarKind(kind):
+		lineBreakAfterKey = string(Space)
+	case kind == reflect.String:
+		lineBreakAfterKey = string(Space)
+
+		lines := strings.Split(fieldRef.String(), string(LF))
+		if len(lines) > 1 {
+			lineBreakAfterKey = string(LF)
+		} else {
+			if (tagFlag & MarshallerTagFlagMultilineStrings) == MarshallerTagFlagMultilineStrings {
+				lineBreakAfterKey = string(LF)
+			}
+		}
+	case kind == reflect.Ptr:
+		switch kind := fieldRef.Type().Elem().Kind(); {
+		case kind == reflect.String || isScalarKind(kind):
+			lineBreakAfterKey = string(Space)
+
+			lines := strings.Split(fieldRef.Elem().String(), string(LF))
+			if len(lines) > 1 {
+				lineBreakAfterKey = string(LF)
+			} else {
+				if (tagFlag & MarshallerTagFlagMultilineStrings) == MarshallerTagFlagMultilineStrings {
+					lineBreakAfterKey = string(LF)
+				}
+			}
+		default:
+			lineBreakAfterKey = string(LF)
+		}
+	default:
+		lineBreakAfterKey = string(LF)
+	}
+
+	marshalizedValue, exists, err := m.unmarshal(fieldType, &fieldRef, depth+1, tagFlag, format)
+	if err != nil {
+		return "", false, err
+	}
+	if !exists {
+		marshalizedValue = ""
+		if token == string(ListToken) {
+			// list element can not be omitted
+			lineBreakAfterKey = string(LF)
+		}
+	}
+
+	return fmt.Sprintf("%s%s%s%s", indent, token, lineBreakAfterKey, marshalizedValue), exists, nil
+}
+
+// appendEntry appends entry of dictionary or list to result.
+// Empty value at the end is written as a token followed by space, which is terminated when another entry follows.
+func appendEntry(result string, entry string) string {
+	if result != "" && !strings.HasSuffix(result, string(LF)) {
+		result = strings.TrimSuffix(result, string(Space)) + string(LF)
+	}
+	return result + entry
+}
+
+type mapKey struct {
+	name string
+	ref  reflect.Value
+}
+
+// sortedMapKeys returns keys of map in sorted order of their string representation for deterministic output.
+func sortedMapKeys(ref reflect.Value) ([]mapKey, error) {
+	keys := make([]mapKey, 0, ref.Len())
+	for _, keyRef := range ref.MapKeys() {
+		var name string
+		if keyRef.Kind() == reflect.String {
+			name = keyRef.String()
+		} else if marshaler, ok := keyRef.Interface().(encoding.TextMarshaler); ok {
+			text, err := marshaler.MarshalText()
+			if err != nil {
+				return nil, &EncodeError{Err: err}
+			}
+			name = string(text)
+		} else {
+			name = fmt.Sprint(keyRef.Interface())
+		}
+		keys = append(keys, mapKey{name: name, ref: keyRef})
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return keys[i].name < keys[j].name })
+
+	return keys, nil
+}
+
+// customMarshalerValue returns Value provided by NestedTextMarshaler or encoding.TextMarshaler when ref implements it.
+// Methods with pointer receiver are also used for values that are not addressable.
+func customMarshalerValue(ref reflect.Value) (*Value, bool, error) {
+	if !ref.IsValid() || !ref.CanInterface() || (ref.Kind() == reflect.Ptr && ref.IsNil()) {
+		return nil, false, nil
+	}
+
+	typ := ref.Type()
+	if ref.Kind() != reflect.Ptr && !typ.Implements(nestedTextMarshalerType) && !typ.Implements(textMarshalerType) {
+		ptr := reflect.PtrTo(typ)
+		if !ptr.Implements(nestedTextMarshalerType) && !ptr.Implements(textMarshalerType) {
+			return nil, false, nil
+		}
+		if !ref.CanAddr() {
+			addressable := reflect.New(typ)
+			addressable.Elem().Set(ref)
+			ref = addressable.Elem()
+		}
+		ref = ref.Addr()
+	}
+
+	switch marshaler := ref.Interface().(type) {
+	case NestedTextMarshaler:
+		value, err := marshaler.MarshalNestedText()
+		if err != nil {
+			return nil, false, &EncodeError{Err: err}
+		}
+		if value == nil {
+			value = &Value{Type: ValueTypeString}
+		}
+		return value, true, nil
+	case encoding.TextMarshaler:
+		text, err := marshaler.MarshalText()
+		if err != nil {
+			return nil, false, &EncodeError{Err: err}
+		}
+		value := &Value{}
+		value.FromInterface(string(text))
+		return value, true, nil
+	}
+
+	return nil, false, nil
+}
+
+// renderCustomValue stringifies value provided by NestedTextMarshaler as a child in depth.
+// It also returns line break to be placed after its key or list token.
+// value is not modified since marshalers may return values they own.
+func renderCustomValue(value *Value, depth int) (string, string, bool) {
+	value = copyValueAtDepth(value, depth)
+
+	switch value.Type {
+	case ValueTypeString:
+		if value.String == "" {
+			return string(LF), "", false
+		}
+		return value.String + string(LF), string(Space), true
+	case ValueTypeText:
+		return value.ToNestedText() + string(LF), string(LF), true
+	case ValueTypeList, ValueTypeDictionary:
+		if len(value.List) == 0 && len(value.Dictionary) == 0 {
+			return "", string(LF), false
+		}
+		return value.ToNestedText(), string(LF), true
+	}
+	return "", string(LF), false
+}
+
+// copyValueAtDepth returns a deep copy of value placed in depth.
+func copyValueAtDepth(value *Value, depth int) *Value {
+	copied := *value
+	copied.Depth = depth
+	copied.IndentSize = UnmarshalDefaultIndentSize
+	if value.List != nil {
+		copied.List = make([]*Value, len(value.List))
+		for i, child := range value.List {
+			copied.List[i] = copyValueAtDepth(child, depth+1)
+		}
+	}
+	if value.Dictionary != nil {
+		copied.Dictionary = make(map[string]*Value, len(value.Dictionary))
+		for key, child := range value.Dictionary {
+			copied.Dictionary[key] = copyValueAtDepth(child, depth+1)
+		}
+	}
+	return &copied
+}
+
+func setValueDepth(value *Value, depth int) {
+	value.Depth = depth
+	value.IndentSize = UnmarshalDefaultIndentSize
+	for _, child := range value.List {
+		setValueDepth(child, depth+1)
+	}
+	for _, child := range value.Dictionary {
+		setValueDepth(child, depth+1)
+	}
 }
 
 func getTagFlagFromTagValue(tagValues []string) (flag int) {
@@ -307,8 +1140,57 @@ func getTagFlagFromTagValue(tagValues []string) (flag int) {
 			flag |= MarshallerTagFlagMultilineStrings
 		case MarshallerTagOmitEmpty:
 			flag |= MarshallerTagFlagOmitEmpty
+		case MarshallerTagInline:
+			flag |= MarshallerTagFlagInline
+		case MarshallerTagRequired:
+			flag |= MarshallerTagFlagRequired
 		}
 	}
 
 	return
 }
+
+// getTagOptionFromTagValue returns the value of option with prefix, e.g. "default=".
+// Since options are separated by commas, the value can not contain them.
+func getTagOptionFromTagValue(tagValues []string, prefix string) (string, bool) {
+	for i := 1; i < len(tagValues); i++ {
+		if strings.HasPrefix(tagValues[i], prefix) {
+			return strings.TrimPrefix(tagValues[i], prefix), true
+		}
+	}
+
+	return "", false
+}
+
+func isScalarKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128, reflect.Bool:
+		return true
+	}
+	return false
+}
+
+// formatScalar stringifies number or boolean.
+// Floats are written in the shortest form that parses back to the same value unless format is given,
+// format is a verb of fmt package such as "%.2f" accepted by checkFormat.
+func formatScalar(ref reflect.Value, format string) string {
+	if format != "" {
+		return fmt.Sprintf(format, ref.Interface())
+	}
+
+	switch ref.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(ref.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(ref.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(ref.Float(), 'g', -1, ref.Type().Bits())
+	case reflect.Complex64, reflect.Complex128:
+		return strconv.FormatComplex(ref.Complex(), 'g', -1, ref.Type().Bits())
+	case reflect.Bool:
+		return strconv.FormatBool(ref.Bool())
+	}
+	return ""
+}
@@ -1,9 +1,18 @@
 package ntgo
 
 import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"net"
 	"reflect"
+	"strconv"
 	"strings"
 	"testing"
+	"testing/quick"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -123,7 +132,7 @@ type RefStruct struct {
 	RefString2 *string `nt:"key2,omitempty"`
 }
 type UnsupportedStruct struct {
-	F bool `nt:"f"`
+	F chan int `nt:"f"`
 }
 
 type NumberStruct struct {
@@ -138,6 +147,262 @@ type NumberStruct struct {
 	Float32PtrSlice []*float32 `nt:"float_ptr_slice"`
 }
 
+type ScalarStruct struct {
+	Int8    int8    `nt:"int8"`
+	Uint    uint    `nt:"uint"`
+	Uint16  uint16  `nt:"uint16"`
+	Float64 float64 `nt:"float64"`
+	Bool    bool    `nt:"bool"`
+	BoolPtr *bool   `nt:"bool_ptr"`
+	Uints   []uint8 `nt:"uints"`
+	Bools   []*bool `nt:"bools"`
+	Ints    [][]int `nt:"ints"`
+}
+
+type MapService struct {
+	Host string `nt:"host"`
+	Port int    `nt:"port"`
+}
+
+type MapKeyCode struct {
+	Code string
+}
+
+func (k *MapKeyCode) UnmarshalText(text []byte) error {
+	k.Code = strings.ToUpper(string(text))
+	return nil
+}
+
+func (k MapKeyCode) MarshalText() ([]byte, error) {
+	return []byte(strings.ToLower(k.Code)), nil
+}
+
+type TextStruct struct {
+	IP    net.IP                `nt:"ip"`
+	IPs   []net.IP              `nt:"ips"`
+	Big   *big.Int              `nt:"big"`
+	When  time.Time             `nt:"when"`
+	Codes map[MapKeyCode]string `nt:"codes"`
+}
+
+const TextMarshalerSample = `ip: 192.168.0.1
+ips:
+  - 10.0.0.1
+  - ::1
+big: 123456789012345678901234567890
+when: 2020-11-12T18:52:00Z
+codes:
+  jp: japan
+  us: united states
+`
+
+type MapStruct struct {
+	Labels   map[string]string              `nt:"labels"`
+	Services map[string]*MapService         `nt:"services"`
+	Ports    map[int]string                 `nt:"ports"`
+	Codes    map[MapKeyCode]string          `nt:"codes"`
+	Nested   map[string]map[string][]string `nt:"nested"`
+	Hosts    []map[string]string            `nt:"hosts"`
+}
+
+type CustomVersion struct {
+	Major int
+	Minor int
+}
+
+func (v *CustomVersion) UnmarshalNestedText(value *Value) error {
+	if value.Type != ValueTypeString {
+		return errors.New("version must be string")
+	}
+	_, err := fmt.Sscanf(value.String, "%d.%d", &v.Major, &v.Minor)
+	return err
+}
+
+func (v CustomVersion) MarshalNestedText() (*Value, error) {
+	if v.Major < 0 {
+		return nil, errors.New("negative major version")
+	}
+	return &Value{Type: ValueTypeString, String: fmt.Sprintf("%d.%d", v.Major, v.Minor)}, nil
+}
+
+type CustomSet map[string]bool
+
+func (s *CustomSet) UnmarshalNestedText(value *Value) error {
+	*s = CustomSet{}
+	for _, child := range value.List {
+		(*s)[child.String] = true
+	}
+	return nil
+}
+
+func (s CustomSet) MarshalNestedText() (*Value, error) {
+	value := &Value{Type: ValueTypeList}
+	for _, key := range []string{"a", "b", "c"} {
+		if s[key] {
+			value.List = append(value.List, &Value{Type: ValueTypeString, String: key})
+		}
+	}
+	return value, nil
+}
+
+type CustomStruct struct {
+	Version    CustomVersion             `nt:"version"`
+	VersionPtr *CustomVersion            `nt:"version_ptr"`
+	Versions   []CustomVersion           `nt:"versions"`
+	VersionMap map[string]*CustomVersion `nt:"version_map"`
+	Set        CustomSet                 `nt:"set"`
+	Sets       []CustomSet               `nt:"sets"`
+}
+
+const CustomSample = `version: 1.2
+version_ptr: 3.4
+versions:
+  - 5.6
+  - 7.8
+version_map:
+  stable: 9.10
+set:
+  - a
+  - c
+sets:
+  -
+    - b
+`
+
+type DynamicStruct struct {
+	Any     interface{}            `nt:"any"`
+	List    []interface{}          `nt:"list"`
+	Dict    map[string]interface{} `nt:"dict"`
+	Raw     *Value                 `nt:"raw"`
+	RawCopy Value                  `nt:"raw_copy"`
+}
+
+const DynamicSample = `any:
+  key: value
+list:
+  - str
+  -
+    - nested
+dict:
+  text:
+    > line 1
+    > line 2
+raw:
+  plugin: x
+raw_copy:
+  - a
+`
+
+type LosslessNumberStruct struct {
+	Int        int        `nt:"int"`
+	Int8       int8       `nt:"int8"`
+	Uint       uint       `nt:"uint"`
+	Uint64     uint64     `nt:"uint64"`
+	Float32    float32    `nt:"float32"`
+	Float64    float64    `nt:"float64"`
+	Complex64  complex64  `nt:"complex64"`
+	Complex128 complex128 `nt:"complex128"`
+	Bool       bool       `nt:"bool"`
+	Floats     []float64  `nt:"floats"`
+}
+
+type MergeServer struct {
+	Host string `nt:"host,required"`
+	Port int    `nt:"port,default=80"`
+}
+
+type MergeConfig struct {
+	Name     string                 `nt:"name"`
+	Server   MergeServer            `nt:"server"`
+	Fallback *MergeServer           `nt:"fallback"`
+	Labels   map[string]string      `nt:"labels"`
+	Backends map[string]MergeServer `nt:"backends"`
+	Tags     []string               `nt:"tags"`
+}
+
+func newMergeConfig() *MergeConfig {
+	return &MergeConfig{
+		Name:     "base",
+		Server:   MergeServer{Host: "localhost", Port: 8080},
+		Fallback: &MergeServer{Host: "fallback", Port: 8081},
+		Labels:   map[string]string{"env": "dev", "team": "core"},
+		Backends: map[string]MergeServer{"api": {Host: "api", Port: 9000}},
+		Tags:     []string{"a"},
+	}
+}
+
+const MergeOverride = `server:
+  port: 443
+fallback:
+  host: backup
+labels:
+  env: prod
+backends:
+  api:
+    port: 9443
+  web:
+    host: web
+tags:
+  - b
+`
+
+type ArrayPoint struct {
+	X int `nt:"x"`
+	Y int `nt:"y"`
+}
+
+type ArrayStruct struct {
+	Names  [3]string     `nt:"names"`
+	Points [2]ArrayPoint `nt:"points"`
+	Lines  [2]string     `nt:"lines,multilinestrings"`
+	Pairs  [][2]int      `nt:"pairs"`
+}
+
+const ArraySample = `names:
+  - a
+  - b
+  - c
+points:
+  -
+    x: 1
+    y: 2
+  -
+    x: 3
+    y: 4
+lines:
+  > line 1
+  > line 2
+pairs:
+  -
+    - 1
+    - 2
+`
+
+type UntaggedOfficer struct {
+	Name            string
+	Address         string
+	Email           string `nt:"email"`
+	AdditionalRoles []string
+	Phone           interface{} `nt:"-"`
+}
+
+type UntaggedContacts struct {
+	President     UntaggedOfficer
+	VicePresident *UntaggedOfficer
+	Treasurer     UntaggedOfficer
+}
+
+type EmbeddedScalar struct {
+	Int int `nt:"int"`
+}
+
+type EmbeddedService struct {
+	EmbeddedCommon
+	*EmbeddedConflictA
+	Port  string         `nt:"port"`
+	Limit EmbeddedScalar `nt:",inline"`
+}
+
 func TestMarshal(t *testing.T) {
 
 	subject := func() (*SampleStruct, error) {
@@ -240,6 +505,515 @@ func TestMarshal(t *testing.T) {
 		assert.Equal(t, "list string pointer bbbb", *s.ListOfStringPointer[1])
 	})
 
+	t.Run("parse error", func(t *testing.T) {
+		t.Run("should return error of Parse", func(t *testing.T) {
+			err := Marshal("string", &SampleStruct{})
+			assert.Equal(t, RootStringError, err)
+		})
+	})
+
+	t.Run("type mismatch", func(t *testing.T) {
+		t.Run("dictionary expected", func(t *testing.T) {
+			err := Marshal("string: hello\ndict: world", &SampleStruct{})
+
+			t.Run("should return DecodeError with paths and line", func(t *testing.T) {
+				decodeErr, ok := err.(*DecodeError)
+				assert.True(t, ok)
+				assert.Equal(t, "Dict", decodeErr.FieldPath)
+				assert.Equal(t, "dict", decodeErr.KeyPath)
+				assert.Equal(t, 2, decodeErr.Line)
+				assert.Equal(t, `ntgo: expected dictionary for struct field Dict, got string (key "dict", line 2)`, err.Error())
+			})
+		})
+
+		t.Run("nested in list", func(t *testing.T) {
+			content := `list_struct:
+  -
+    list_string: aaaa
+  -
+    list_string:
+      - bbbb`
+			err := Marshal(content, &SampleStruct{})
+
+			t.Run("should return DecodeError with nested paths", func(t *testing.T) {
+				decodeErr, ok := err.(*DecodeError)
+				assert.True(t, ok)
+				assert.Equal(t, "ListOfStruct[1].ListString", decodeErr.FieldPath)
+				assert.Equal(t, "list_struct[1].list_string", decodeErr.KeyPath)
+				assert.Equal(t, 5, decodeErr.Line)
+				assert.Equal(t, "string or text", decodeErr.Expected)
+				assert.Equal(t, ValueTypeList, decodeErr.Actual)
+			})
+		})
+
+		t.Run("empty value", func(t *testing.T) {
+			err := Marshal("dict:\nlist_struct:", &SampleStruct{})
+
+			t.Run("should be treated as zero value", func(t *testing.T) {
+				assert.Nil(t, err)
+			})
+		})
+	})
+
+	t.Run("number and boolean", func(t *testing.T) {
+		t.Run("round trip of Unmarshal", func(t *testing.T) {
+			var i int = 123456
+			var f float32 = 1.23456
+			expect := NumberStruct{
+				-123456,
+				-1.23456,
+				&i,
+				&f,
+				[]int{123, 456},
+				[]float32{1.23, 4.56},
+				[]*int{&i}, []*float32{&f},
+			}
+
+			t.Run("should restore numbers", func(t *testing.T) {
+				s := &NumberStruct{}
+				err := Marshal(Unmarshal(expect), s)
+				assert.Nil(t, err)
+				assert.Equal(t, expect, *s)
+			})
+		})
+
+		t.Run("round trip of nil pointers", func(t *testing.T) {
+			expect := ScalarStruct{Int8: 1, Bool: true, Bools: []*bool{}}
+
+			t.Run("should restore nil", func(t *testing.T) {
+				s := &ScalarStruct{}
+				err := Marshal(Unmarshal(expect), s)
+				assert.Nil(t, err)
+				assert.Nil(t, s.BoolPtr)
+				assert.Equal(t, int8(1), s.Int8)
+				assert.True(t, s.Bool)
+
+				n := &NumberStruct{}
+				err = Marshal(Unmarshal(NumberStruct{Int: 1}), n)
+				assert.Nil(t, err)
+				assert.Equal(t, 1, n.Int)
+				assert.Nil(t, n.IntPtr)
+				assert.Nil(t, n.Float32Ptr)
+			})
+		})
+
+		t.Run("empty values", func(t *testing.T) {
+			content := "int8:\nuint: 42\nfloat64:\nbool:\nbool_ptr:"
+
+			t.Run("should be zero values", func(t *testing.T) {
+				s := &ScalarStruct{Int8: 1, Float64: 1, Bool: true, BoolPtr: new(bool)}
+				err := Marshal(content, s)
+				assert.Nil(t, err)
+				assert.Equal(t, &ScalarStruct{Uint: 42}, s)
+			})
+		})
+
+		t.Run("various types", func(t *testing.T) {
+			content := `int8: -128
+uint: 42
+uint16:  65535
+float64: 1e-9
+bool: true
+bool_ptr: false
+uints:
+  - 1
+  - 255
+bools: TRUE
+ints:
+  -
+    - 1
+    - 2
+  -
+    - 3`
+
+			t.Run("should parse strings", func(t *testing.T) {
+				s := &ScalarStruct{}
+				err := Marshal(content, s)
+				assert.Nil(t, err)
+				assert.Equal(t, int8(-128), s.Int8)
+				assert.Equal(t, uint(42), s.Uint)
+				assert.Equal(t, uint16(65535), s.Uint16)
+				assert.Equal(t, 1e-9, s.Float64)
+				assert.True(t, s.Bool)
+				assert.False(t, *s.BoolPtr)
+				assert.Equal(t, []uint8{1, 255}, s.Uints)
+				assert.Equal(t, 1, len(s.Bools))
+				assert.True(t, *s.Bools[0])
+				assert.Equal(t, [][]int{{1, 2}, {3}}, s.Ints)
+			})
+		})
+
+		t.Run("overflow", func(t *testing.T) {
+			err := Marshal("uints:\n  - 1\n  - 256", &ScalarStruct{})
+
+			t.Run("should return DecodeError with strconv error", func(t *testing.T) {
+				decodeErr, ok := err.(*DecodeError)
+				assert.True(t, ok)
+				assert.Equal(t, "Uints[1]", decodeErr.FieldPath)
+				assert.Equal(t, 3, decodeErr.Line)
+				numErr, ok := decodeErr.Err.(*strconv.NumError)
+				assert.True(t, ok)
+				assert.Equal(t, strconv.ErrRange, numErr.Err)
+			})
+		})
+
+		t.Run("syntax error", func(t *testing.T) {
+			err := Marshal("int8: 1\nbool: yes", &ScalarStruct{})
+
+			t.Run("should return DecodeError with strconv error", func(t *testing.T) {
+				assert.Equal(t, `ntgo: struct field Bool: strconv.ParseBool: parsing "yes": invalid syntax (key "bool", line 2)`, err.Error())
+			})
+		})
+	})
+
+	t.Run("map", func(t *testing.T) {
+		content := `labels:
+  app: web
+  tier: front
+services:
+  web:
+    host: localhost
+    port: 80
+ports:
+  22: ssh
+codes:
+  jp: japan
+nested:
+  group:
+    members:
+      - a
+      - b
+hosts:
+  -
+    name: a
+  -
+    name: b`
+
+		t.Run("should decode dictionaries into maps", func(t *testing.T) {
+			s := &MapStruct{}
+			err := Marshal(content, s)
+			assert.Nil(t, err)
+			assert.Equal(t, map[string]string{"app": "web", "tier": "front"}, s.Labels)
+			assert.Equal(t, &MapService{Host: "localhost", Port: 80}, s.Services["web"])
+			assert.Equal(t, map[int]string{22: "ssh"}, s.Ports)
+			assert.Equal(t, map[MapKeyCode]string{{"JP"}: "japan"}, s.Codes)
+			assert.Equal(t, []string{"a", "b"}, s.Nested["group"]["members"])
+			assert.Equal(t, []map[string]string{{"name": "a"}, {"name": "b"}}, s.Hosts)
+		})
+
+		t.Run("element error", func(t *testing.T) {
+			err := Marshal("services:\n  web:\n    port: http", &MapStruct{})
+
+			t.Run("should return DecodeError with map key in paths", func(t *testing.T) {
+				decodeErr, ok := err.(*DecodeError)
+				assert.True(t, ok)
+				assert.Equal(t, `Services["web"].Port`, decodeErr.FieldPath)
+				assert.Equal(t, "services.web.port", decodeErr.KeyPath)
+				assert.Equal(t, 3, decodeErr.Line)
+			})
+		})
+
+		t.Run("key error", func(t *testing.T) {
+			err := Marshal("ports:\n  ssh: 22", &MapStruct{})
+
+			t.Run("should return DecodeError", func(t *testing.T) {
+				decodeErr, ok := err.(*DecodeError)
+				assert.True(t, ok)
+				assert.Equal(t, `Ports["ssh"]`, decodeErr.FieldPath)
+				assert.Equal(t, 2, decodeErr.Line)
+			})
+		})
+
+		t.Run("not dictionary", func(t *testing.T) {
+			err := Marshal("labels:\n  - a", &MapStruct{})
+
+			t.Run("should return DecodeError", func(t *testing.T) {
+				decodeErr, ok := err.(*DecodeError)
+				assert.True(t, ok)
+				assert.Equal(t, "dictionary", decodeErr.Expected)
+			})
+		})
+	})
+
+	t.Run("NestedTextUnmarshaler", func(t *testing.T) {
+		t.Run("should be used at every level", func(t *testing.T) {
+			s := &CustomStruct{}
+			err := Marshal(CustomSample, s)
+			assert.Nil(t, err)
+			assert.Equal(t, CustomVersion{1, 2}, s.Version)
+			assert.Equal(t, &CustomVersion{3, 4}, s.VersionPtr)
+			assert.Equal(t, []CustomVersion{{5, 6}, {7, 8}}, s.Versions)
+			assert.Equal(t, &CustomVersion{9, 10}, s.VersionMap["stable"])
+			assert.Equal(t, CustomSet{"a": true, "c": true}, s.Set)
+			assert.Equal(t, []CustomSet{{"b": true}}, s.Sets)
+		})
+
+		t.Run("should be used for root", func(t *testing.T) {
+			s := &CustomSet{}
+			err := Marshal("- a\n- b", s)
+			assert.Nil(t, err)
+			assert.Equal(t, CustomSet{"a": true, "b": true}, *s)
+		})
+
+		t.Run("error", func(t *testing.T) {
+			err := Marshal("versions:\n  - 1.0\n  -\n    - 2.0", &CustomStruct{})
+
+			t.Run("should be wrapped with DecodeError", func(t *testing.T) {
+				decodeErr, ok := err.(*DecodeError)
+				assert.True(t, ok)
+				assert.Equal(t, "Versions[1]", decodeErr.FieldPath)
+				assert.Equal(t, 3, decodeErr.Line)
+				assert.Equal(t, "version must be string", decodeErr.Err.Error())
+			})
+		})
+	})
+
+	t.Run("encoding.TextUnmarshaler", func(t *testing.T) {
+		t.Run("should be used for scalar values and map keys", func(t *testing.T) {
+			s := &TextStruct{}
+			err := Marshal(TextMarshalerSample, s)
+			assert.Nil(t, err)
+			assert.Equal(t, "192.168.0.1", s.IP.String())
+			assert.Equal(t, 2, len(s.IPs))
+			assert.Equal(t, "::1", s.IPs[1].String())
+			assert.Equal(t, "123456789012345678901234567890", s.Big.String())
+			assert.Equal(t, time.Date(2020, 11, 12, 18, 52, 0, 0, time.UTC), s.When)
+			assert.Equal(t, "united states", s.Codes[MapKeyCode{"US"}])
+		})
+
+		t.Run("error", func(t *testing.T) {
+			err := Marshal("ip: 192.168.0.1\nwhen: yesterday", &TextStruct{})
+
+			t.Run("should be wrapped with DecodeError", func(t *testing.T) {
+				decodeErr, ok := err.(*DecodeError)
+				assert.True(t, ok)
+				assert.Equal(t, "When", decodeErr.FieldPath)
+				assert.Equal(t, 2, decodeErr.Line)
+			})
+		})
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		t.Run("without Merge", func(t *testing.T) {
+			t.Run("should replace nested values", func(t *testing.T) {
+				s := newMergeConfig()
+				err := Marshal(MergeOverride, s)
+				assert.Equal(t, `ntgo: 2 missing key(s)
+	missing key "server.host" for field Host of ntgo.MergeServer (line 1)
+	missing key "backends.api.host" for field Host of ntgo.MergeServer (line 8)`, err.Error())
+				assert.Equal(t, "base", s.Name)
+				assert.Equal(t, MergeServer{Port: 443}, s.Server)
+				assert.Equal(t, map[string]string{"env": "prod"}, s.Labels)
+			})
+		})
+
+		t.Run("with Merge", func(t *testing.T) {
+			t.Run("should update existing values in place", func(t *testing.T) {
+				s := newMergeConfig()
+				fallback := s.Fallback
+				err := (&Marshaller{Merge: true}).Marshal(MergeOverride, s)
+				assert.Nil(t, err)
+				assert.Equal(t, "base", s.Name)
+				assert.Equal(t, MergeServer{Host: "localhost", Port: 443}, s.Server)
+				assert.Equal(t, &MergeServer{Host: "backup", Port: 8081}, s.Fallback)
+				assert.True(t, fallback == s.Fallback)
+				assert.Equal(t, map[string]string{"env": "prod", "team": "core"}, s.Labels)
+				assert.Equal(t, map[string]MergeServer{
+					"api": {Host: "api", Port: 9443},
+					"web": {Host: "web", Port: 80},
+				}, s.Backends)
+				assert.Equal(t, []string{"b"}, s.Tags)
+			})
+
+			t.Run("should apply default and required to zero values", func(t *testing.T) {
+				s := &MergeConfig{}
+				err := (&Marshaller{Merge: true}).Marshal("server:\n  host: localhost\nfallback:\n  port: 1", s)
+				assert.Equal(t, `ntgo: 1 missing key(s)
+	missing key "fallback.host" for field Host of ntgo.MergeServer (line 3)`, err.Error())
+				assert.Equal(t, 80, s.Server.Port)
+			})
+
+			t.Run("with SliceMergeAppend", func(t *testing.T) {
+				t.Run("should append elements", func(t *testing.T) {
+					s := newMergeConfig()
+					err := (&Marshaller{Merge: true, SliceMerge: SliceMergeAppend}).Marshal(MergeOverride, s)
+					assert.Nil(t, err)
+					assert.Equal(t, []string{"a", "b"}, s.Tags)
+				})
+			})
+		})
+	})
+
+	t.Run("array", func(t *testing.T) {
+		t.Run("should receive list elements", func(t *testing.T) {
+			s := &ArrayStruct{}
+			err := Marshal(ArraySample, s)
+			assert.Nil(t, err)
+			assert.Equal(t, [3]string{"a", "b", "c"}, s.Names)
+			assert.Equal(t, [2]ArrayPoint{{1, 2}, {3, 4}}, s.Points)
+			assert.Equal(t, [2]string{"line 1\n", "line 2"}, s.Lines)
+			assert.Equal(t, [][2]int{{1, 2}}, s.Pairs)
+		})
+
+		t.Run("length mismatch", func(t *testing.T) {
+			t.Run("should return DecodeError", func(t *testing.T) {
+				s := &ArrayStruct{}
+				err := Marshal("names:\n  - a\n  - b", s)
+				assert.Equal(t, `ntgo: struct field Names: expected 3 elements for [3]string, got 2 (key "names", line 1)`, err.Error())
+			})
+
+			t.Run("should return DecodeError with index for nested array", func(t *testing.T) {
+				s := &ArrayStruct{}
+				err := Marshal("pairs:\n  -\n    - 1\n    - 2\n  -\n    - 1\n    - 2\n    - 3", s)
+				decodeErr, ok := err.(*DecodeError)
+				assert.True(t, ok)
+				assert.Equal(t, "pairs[1]", decodeErr.KeyPath)
+			})
+		})
+
+		t.Run("empty value", func(t *testing.T) {
+			t.Run("should leave zero value", func(t *testing.T) {
+				s := &ArrayStruct{}
+				err := Marshal("names:", s)
+				assert.Nil(t, err)
+				assert.Equal(t, [3]string{}, s.Names)
+			})
+		})
+	})
+
+	t.Run("untagged fields", func(t *testing.T) {
+		t.Run("should receive keys matching field names case-insensitively", func(t *testing.T) {
+			s := &UntaggedOfficer{}
+			err := Marshal("name: smith\nADDRESS: Tokyo\nemail: a@example.com", s)
+			assert.Nil(t, err)
+			assert.Equal(t, "smith", s.Name)
+			assert.Equal(t, "Tokyo", s.Address)
+			assert.Equal(t, "a@example.com", s.Email)
+		})
+
+		t.Run("should prefer exact match", func(t *testing.T) {
+			s := &struct {
+				Name  string
+				Lower string `nt:"name"`
+			}{}
+			Marshal("Name: upper\nname: lower", s)
+			assert.Equal(t, "upper", s.Name)
+			assert.Equal(t, "lower", s.Lower)
+		})
+
+		t.Run("should ignore fields tagged with -", func(t *testing.T) {
+			s := &UntaggedOfficer{}
+			err := Marshal("phone: 1-210-555-5297", s)
+			assert.Nil(t, err)
+			assert.Nil(t, s.Phone)
+		})
+
+		t.Run("with NamingStrategy", func(t *testing.T) {
+			data, _ := ioutil.ReadFile("./sample/sample.nt")
+
+			t.Run("should receive keys derived from field names", func(t *testing.T) {
+				s := &UntaggedContacts{}
+				err := (&Marshaller{NamingStrategy: SpaceSeparated}).Marshal(string(data), s)
+				assert.Nil(t, err)
+				assert.Equal(t, "Katheryn McDaniel", s.President.Name)
+				assert.Equal(t, []string{"board member"}, s.President.AdditionalRoles)
+				assert.Equal(t, "margaret.hodge@uk.edu", s.VicePresident.Email)
+				assert.Equal(t, []string{"accounting task force"}, s.Treasurer.AdditionalRoles)
+			})
+		})
+	})
+
+	t.Run("embedded struct", func(t *testing.T) {
+		t.Run("should receive promoted fields", func(t *testing.T) {
+			s := &EmbeddedService{}
+			err := Marshal("name: api\nversion: 1\nlabel: main\nport: 80", s)
+			assert.Nil(t, err)
+			assert.Equal(t, "api", s.EmbeddedCommon.Name)
+			assert.Equal(t, "1", s.Version)
+			assert.Equal(t, "main", s.EmbeddedConflictA.Label)
+			assert.Equal(t, "80", s.Port)
+		})
+
+		t.Run("should receive fields of inline struct", func(t *testing.T) {
+			s := &EmbeddedService{}
+			err := Marshal("int: 10", s)
+			assert.Nil(t, err)
+			assert.Equal(t, 10, s.Limit.Int)
+		})
+
+		t.Run("should not allocate embedded pointer without keys", func(t *testing.T) {
+			s := &EmbeddedService{}
+			Marshal("port: 80", s)
+			assert.Nil(t, s.EmbeddedConflictA)
+		})
+
+		t.Run("should return DecodeError with path through embedded struct", func(t *testing.T) {
+			s := &struct {
+				EmbeddedScalar
+			}{}
+			err := Marshal("int: abc", s)
+			decodeErr, ok := err.(*DecodeError)
+			assert.True(t, ok)
+			assert.Equal(t, "EmbeddedScalar.Int", decodeErr.FieldPath)
+		})
+	})
+
+	t.Run("interface", func(t *testing.T) {
+		t.Run("should receive generic values", func(t *testing.T) {
+			s := &DynamicStruct{}
+			err := Marshal(DynamicSample, s)
+			assert.Nil(t, err)
+			assert.Equal(t, map[string]interface{}{"key": "value"}, s.Any)
+			assert.Equal(t, []interface{}{"str", []interface{}{"nested"}}, s.List)
+			assert.Equal(t, map[string]interface{}{"text": "line 1\nline 2"}, s.Dict)
+		})
+
+		t.Run("with InterfaceAsValue", func(t *testing.T) {
+			t.Run("should receive raw value", func(t *testing.T) {
+				s := &DynamicStruct{}
+				err := (&Marshaller{InterfaceAsValue: true}).Marshal(DynamicSample, s)
+				assert.Nil(t, err)
+				value, ok := s.Any.(*Value)
+				assert.True(t, ok)
+				assert.Equal(t, "value", value.Dictionary["key"].String)
+				assert.Equal(t, 2, value.Dictionary["key"].Line)
+				_, ok = s.List[1].(*Value)
+				assert.True(t, ok)
+			})
+		})
+
+		t.Run("root", func(t *testing.T) {
+			t.Run("should receive generic values", func(t *testing.T) {
+				var s map[string]interface{}
+				err := Marshal("a: b\nc:\n  - d", &s)
+				assert.Nil(t, err)
+				assert.Equal(t, map[string]interface{}{"a": "b", "c": []interface{}{"d"}}, s)
+			})
+		})
+
+		t.Run("interface with methods", func(t *testing.T) {
+			s := &struct {
+				Stringer fmt.Stringer `nt:"stringer"`
+			}{}
+
+			t.Run("should return DecodeError", func(t *testing.T) {
+				err := Marshal("stringer: a", s)
+				assert.Equal(t, `ntgo: struct field Stringer: unsupported type fmt.Stringer (key "stringer", line 1)`, err.Error())
+			})
+		})
+	})
+
+	t.Run("value", func(t *testing.T) {
+		t.Run("should receive parsed value", func(t *testing.T) {
+			s := &DynamicStruct{}
+			err := Marshal(DynamicSample, s)
+			assert.Nil(t, err)
+			assert.Equal(t, "x", s.Raw.Dictionary["plugin"].String)
+			assert.Equal(t, ValueTypeList, s.RawCopy.Type)
+			assert.Equal(t, "a", s.RawCopy.List[0].String)
+		})
+	})
+
 	t.Run("holistic", func(t *testing.T) {
 		HolisticSample := `
 string:
@@ -433,7 +1207,8 @@ list_string:
 list_string_pointer:
   - list of str ptr 1
   - list of str ptr 2
-not_omit_string: `
+not_omit_string:
+NoTag: `
 
 	t.Run("holistic", func(t *testing.T) {
 		s := subject()
@@ -493,8 +1268,267 @@ not_omit_string: `
 		})
 	})
 
+	t.Run("map", func(t *testing.T) {
+		s := MapStruct{
+			Labels: map[string]string{"tier": "front", "app": "web", "key: with colon": "quoted"},
+			Services: map[string]*MapService{
+				"web": &MapService{Host: "localhost", Port: 80},
+				"db":  &MapService{Host: "127.0.0.1", Port: 5432},
+			},
+			Ports: map[int]string{22: "ssh"},
+			Nested: map[string]map[string][]string{
+				"group": {"members": {"a", "b"}},
+			},
+			Hosts: []map[string]string{{"name": "a"}},
+		}
+
+		t.Run("should unmarshaled with sorted keys", func(t *testing.T) {
+			ret := Unmarshal(s)
+			assert.Equal(t, `labels:
+  app: web
+  "key: with colon": quoted
+  tier: front
+services:
+  db:
+    host: 127.0.0.1
+    port: 5432
+  web:
+    host: localhost
+    port: 80
+ports:
+  22: ssh
+codes:
+nested:
+  group:
+    members:
+      - a
+      - b
+hosts:
+  -
+    name: a
+`, ret)
+		})
+
+		t.Run("should be marshaled back", func(t *testing.T) {
+			another := &MapStruct{}
+			err := Marshal(Unmarshal(s), another)
+			assert.Nil(t, err)
+			assert.Equal(t, s, *another)
+		})
+	})
+
+	t.Run("NestedTextMarshaler", func(t *testing.T) {
+		s := CustomStruct{
+			Version:    CustomVersion{1, 2},
+			VersionPtr: &CustomVersion{3, 4},
+			Versions:   []CustomVersion{{5, 6}, {7, 8}},
+			VersionMap: map[string]*CustomVersion{"stable": {9, 10}},
+			Set:        CustomSet{"a": true, "c": true},
+			Sets:       []CustomSet{{"b": true}},
+		}
+
+		t.Run("should be used at every level", func(t *testing.T) {
+			ret, err := (&Marshaller{}).Unmarshal(s)
+			assert.Nil(t, err)
+			assert.Equal(t, CustomSample, ret)
+		})
+
+		t.Run("should not modify returned values", func(t *testing.T) {
+			raw := &Value{}
+			raw.Parse([]byte("a:\n  b: c"))
+
+			ret, err := (&Marshaller{}).Unmarshal(&DynamicStruct{Raw: raw})
+			assert.Nil(t, err)
+			assert.Contains(t, ret, "raw:\n  a:\n    b: c\n")
+			assert.Equal(t, "a:\n  b: c\n", raw.ToNestedText())
+		})
+
+		t.Run("error", func(t *testing.T) {
+			s.Versions[1].Major = -1
+
+			t.Run("should be returned as EncodeError", func(t *testing.T) {
+				_, err := (&Marshaller{}).Unmarshal(s)
+				encodeErr, ok := err.(*EncodeError)
+				assert.True(t, ok)
+				assert.Equal(t, "Versions[1]", encodeErr.FieldPath)
+				assert.Equal(t, "ntgo: struct field Versions[1]: negative major version", err.Error())
+			})
+		})
+	})
+
+	t.Run("encoding.TextMarshaler", func(t *testing.T) {
+		t.Run("should be used for scalar values and map keys", func(t *testing.T) {
+			s := &TextStruct{}
+			Marshal(TextMarshalerSample, s)
+
+			ret, err := (&Marshaller{}).Unmarshal(s)
+			assert.Nil(t, err)
+			assert.Equal(t, TextMarshalerSample, ret)
+		})
+
+		t.Run("empty text and nil pointer", func(t *testing.T) {
+			type EmptyText struct {
+				IP   net.IP     `nt:"ip"`
+				When *time.Time `nt:"when"`
+				Name string     `nt:"name"`
+			}
+			s := EmptyText{Name: "a"}
+
+			t.Run("should be written as empty value", func(t *testing.T) {
+				ret, err := (&Marshaller{}).Unmarshal(s)
+				assert.Nil(t, err)
+				assert.True(t, strings.HasPrefix(ret, "ip:\n"))
+			})
+
+			t.Run("should be decoded back", func(t *testing.T) {
+				decoded := &EmptyText{When: &time.Time{}}
+				err := Marshal(Unmarshal(s), decoded)
+				assert.Nil(t, err)
+				assert.Equal(t, &s, decoded)
+			})
+		})
+	})
+
+	t.Run("numbers and booleans", func(t *testing.T) {
+		t.Run("should be written in the shortest form", func(t *testing.T) {
+			s := LosslessNumberStruct{
+				Int:        -1,
+				Int8:       127,
+				Uint:       1,
+				Uint64:     math.MaxUint64,
+				Float32:    0.1,
+				Float64:    1e-9,
+				Complex64:  complex(1, -0.5),
+				Complex128: complex(1e20, 0),
+				Bool:       true,
+				Floats:     []float64{1.5, 1e20},
+			}
+			ret, err := (&Marshaller{}).Unmarshal(s)
+			assert.Nil(t, err)
+			assert.Equal(t, `int: -1
+int8: 127
+uint: 1
+uint64: 18446744073709551615
+float32: 0.1
+float64: 1e-09
+complex64: (1-0.5i)
+complex128: (1e+20+0i)
+bool: true
+floats:
+  - 1.5
+  - 1e+20
+`, ret)
+		})
+
+		t.Run("with format", func(t *testing.T) {
+			s := struct {
+				Ratio  float64   `nt:"ratio,format=%.2f"`
+				Padded uint      `nt:"padded,format=%04d"`
+				Ratios []float32 `nt:"ratios,format=%.1f"`
+			}{0.125, 255, []float32{1, 2}}
+
+			t.Run("should be written with format", func(t *testing.T) {
+				ret, err := (&Marshaller{}).Unmarshal(s)
+				assert.Nil(t, err)
+				assert.Equal(t, "ratio: 0.12\npadded: 0255\nratios:\n  - 1.0\n  - 2.0\n", ret)
+			})
+
+			t.Run("should be rejected when written values can not be decoded", func(t *testing.T) {
+				_, err := (&Marshaller{}).Unmarshal(struct {
+					Hex uint `nt:"hex,format=%#x"`
+				}{255})
+				assert.IsType(t, &TagError{}, err)
+			})
+		})
+
+		t.Run("pointer", func(t *testing.T) {
+			b := false
+			s := struct {
+				Bool *bool `nt:"bool"`
+			}{&b}
+
+			t.Run("should be written on the same line", func(t *testing.T) {
+				assert.Equal(t, "bool: false\n", Unmarshal(s))
+			})
+		})
+	})
+
+	t.Run("array", func(t *testing.T) {
+		s := ArrayStruct{
+			Names:  [3]string{"a", "b", "c"},
+			Points: [2]ArrayPoint{{1, 2}, {3, 4}},
+			Lines:  [2]string{"line 1", "line 2"},
+			Pairs:  [][2]int{{1, 2}},
+		}
+
+		t.Run("should be written as list", func(t *testing.T) {
+			ret, err := (&Marshaller{}).Unmarshal(s)
+			assert.Nil(t, err)
+			assert.Equal(t, ArraySample, ret)
+		})
+	})
+
+	t.Run("untagged fields", func(t *testing.T) {
+		s := UntaggedOfficer{Name: "smith", Email: "a@example.com", AdditionalRoles: []string{"board member"}, Phone: "1"}
+
+		t.Run("should be written with field names", func(t *testing.T) {
+			ret, err := (&Marshaller{}).Unmarshal(s)
+			assert.Nil(t, err)
+			assert.Equal(t, "Name: smith\nAddress:\nemail: a@example.com\nAdditionalRoles:\n  - board member\n", ret)
+		})
+
+		t.Run("with NamingStrategy", func(t *testing.T) {
+			t.Run("should be written with keys derived from field names", func(t *testing.T) {
+				ret, err := (&Marshaller{NamingStrategy: KebabCase}).Unmarshal(s)
+				assert.Nil(t, err)
+				assert.Equal(t, "name: smith\naddress:\nemail: a@example.com\nadditional-roles:\n  - board member\n", ret)
+			})
+		})
+	})
+
+	t.Run("embedded struct", func(t *testing.T) {
+		t.Run("should write promoted fields in order of declaration", func(t *testing.T) {
+			s := EmbeddedService{
+				EmbeddedCommon:    EmbeddedCommon{Name: "api", Version: "1"},
+				EmbeddedConflictA: &EmbeddedConflictA{Label: "main"},
+				Port:              "80",
+			}
+			ret, err := (&Marshaller{}).Unmarshal(s)
+			assert.Nil(t, err)
+			assert.Equal(t, "name: api\nversion: 1\nlabel: main\nport: 80\nint: 0\n", ret)
+		})
+
+		t.Run("should skip fields of nil embedded pointer", func(t *testing.T) {
+			s := EmbeddedService{EmbeddedCommon: EmbeddedCommon{Name: "api", Version: "1"}, Port: "80"}
+			ret, err := (&Marshaller{}).Unmarshal(s)
+			assert.Nil(t, err)
+			assert.Equal(t, "name: api\nversion: 1\nport: 80\nint: 0\n", ret)
+		})
+	})
+
+	t.Run("interface and value", func(t *testing.T) {
+		t.Run("should be unmarshaled by dynamic type", func(t *testing.T) {
+			s := &DynamicStruct{}
+			Marshal(DynamicSample, s)
+
+			ret, err := (&Marshaller{}).Unmarshal(s)
+			assert.Nil(t, err)
+			assert.Equal(t, DynamicSample, ret)
+		})
+
+		t.Run("nil element", func(t *testing.T) {
+			s := DynamicStruct{List: []interface{}{nil, 1, nil}}
+
+			t.Run("should be written as empty element", func(t *testing.T) {
+				ret, err := (&Marshaller{}).Unmarshal(s)
+				assert.Nil(t, err)
+				assert.Equal(t, "any:\nlist:\n  -\n  - 1\n  -\n", strings.Split(ret, "dict:")[0])
+			})
+		})
+	})
+
 	t.Run("unsupported type of field", func(t *testing.T) {
-		s := UnsupportedStruct{true}
+		s := UnsupportedStruct{make(chan int)}
 
 		t.Run("should return empty value", func(t *testing.T) {
 			ret := Unmarshal(s)
@@ -503,6 +1537,40 @@ not_omit_string: `
 	})
 }
 
+func TestNumberRoundTrip(t *testing.T) {
+	roundTrip := func(s LosslessNumberStruct) bool {
+		content, err := (&Marshaller{}).Unmarshal(s)
+		if err != nil {
+			return false
+		}
+		decoded := LosslessNumberStruct{}
+		if err := Marshal(content, &decoded); err != nil {
+			return false
+		}
+		if len(s.Floats) == 0 {
+			// empty slice is written as empty value
+			decoded.Floats = s.Floats
+		}
+		return reflect.DeepEqual(s, decoded)
+	}
+
+	t.Run("should decode encoded numbers into the same values", func(t *testing.T) {
+		assert.Nil(t, quick.Check(roundTrip, nil))
+	})
+
+	t.Run("should keep boundary values", func(t *testing.T) {
+		assert.True(t, roundTrip(LosslessNumberStruct{
+			Int:        math.MinInt64,
+			Int8:       math.MinInt8,
+			Uint64:     math.MaxUint64,
+			Float32:    math.SmallestNonzeroFloat32,
+			Float64:    math.MaxFloat64,
+			Complex128: complex(math.SmallestNonzeroFloat64, -math.MaxFloat64),
+			Floats:     []float64{math.Inf(1), math.Inf(-1), -0.0},
+		}))
+	})
+}
+
 func TestMarshalSlice(t *testing.T) {
 	var value *Value
 	var sliceType reflect.Type
@@ -512,7 +1580,7 @@ func TestMarshalSlice(t *testing.T) {
 	condition := func() {}
 
 	subject := func() interface{} {
-		marshalSlice(value, elementType, elementRef)
+		(&Marshaller{}).marshalSlice(value, elementType, elementRef)
 		return elementRef.Interface()
 	}
 
@@ -0,0 +1,64 @@
+package ntgo
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NamingStrategy derives a dictionary key from the name of struct field without nt tag.
+// Keys derived by predefined strategies are cached per type, and other strategies are called whenever structs are encoded or decoded.
+type NamingStrategy func(fieldName string) string
+
+var (
+	// SnakeCase maps UserID to user_id.
+	SnakeCase NamingStrategy = func(fieldName string) string {
+		return strings.Join(splitFieldName(fieldName), "_")
+	}
+	// KebabCase maps UserID to user-id.
+	KebabCase NamingStrategy = func(fieldName string) string {
+		return strings.Join(splitFieldName(fieldName), "-")
+	}
+	// SpaceSeparated maps AdditionalRoles to "additional roles".
+	SpaceSeparated NamingStrategy = func(fieldName string) string {
+		return strings.Join(splitFieldName(fieldName), " ")
+	}
+)
+
+// splitFieldName splits camel case name into lower case words.
+// Sequence of upper case letters is treated as an acronym, e.g. HTTPServer becomes "http" and "server".
+// Digits belong to the preceding word.
+func splitFieldName(name string) []string {
+	runes := []rune(name)
+	words := []string{}
+	begin := 0
+
+	for i := 1; i < len(runes); i++ {
+		prev := runes[i-1]
+		current := runes[i]
+
+		boundary := false
+		switch {
+		case current == '_':
+			boundary = true
+		case unicode.IsUpper(current) && (unicode.IsLower(prev) || unicode.IsDigit(prev)):
+			boundary = true
+		case unicode.IsUpper(current) && unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1]):
+			boundary = true
+		}
+
+		if !boundary {
+			continue
+		}
+
+		if word := strings.Trim(string(runes[begin:i]), "_"); word != "" {
+			words = append(words, strings.ToLower(word))
+		}
+		begin = i
+	}
+
+	if word := strings.Trim(string(runes[begin:]), "_"); word != "" {
+		words = append(words, strings.ToLower(word))
+	}
+
+	return words
+}
@@ -0,0 +1,45 @@
+package ntgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamingStrategy(t *testing.T) {
+	t.Run("SnakeCase", func(t *testing.T) {
+		t.Run("should join lower case words with underscore", func(t *testing.T) {
+			assert.Equal(t, "additional_roles", SnakeCase("AdditionalRoles"))
+		})
+	})
+
+	t.Run("KebabCase", func(t *testing.T) {
+		t.Run("should join lower case words with hyphen", func(t *testing.T) {
+			assert.Equal(t, "additional-roles", KebabCase("AdditionalRoles"))
+		})
+	})
+
+	t.Run("SpaceSeparated", func(t *testing.T) {
+		t.Run("should join lower case words with space", func(t *testing.T) {
+			assert.Equal(t, "additional roles", SpaceSeparated("AdditionalRoles"))
+		})
+	})
+}
+
+func TestSplitFieldName(t *testing.T) {
+	t.Run("should split camel case", func(t *testing.T) {
+		assert.Equal(t, []string{"vice", "president"}, splitFieldName("VicePresident"))
+	})
+	t.Run("should treat upper case sequence as acronym", func(t *testing.T) {
+		assert.Equal(t, []string{"http", "server", "id"}, splitFieldName("HTTPServerID"))
+	})
+	t.Run("should keep digits in the preceding word", func(t *testing.T) {
+		assert.Equal(t, []string{"version2", "name"}, splitFieldName("Version2Name"))
+	})
+	t.Run("should split at underscore", func(t *testing.T) {
+		assert.Equal(t, []string{"user", "name"}, splitFieldName("User_Name"))
+	})
+	t.Run("should keep single word", func(t *testing.T) {
+		assert.Equal(t, []string{"name"}, splitFieldName("Name"))
+	})
+}
@@ -0,0 +1,107 @@
+package ntgo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Paths point elements in a tree with keys joined by dots and list indexes in brackets,
+// e.g. "president.additional roles[0]".
+
+func appendKeyPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func appendIndexPath(path string, index int) string {
+	return path + "[" + strconv.Itoa(index) + "]"
+}
+
+// prependPath places segment, a key or an index in brackets, in front of path.
+func prependPath(segment string, path string) string {
+	if path == "" || strings.HasPrefix(path, "[") {
+		return segment + path
+	}
+	return segment + "." + path
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "root"
+	}
+	return strconv.Quote(path)
+}
+
+// pathSegment is a dictionary key or a list index in path.
+type pathSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// splitPath parses path into segments, empty path points the root.
+func splitPath(path string) ([]pathSegment, error) {
+	segments := []pathSegment{}
+	key := ""
+	afterIndex := false
+
+	for i := 0; i < len(path); i++ {
+		switch path[i] {
+		case '.':
+			if key == "" && !afterIndex {
+				return nil, fmt.Errorf("empty key in path %q", path)
+			}
+			if key != "" {
+				segments = append(segments, pathSegment{key: key})
+			}
+			key = ""
+			afterIndex = false
+		case '[':
+			if key != "" {
+				segments = append(segments, pathSegment{key: key})
+				key = ""
+			}
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated index in path %q", path)
+			}
+			index, err := strconv.Atoi(path[i+1 : i+end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid index in path %q", path)
+			}
+			segments = append(segments, pathSegment{index: index, isIndex: true})
+			i += end
+			afterIndex = true
+		default:
+			if afterIndex {
+				return nil, fmt.Errorf("expected . or [ after index in path %q", path)
+			}
+			key += string(path[i])
+		}
+	}
+
+	if key != "" {
+		segments = append(segments, pathSegment{key: key})
+	} else if len(path) > 0 && !afterIndex {
+		return nil, fmt.Errorf("empty key in path %q", path)
+	}
+	return segments, nil
+}
+
+// lookupPath returns the value pointed by segments from value, nil when it does not exist.
+func lookupPath(value *Value, segments []pathSegment) *Value {
+	for _, segment := range segments {
+		switch {
+		case segment.isIndex && value.Type == ValueTypeList && segment.index < len(value.List):
+			value = value.List[segment.index]
+		case !segment.isIndex && value.Type == ValueTypeDictionary && value.Dictionary[segment.key] != nil:
+			value = value.Dictionary[segment.key]
+		default:
+			return nil
+		}
+	}
+	return value
+}
@@ -0,0 +1,32 @@
+package ntgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitPath(t *testing.T) {
+	t.Run("should split keys and indexes", func(t *testing.T) {
+		segments, err := splitPath("president.additional roles[0]")
+		assert.Nil(t, err)
+		assert.Equal(t, []pathSegment{{key: "president"}, {key: "additional roles"}, {index: 0, isIndex: true}}, segments)
+
+		segments, err = splitPath("[1][2].key")
+		assert.Nil(t, err)
+		assert.Equal(t, []pathSegment{{index: 1, isIndex: true}, {index: 2, isIndex: true}, {key: "key"}}, segments)
+	})
+
+	t.Run("empty path should point the root", func(t *testing.T) {
+		segments, err := splitPath("")
+		assert.Nil(t, err)
+		assert.Empty(t, segments)
+	})
+
+	t.Run("malformed path should be an error", func(t *testing.T) {
+		for _, path := range []string{"a..b", "a.", "a[", "a[x]", "a[-1]", "a[0]b"} {
+			_, err := splitPath(path)
+			assert.NotNil(t, err, path)
+		}
+	})
+}
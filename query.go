@@ -0,0 +1,302 @@
+package ntgo
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Query selects values in a tree, see ParseQuery.
+type Query struct {
+	expression string
+	steps      []queryStep
+}
+
+type queryStepKind int
+
+const (
+	queryStepKey queryStepKind = iota
+	queryStepWildcard
+	queryStepIndex
+	queryStepSlice
+)
+
+// queryStep selects children of values, or of values and their descendants when recursive.
+type queryStep struct {
+	kind      queryStepKind
+	recursive bool
+	key       string
+	// index is the index or the start of slice, counted from the end when negative
+	index int
+	// end is the end of slice
+	end      int
+	hasIndex bool
+	hasEnd   bool
+}
+
+// QueryMatch is a value selected by Query.
+type QueryMatch struct {
+	KeyPath string
+	Value   *Value
+}
+
+// ParseQuery parses expression selecting values in a tree, e.g.
+//
+//	president.phone.cell    value of keys
+//	officers[0]             list element, negative index counts from the end
+//	officers[1:3]           list elements in the range, either of bounds can be omitted
+//	officers[*].email       * matches any key or element
+//	..email                 .. matches the value and all of its descendants
+//	["key.with.dots"]       quoted key
+//
+// Empty expression or "." selects the root.
+func ParseQuery(expression string) (*Query, error) {
+	q := &Query{expression: expression}
+	if expression == "." {
+		return q, nil
+	}
+
+	fail := func(format string, args ...interface{}) (*Query, error) {
+		return nil, fmt.Errorf("ntgo: invalid query %q: %s", expression, fmt.Sprintf(format, args...))
+	}
+
+	for i := 0; i < len(expression); {
+		step := queryStep{}
+		switch {
+		case strings.HasPrefix(expression[i:], ".."):
+			step.recursive = true
+			i += 2
+		case expression[i] == '.':
+			if len(q.steps) == 0 {
+				return fail("unexpected . at %d", i)
+			}
+			i++
+		case expression[i] != '[' && len(q.steps) > 0:
+			return fail("expected . or [ at %d", i)
+		}
+		if i >= len(expression) {
+			return fail("missing key at the end")
+		}
+
+		if expression[i] != '[' {
+			end := strings.IndexAny(expression[i:], ".[")
+			if end < 0 {
+				end = len(expression) - i
+			}
+			step.key = expression[i : i+end]
+			switch step.key {
+			case "":
+				return fail("empty key at %d", i)
+			case "*":
+				step.kind = queryStepWildcard
+			}
+			q.steps = append(q.steps, step)
+			i += end
+			continue
+		}
+
+		consumed, err := step.parseBracket(expression[i:])
+		if err != nil {
+			return fail("%v at %d", err, i)
+		}
+		q.steps = append(q.steps, step)
+		i += consumed
+	}
+	return q, nil
+}
+
+// parseBracket reads a quoted key, an index, a slice or a wildcard in brackets and returns the length of them.
+func (s *queryStep) parseBracket(expression string) (int, error) {
+	if strings.HasPrefix(expression, `["`) {
+		for i := 2; i < len(expression); i++ {
+			switch expression[i] {
+			case '\\':
+				i++
+			case '"':
+				key, err := strconv.Unquote(expression[1 : i+1])
+				if err != nil {
+					return 0, fmt.Errorf("invalid quoted key")
+				}
+				if !strings.HasPrefix(expression[i+1:], "]") {
+					return 0, fmt.Errorf("expected ] after quoted key")
+				}
+				s.key = key
+				return i + 2, nil
+			}
+		}
+		return 0, fmt.Errorf("unterminated quoted key")
+	}
+
+	end := strings.IndexByte(expression, ']')
+	if end < 0 {
+		return 0, fmt.Errorf("unterminated [")
+	}
+	content := strings.TrimSpace(expression[1:end])
+
+	if content == "*" {
+		s.kind = queryStepWildcard
+		return end + 1, nil
+	}
+
+	bounds := strings.Split(content, ":")
+	if len(bounds) > 2 {
+		return 0, fmt.Errorf("invalid slice %q", content)
+	}
+	for i, bound := range bounds {
+		bound = strings.TrimSpace(bound)
+		if bound == "" {
+			if len(bounds) == 1 {
+				return 0, fmt.Errorf("empty index")
+			}
+			continue
+		}
+		number, err := strconv.Atoi(bound)
+		if err != nil {
+			return 0, fmt.Errorf("invalid index %q", bound)
+		}
+		if i == 0 {
+			s.index = number
+			s.hasIndex = true
+		} else {
+			s.end = number
+			s.hasEnd = true
+		}
+	}
+
+	s.kind = queryStepIndex
+	if len(bounds) == 2 {
+		s.kind = queryStepSlice
+	}
+	return end + 1, nil
+}
+
+// String returns the expression of q.
+func (q *Query) String() string {
+	return q.expression
+}
+
+// Find returns values selected by q from value in order of appearance.
+// Steps not applicable to the type of values select nothing.
+func (q *Query) Find(value *Value) []*QueryMatch {
+	// order is the position of values in depth-first order to sort descendants
+	var order map[*Value]int
+	matches := []*QueryMatch{{Value: value}}
+	for _, step := range q.steps {
+		next := []*QueryMatch{}
+		found := map[*Value]bool{}
+		for _, match := range matches {
+			candidates := []*QueryMatch{match}
+			if step.recursive {
+				candidates = appendDescendants(candidates, match)
+			}
+			for _, candidate := range candidates {
+				for _, selected := range step.selectFrom(candidate) {
+					if !found[selected.Value] {
+						found[selected.Value] = true
+						next = append(next, selected)
+					}
+				}
+			}
+		}
+		if step.recursive {
+			if order == nil {
+				order = map[*Value]int{}
+				for i, match := range appendDescendants([]*QueryMatch{{Value: value}}, &QueryMatch{Value: value}) {
+					order[match.Value] = i
+				}
+			}
+			sort.SliceStable(next, func(i, j int) bool { return order[next[i].Value] < order[next[j].Value] })
+		}
+		matches = next
+	}
+	return matches
+}
+
+// Query returns values selected by expression from v, see ParseQuery.
+func (v *Value) Query(expression string) ([]*QueryMatch, error) {
+	q, err := ParseQuery(expression)
+	if err != nil {
+		return nil, err
+	}
+	return q.Find(v), nil
+}
+
+// appendDescendants appends descendants of match in depth-first order.
+func appendDescendants(matches []*QueryMatch, match *QueryMatch) []*QueryMatch {
+	for _, child := range queryChildren(match) {
+		matches = append(matches, child)
+		matches = appendDescendants(matches, child)
+	}
+	return matches
+}
+
+// queryChildren returns elements of list or values of dictionary in order of appearance.
+func queryChildren(match *QueryMatch) []*QueryMatch {
+	result := []*QueryMatch{}
+	switch match.Value.Type {
+	case ValueTypeList:
+		for i, child := range match.Value.List {
+			result = append(result, &QueryMatch{KeyPath: appendIndexPath(match.KeyPath, i), Value: child})
+		}
+	case ValueTypeDictionary:
+		for _, key := range match.Value.Keys() {
+			result = append(result, &QueryMatch{KeyPath: appendKeyPath(match.KeyPath, key), Value: match.Value.Dictionary[key]})
+		}
+	}
+	return result
+}
+
+func (s *queryStep) selectFrom(match *QueryMatch) []*QueryMatch {
+	value := match.Value
+	switch s.kind {
+	case queryStepWildcard:
+		return queryChildren(match)
+	case queryStepKey:
+		if child, ok := value.Dictionary[s.key]; ok && value.Type == ValueTypeDictionary {
+			return []*QueryMatch{{KeyPath: appendKeyPath(match.KeyPath, s.key), Value: child}}
+		}
+	case queryStepIndex:
+		if value.Type != ValueTypeList {
+			return nil
+		}
+		index := s.index
+		if index < 0 {
+			index += len(value.List)
+		}
+		if index >= 0 && index < len(value.List) {
+			return []*QueryMatch{{KeyPath: appendIndexPath(match.KeyPath, index), Value: value.List[index]}}
+		}
+	case queryStepSlice:
+		if value.Type != ValueTypeList {
+			return nil
+		}
+		begin, end := 0, len(value.List)
+		if s.hasIndex {
+			begin = clampIndex(s.index, len(value.List))
+		}
+		if s.hasEnd {
+			end = clampIndex(s.end, len(value.List))
+		}
+		result := []*QueryMatch{}
+		for i := begin; i < end; i++ {
+			result = append(result, &QueryMatch{KeyPath: appendIndexPath(match.KeyPath, i), Value: value.List[i]})
+		}
+		return result
+	}
+	return nil
+}
+
+// clampIndex converts index of a slice bound counted from the end into the range of length.
+func clampIndex(index int, length int) int {
+	if index < 0 {
+		index += length
+	}
+	if index < 0 {
+		return 0
+	}
+	if index > length {
+		return length
+	}
+	return index
+}
@@ -0,0 +1,153 @@
+package ntgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery(t *testing.T) {
+	content := `president:
+  name: Katheryn McDaniel
+  phone:
+    cell: 1-210-555-5297
+    home: 1-210-555-8470
+  email: KateMcD@aol.com
+officers:
+  -
+    name: Margaret Hodge
+    email: margaret@example.com
+  -
+    name: Fumiko Purvis
+    additional roles:
+      - accounts
+  -
+    name: Merrill Eldridge
+    email: merrill@example.com
+key.with.dots: dotted`
+
+	subject := func(expression string) ([]string, []string) {
+		value := &Value{}
+		value.Parse([]byte(content))
+		matches, err := value.Query(expression)
+		assert.Nil(t, err, expression)
+		paths := []string{}
+		strs := []string{}
+		for _, match := range matches {
+			paths = append(paths, match.KeyPath)
+			strs = append(strs, match.Value.String)
+		}
+		return paths, strs
+	}
+
+	t.Run("key path", func(t *testing.T) {
+		t.Run("should select the value", func(t *testing.T) {
+			paths, strs := subject("president.phone.cell")
+			assert.Equal(t, []string{"president.phone.cell"}, paths)
+			assert.Equal(t, []string{"1-210-555-5297"}, strs)
+		})
+
+		t.Run("should select nothing when it does not exist", func(t *testing.T) {
+			paths, _ := subject("president.phone.office")
+			assert.Equal(t, []string{}, paths)
+			paths, _ = subject("president.name.first")
+			assert.Equal(t, []string{}, paths)
+		})
+
+		t.Run("should accept quoted keys", func(t *testing.T) {
+			_, strs := subject(`["key.with.dots"]`)
+			assert.Equal(t, []string{"dotted"}, strs)
+			_, strs = subject(`officers[1]["additional roles"][0]`)
+			assert.Equal(t, []string{"accounts"}, strs)
+		})
+
+		t.Run("should accept keys with spaces", func(t *testing.T) {
+			_, strs := subject("officers[1].additional roles[0]")
+			assert.Equal(t, []string{"accounts"}, strs)
+		})
+	})
+
+	t.Run("root", func(t *testing.T) {
+		t.Run("should be selected by empty expression and dot", func(t *testing.T) {
+			paths, _ := subject("")
+			assert.Equal(t, []string{""}, paths)
+			paths, _ = subject(".")
+			assert.Equal(t, []string{""}, paths)
+		})
+	})
+
+	t.Run("index", func(t *testing.T) {
+		t.Run("should select the element", func(t *testing.T) {
+			paths, _ := subject("officers[2].name")
+			assert.Equal(t, []string{"officers[2].name"}, paths)
+		})
+
+		t.Run("negative index should count from the end", func(t *testing.T) {
+			_, strs := subject("officers[-1].name")
+			assert.Equal(t, []string{"Merrill Eldridge"}, strs)
+		})
+
+		t.Run("out of range should select nothing", func(t *testing.T) {
+			paths, _ := subject("officers[3]")
+			assert.Equal(t, []string{}, paths)
+			paths, _ = subject("officers[-4]")
+			assert.Equal(t, []string{}, paths)
+		})
+	})
+
+	t.Run("slice", func(t *testing.T) {
+		t.Run("should select elements in the range", func(t *testing.T) {
+			_, strs := subject("officers[1:].name")
+			assert.Equal(t, []string{"Fumiko Purvis", "Merrill Eldridge"}, strs)
+			_, strs = subject("officers[:-1].name")
+			assert.Equal(t, []string{"Margaret Hodge", "Fumiko Purvis"}, strs)
+			_, strs = subject("officers[-10:10].name")
+			assert.Equal(t, []string{"Margaret Hodge", "Fumiko Purvis", "Merrill Eldridge"}, strs)
+			paths, _ := subject("officers[2:1]")
+			assert.Equal(t, []string{}, paths)
+		})
+	})
+
+	t.Run("wildcard", func(t *testing.T) {
+		t.Run("should select all of elements and values", func(t *testing.T) {
+			paths, _ := subject("officers[*].email")
+			assert.Equal(t, []string{"officers[0].email", "officers[2].email"}, paths)
+			paths, _ = subject("officers.*.name")
+			assert.Equal(t, []string{"officers[0].name", "officers[1].name", "officers[2].name"}, paths)
+			_, strs := subject("president.phone.*")
+			assert.Equal(t, []string{"1-210-555-5297", "1-210-555-8470"}, strs)
+		})
+	})
+
+	t.Run("recursive descent", func(t *testing.T) {
+		t.Run("should select descendants in order of appearance", func(t *testing.T) {
+			paths, _ := subject("..email")
+			assert.Equal(t, []string{"president.email", "officers[0].email", "officers[2].email"}, paths)
+			paths, _ = subject("president..*")
+			assert.Equal(t, []string{"president.name", "president.phone", "president.phone.cell", "president.phone.home", "president.email"}, paths)
+			paths, _ = subject("..[0]")
+			assert.Equal(t, []string{"officers[0]", "officers[1].additional roles[0]"}, paths)
+		})
+
+		t.Run("should not select the same value twice", func(t *testing.T) {
+			paths, _ := subject("..phone..cell")
+			assert.Equal(t, []string{"president.phone.cell"}, paths)
+			paths, _ = subject("..*..cell")
+			assert.Equal(t, []string{"president.phone.cell"}, paths)
+		})
+	})
+
+	t.Run("malformed expression", func(t *testing.T) {
+		t.Run("should be an error", func(t *testing.T) {
+			for _, expression := range []string{"a.", "a..", ".a", "a..[", "a[", "a[]", "a[x]", "a[1:2:3]", `a["b`, `a["b"`, `a["b"]c`, "a[0]b", "a.b..", "a...b", "....a"} {
+				_, err := ParseQuery(expression)
+				assert.NotNil(t, err, expression)
+			}
+		})
+
+		t.Run("should be described with the position", func(t *testing.T) {
+			_, err := ParseQuery("a[0]b")
+			assert.Equal(t, `ntgo: invalid query "a[0]b": expected . or [ at 4`, err.Error())
+		})
+	})
+}
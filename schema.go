@@ -0,0 +1,335 @@
+package ntgo
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Schema describes the expected structure of a value.
+// Zero value accepts any value.
+type Schema struct {
+	// Types accepted for the value, any type is accepted when empty
+	Types []ValueType
+	// Optional allows the key to be absent when the schema is in Keys of a dictionary
+	Optional bool
+
+	// Pattern is a regular expression that strings and texts must match entirely
+	Pattern string
+	// Enum lists allowed strings and texts
+	Enum []string
+	// Minimum and Maximum bound strings and texts as numbers, they must be numbers when either is set
+	Minimum *float64
+	Maximum *float64
+
+	// MinItems and MaxItems bound the number of list elements, MaxItems is not checked when it is 0
+	MinItems int
+	MaxItems int
+	// Items is the schema of list elements
+	Items *Schema
+
+	// Keys are schemas of values of dictionary keys
+	Keys map[string]*Schema
+	// AdditionalKeys is the schema of values of keys not listed in Keys.
+	// Unknown keys are rejected when it is nil and Keys is not empty.
+	AdditionalKeys *Schema
+}
+
+// SchemaViolation describes a value that does not satisfy its schema.
+type SchemaViolation struct {
+	KeyPath string
+	// Line is the line of the value, or the dictionary lacking the key
+	Line int
+	// File is the file of the value, empty unless the document is parsed by ParseNamed or included
+	File    string
+	Message string
+}
+
+func (v *SchemaViolation) Error() string {
+	location := displayPath(v.KeyPath)
+	if source := sourceLocation(v.File, v.Line); source != "" {
+		location = fmt.Sprintf("%s (%s)", location, source)
+	}
+	return fmt.Sprintf("ntgo: %s: %s", location, v.Message)
+}
+
+// ValidationError lists all of violations in order of appearance in the document.
+type ValidationError struct {
+	Violations []*SchemaViolation
+}
+
+func (e *ValidationError) Error() string {
+	return joinErrorMessages(fmt.Sprintf("ntgo: %d schema violation(s)", len(e.Violations)), len(e.Violations), func(i int) error { return e.Violations[i] })
+}
+
+var patternCache sync.Map // map[string]*regexp.Regexp
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if cached, ok := patternCache.Load(pattern); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+	compiled, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("ntgo: invalid schema pattern %q: %v", pattern, err)
+	}
+	patternCache.Store(pattern, compiled)
+	return compiled, nil
+}
+
+// Validate checks value against s.
+// Violations are returned as *ValidationError, other errors describe invalid schema.
+func (s *Schema) Validate(value *Value) error {
+	violations := []*violation{}
+	if err := s.validate(value, "", &violations); err != nil {
+		return err
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+
+	// violations are sorted in order of appearance since included files have their own lines
+	order := map[*Value]int{}
+	collectValueOrder(value, order)
+	sort.SliceStable(violations, func(i, j int) bool {
+		if order[violations[i].value] != order[violations[j].value] {
+			return order[violations[i].value] < order[violations[j].value]
+		}
+		return violations[i].KeyPath < violations[j].KeyPath
+	})
+
+	err := &ValidationError{}
+	for _, v := range violations {
+		err.Violations = append(err.Violations, v.SchemaViolation)
+	}
+	return err
+}
+
+// violation is SchemaViolation with the value reported.
+type violation struct {
+	*SchemaViolation
+	value *Value
+}
+
+func newViolation(value *Value, path string, message string) *violation {
+	return &violation{
+		SchemaViolation: &SchemaViolation{KeyPath: path, Line: value.Line, File: value.File, Message: message},
+		value:           value,
+	}
+}
+
+func (s *Schema) validate(value *Value, path string, violations *[]*violation) error {
+	report := func(format string, args ...interface{}) {
+		*violations = append(*violations, newViolation(value, path, fmt.Sprintf(format, args...)))
+	}
+
+	if len(s.Types) > 0 && !s.accepts(value.Type) {
+		names := make([]string, len(s.Types))
+		for i, typ := range s.Types {
+			names[i] = typ.String()
+		}
+		report("expected %s, got %s", strings.Join(names, " or "), value.Type)
+		return nil
+	}
+
+	switch value.Type {
+	case ValueTypeString, ValueTypeText:
+		return s.validateString(value, report)
+	case ValueTypeList:
+		if len(value.List) < s.MinItems {
+			report("expected at least %d element(s), got %d", s.MinItems, len(value.List))
+		}
+		if s.MaxItems > 0 && len(value.List) > s.MaxItems {
+			report("expected at most %d element(s), got %d", s.MaxItems, len(value.List))
+		}
+		if s.Items != nil {
+			for i, child := range value.List {
+				if err := s.Items.validate(child, appendIndexPath(path, i), violations); err != nil {
+					return err
+				}
+			}
+		}
+	case ValueTypeDictionary:
+		for _, key := range value.Keys() {
+			child := value.Dictionary[key]
+			keySchema, ok := s.Keys[key]
+			if !ok {
+				keySchema = s.AdditionalKeys
+			}
+			if keySchema == nil {
+				if len(s.Keys) > 0 {
+					*violations = append(*violations, newViolation(child, appendKeyPath(path, key), "unknown key"))
+				}
+				continue
+			}
+			if err := keySchema.validate(child, appendKeyPath(path, key), violations); err != nil {
+				return err
+			}
+		}
+		for key, keySchema := range s.Keys {
+			if _, exists := value.Dictionary[key]; !exists && !keySchema.Optional {
+				*violations = append(*violations, newViolation(value, appendKeyPath(path, key), "missing key"))
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) accepts(typ ValueType) bool {
+	for _, accepted := range s.Types {
+		if accepted == typ {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Schema) validateString(value *Value, report func(string, ...interface{})) error {
+	str := value.String
+	if value.Type == ValueTypeText {
+		str = value.Text.String()
+	}
+
+	if s.Pattern != "" {
+		pattern, err := compilePattern(s.Pattern)
+		if err != nil {
+			return err
+		}
+		if !pattern.MatchString(str) {
+			report("%q does not match pattern %q", str, s.Pattern)
+		}
+	}
+
+	if len(s.Enum) > 0 {
+		allowed := false
+		for _, candidate := range s.Enum {
+			allowed = allowed || candidate == str
+		}
+		if !allowed {
+			quoted := make([]string, len(s.Enum))
+			for i, candidate := range s.Enum {
+				quoted[i] = strconv.Quote(candidate)
+			}
+			report("%q is not one of %s", str, strings.Join(quoted, ", "))
+		}
+	}
+
+	if s.Minimum != nil || s.Maximum != nil {
+		number, err := strconv.ParseFloat(strings.TrimSpace(str), 64)
+		switch {
+		case err != nil || math.IsNaN(number):
+			report("%q is not a number", str)
+		case s.Minimum != nil && number < *s.Minimum:
+			report("%s is less than minimum %s", strings.TrimSpace(str), formatSchemaNumber(*s.Minimum))
+		case s.Maximum != nil && number > *s.Maximum:
+			report("%s is greater than maximum %s", strings.TrimSpace(str), formatSchemaNumber(*s.Maximum))
+		}
+	}
+	return nil
+}
+
+func formatSchemaNumber(number float64) string {
+	return strconv.FormatFloat(number, 'g', -1, 64)
+}
+
+// schemaDocument is the representation of Schema in NestedText.
+type schemaDocument struct {
+	Type           []string                   `nt:"type"`
+	Optional       bool                       `nt:"optional"`
+	Pattern        string                     `nt:"pattern"`
+	Enum           []string                   `nt:"enum"`
+	Minimum        *float64                   `nt:"minimum"`
+	Maximum        *float64                   `nt:"maximum"`
+	MinItems       int                        `nt:"min items"`
+	MaxItems       int                        `nt:"max items"`
+	Items          *schemaDocument            `nt:"items"`
+	Keys           map[string]*schemaDocument `nt:"keys"`
+	AdditionalKeys *schemaDocument            `nt:"additional keys"`
+}
+
+var schemaTypeNames = map[string]ValueType{
+	"string":     ValueTypeString,
+	"text":       ValueTypeText,
+	"list":       ValueTypeList,
+	"dictionary": ValueTypeDictionary,
+	"dict":       ValueTypeDictionary,
+}
+
+// ParseSchema reads Schema written in NestedText, e.g.
+//
+//	type: dictionary
+//	keys:
+//	  port:
+//	    type: string
+//	    minimum: 1
+//	    maximum: 65535
+//	  roles:
+//	    type: list
+//	    optional: true
+//	    items:
+//	      enum:
+//	        - admin
+//	        - member
+//
+// Keys of the schema document are the names of Schema fields in lower case separated with spaces.
+func ParseSchema(content string) (*Schema, error) {
+	doc := &schemaDocument{}
+	m := &Marshaller{DisallowUnknownFields: true}
+	if err := m.Marshal(content, doc); err != nil {
+		return nil, err
+	}
+	return doc.schema("")
+}
+
+func (d *schemaDocument) schema(path string) (*Schema, error) {
+	if d == nil {
+		return nil, nil
+	}
+
+	s := &Schema{
+		Optional: d.Optional,
+		Pattern:  d.Pattern,
+		Enum:     d.Enum,
+		Minimum:  d.Minimum,
+		Maximum:  d.Maximum,
+		MinItems: d.MinItems,
+		MaxItems: d.MaxItems,
+	}
+	for _, name := range d.Type {
+		typ, ok := schemaTypeNames[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("ntgo: unknown schema type %q at %s", name, displayPath(appendKeyPath(path, "type")))
+		}
+		s.Types = append(s.Types, typ)
+	}
+	if s.Pattern != "" {
+		if _, err := compilePattern(s.Pattern); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	if s.Items, err = d.Items.schema(appendKeyPath(path, "items")); err != nil {
+		return nil, err
+	}
+	if s.AdditionalKeys, err = d.AdditionalKeys.schema(appendKeyPath(path, "additional keys")); err != nil {
+		return nil, err
+	}
+	if len(d.Keys) > 0 {
+		s.Keys = map[string]*Schema{}
+		for key, keyDoc := range d.Keys {
+			keyPath := appendKeyPath(appendKeyPath(path, "keys"), key)
+			if keyDoc == nil {
+				// key without value accepts any value
+				keyDoc = &schemaDocument{}
+			}
+			if s.Keys[key], err = keyDoc.schema(keyPath); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,194 @@
+package ntgo
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const schemaSample = `
+type: dictionary
+keys:
+  name:
+    type: string
+    pattern: [A-Z][a-z]+
+  port:
+    type: string
+    minimum: 1
+    maximum: 65535
+  address:
+    type:
+      - string
+      - text
+    optional: true
+  roles:
+    type: list
+    optional: true
+    min items: 1
+    max items: 2
+    items:
+      enum:
+        - admin
+        - member
+  labels:
+    type: dictionary
+    optional: true
+    additional keys:
+      type: string
+  extra:
+    optional: true
+`
+
+func TestSchemaValidate(t *testing.T) {
+	parse := func(content string) *Value {
+		value := &Value{}
+		value.Parse([]byte(content))
+		return value
+	}
+
+	schema, err := ParseSchema(schemaSample)
+	assert.Nil(t, err)
+
+	t.Run("when document satisfies schema", func(t *testing.T) {
+		t.Run("should return nil", func(t *testing.T) {
+			assert.Nil(t, schema.Validate(parse("name: Server\nport: 80\naddress:\n  > line 1\n  > line 2\nroles:\n  - admin\nlabels:\n  env: prod\nextra:\n  - any")))
+			assert.Nil(t, schema.Validate(parse("name: Server\nport: 65535")))
+		})
+	})
+
+	t.Run("when document violates schema", func(t *testing.T) {
+		content := `name: server
+port: 70000
+roles:
+  - admin
+  - guest
+  - member
+labels:
+  env:
+    - prod
+unknown: value`
+		err := schema.Validate(parse(content))
+
+		t.Run("should return all of violations in order of lines", func(t *testing.T) {
+			assert.IsType(t, &ValidationError{}, err)
+			assert.Equal(t, []*SchemaViolation{
+				{KeyPath: "name", Line: 1, Message: `"server" does not match pattern "[A-Z][a-z]+"`},
+				{KeyPath: "port", Line: 2, Message: "70000 is greater than maximum 65535"},
+				{KeyPath: "roles", Line: 3, Message: "expected at most 2 element(s), got 3"},
+				{KeyPath: "roles[1]", Line: 5, Message: `"guest" is not one of "admin", "member"`},
+				{KeyPath: "labels.env", Line: 8, Message: "expected string, got list"},
+				{KeyPath: "unknown", Line: 10, Message: "unknown key"},
+			}, err.(*ValidationError).Violations)
+		})
+
+		t.Run("should describe violations in message", func(t *testing.T) {
+			assert.Contains(t, err.Error(), "ntgo: 6 schema violation(s)\n")
+			assert.Contains(t, err.Error(), "\t\"port\" (line 2): 70000 is greater than maximum 65535\n")
+		})
+	})
+
+	t.Run("when required keys are absent", func(t *testing.T) {
+		t.Run("should report them at the line of dictionary", func(t *testing.T) {
+			err := schema.Validate(parse("roles:\n  - admin"))
+			assert.Equal(t, []*SchemaViolation{
+				{KeyPath: "name", Line: 1, Message: "missing key"},
+				{KeyPath: "port", Line: 1, Message: "missing key"},
+			}, err.(*ValidationError).Violations)
+		})
+	})
+
+	t.Run("when number is expected", func(t *testing.T) {
+		t.Run("should report strings that are not numbers", func(t *testing.T) {
+			err := schema.Validate(parse("name: Server\nport: eighty"))
+			assert.Equal(t, `"eighty" is not a number`, err.(*ValidationError).Violations[0].Message)
+		})
+
+		t.Run("should report numbers less than minimum", func(t *testing.T) {
+			err := schema.Validate(parse("name: Server\nport: 0"))
+			assert.Equal(t, "0 is less than minimum 1", err.(*ValidationError).Violations[0].Message)
+		})
+	})
+
+	t.Run("when schema is defined in Go", func(t *testing.T) {
+		minimum := 0.0
+		s := &Schema{
+			Types: []ValueType{ValueTypeList},
+			Items: &Schema{
+				Keys: map[string]*Schema{
+					"ratio": {Minimum: &minimum},
+				},
+			},
+		}
+
+		t.Run("should validate nested schemas", func(t *testing.T) {
+			err := s.Validate(parse("-\n  ratio: -0.5\n-\n  ratio: 0.5"))
+			assert.Equal(t, []*SchemaViolation{
+				{KeyPath: "[0].ratio", Line: 2, Message: "-0.5 is less than minimum 0"},
+			}, err.(*ValidationError).Violations)
+		})
+
+		t.Run("should report root type mismatch", func(t *testing.T) {
+			err := s.Validate(parse("key: value"))
+			assert.EqualError(t, err, "ntgo: 1 schema violation(s)\n\troot (line 1): expected list, got dictionary")
+		})
+	})
+
+	t.Run("when document includes files", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"app.nt":    {Data: []byte("name: Server\nlabels: !include labels.nt\nport: 0")},
+			"labels.nt": {Data: []byte("# labels\n\n\nenv:\n  - prod")},
+		}
+		value, err := ParseFileWithIncludes(fsys, "app.nt")
+		assert.Nil(t, err)
+		err = schema.Validate(value)
+
+		t.Run("should report files of violations in order of appearance", func(t *testing.T) {
+			assert.Equal(t, []*SchemaViolation{
+				{KeyPath: "labels.env", Line: 4, File: "labels.nt", Message: "expected string, got list"},
+				{KeyPath: "port", Line: 3, File: "app.nt", Message: "0 is less than minimum 1"},
+			}, err.(*ValidationError).Violations)
+		})
+
+		t.Run("should describe files in message", func(t *testing.T) {
+			assert.Contains(t, err.Error(), "\t\"labels.env\" (labels.nt:4): expected string, got list\n")
+		})
+	})
+
+	t.Run("when pattern is invalid", func(t *testing.T) {
+		t.Run("should return error other than ValidationError", func(t *testing.T) {
+			err := (&Schema{Pattern: "("}).Validate(parse("> text"))
+			assert.NotNil(t, err)
+			_, ok := err.(*ValidationError)
+			assert.False(t, ok)
+		})
+	})
+}
+
+func TestParseSchema(t *testing.T) {
+	t.Run("should build Schema from document", func(t *testing.T) {
+		schema, err := ParseSchema(schemaSample)
+		assert.Nil(t, err)
+		assert.Equal(t, []ValueType{ValueTypeDictionary}, schema.Types)
+		assert.Equal(t, []ValueType{ValueTypeString, ValueTypeText}, schema.Keys["address"].Types)
+		assert.True(t, schema.Keys["roles"].Optional)
+		assert.Equal(t, 1, schema.Keys["roles"].MinItems)
+		assert.Equal(t, []string{"admin", "member"}, schema.Keys["roles"].Items.Enum)
+		assert.Equal(t, 65535.0, *schema.Keys["port"].Maximum)
+		assert.Equal(t, &Schema{Optional: true}, schema.Keys["extra"])
+	})
+
+	t.Run("when type is unknown", func(t *testing.T) {
+		t.Run("should return error", func(t *testing.T) {
+			_, err := ParseSchema("keys:\n  name:\n    type: number")
+			assert.EqualError(t, err, `ntgo: unknown schema type "number" at "keys.name.type"`)
+		})
+	})
+
+	t.Run("when schema has unknown key", func(t *testing.T) {
+		t.Run("should return error", func(t *testing.T) {
+			_, err := ParseSchema("typ: string")
+			assert.IsType(t, &UnknownKeysError{}, err)
+		})
+	})
+}
@@ -0,0 +1,275 @@
+package ntgo
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// UnknownKeyError describes a dictionary key that has no corresponding struct field.
+type UnknownKeyError struct {
+	KeyPath string
+	Line    int
+	// File is the file of the key, empty unless the document is parsed by ParseNamed or included
+	File string
+	// Suggestion is the key of the most similar field, empty when nothing is similar enough
+	Suggestion string
+}
+
+func (e *UnknownKeyError) Error() string {
+	message := fmt.Sprintf("ntgo: unknown key %s", displayPath(e.KeyPath))
+	if source := sourceLocation(e.File, e.Line); source != "" {
+		message = fmt.Sprintf("%s (%s)", message, source)
+	}
+	if e.Suggestion != "" {
+		message = fmt.Sprintf("%s, did you mean %q?", message, e.Suggestion)
+	}
+	return message
+}
+
+// UnknownKeysError is returned by Marshaller with DisallowUnknownFields, listing all of unknown keys in order of appearance in the document.
+type UnknownKeysError struct {
+	Keys []*UnknownKeyError
+	// Missing lists keys found missing in the same document, nil when there is none
+	Missing *MissingKeysError
+}
+
+func (e *UnknownKeysError) Error() string {
+	message := joinErrorMessages(fmt.Sprintf("ntgo: %d unknown key(s)", len(e.Keys)), len(e.Keys), func(i int) error { return e.Keys[i] })
+	if e.Missing != nil {
+		message = fmt.Sprintf("%s\n%s", message, strings.TrimPrefix(e.Missing.Error(), "ntgo: "))
+	}
+	return message
+}
+
+// Unwrap returns Missing so that errors.As finds *MissingKeysError as well.
+func (e *UnknownKeysError) Unwrap() error {
+	if e.Missing == nil {
+		return nil
+	}
+	return e.Missing
+}
+
+// MissingKeyError describes a struct field whose key is absent in the document.
+type MissingKeyError struct {
+	KeyPath string
+	// Field is the name of the field in Struct, promoted fields are joined with dots
+	Field  string
+	Struct reflect.Type
+	// Line is the line of the dictionary that lacks the key
+	Line int
+	// File is the file of the dictionary, empty unless the document is parsed by ParseNamed or included
+	File string
+}
+
+func (e *MissingKeyError) Error() string {
+	message := fmt.Sprintf("ntgo: missing key %s for field %s of %v", displayPath(e.KeyPath), e.Field, e.Struct)
+	if source := sourceLocation(e.File, e.Line); source != "" {
+		message = fmt.Sprintf("%s (%s)", message, source)
+	}
+	return message
+}
+
+// MissingKeysError lists all of keys absent for fields with required option,
+// or any fields without default option when ReportMissingKeys of Marshaller is enabled, in order of appearance in the document.
+type MissingKeysError struct {
+	Keys []*MissingKeyError
+}
+
+func (e *MissingKeysError) Error() string {
+	return joinErrorMessages(fmt.Sprintf("ntgo: %d missing key(s)", len(e.Keys)), len(e.Keys), func(i int) error { return e.Keys[i] })
+}
+
+func joinErrorMessages(summary string, count int, at func(int) error) string {
+	lines := []string{summary}
+	for i := 0; i < count; i++ {
+		lines = append(lines, "\t"+strings.TrimPrefix(at(i).Error(), "ntgo: "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+type unknownKey struct {
+	parent     *Value
+	key        string
+	suggestion string
+}
+
+type missingKey struct {
+	parent *Value
+	field  field
+	typ    reflect.Type
+}
+
+// decodeState collects keys checked by strict decoding during a call of Marshal.
+// Paths are resolved after decoding by looking up parents in the value tree.
+type decodeState struct {
+	unknownKeys []unknownKey
+	missingKeys []missingKey
+}
+
+// checkKeys records unknown keys in value and fields whose keys are not found, as configured in m.
+func (m *Marshaller) checkKeys(value *Value, typ reflect.Type, fields *structFields, found map[string]bool) {
+	if m.state == nil {
+		return
+	}
+
+	if m.DisallowUnknownFields {
+		for _, key := range value.Keys() {
+			if _, exists := fields.lookup(key); !exists {
+				m.state.unknownKeys = append(m.state.unknownKeys, unknownKey{
+					parent:     value,
+					key:        key,
+					suggestion: suggestKey(key, fields.list),
+				})
+			}
+		}
+	}
+
+	for _, f := range fields.list {
+		if found[f.key] {
+			continue
+		}
+		required := (f.tagFlag & MarshallerTagFlagRequired) == MarshallerTagFlagRequired
+		if required || (m.ReportMissingKeys && !f.hasDefault) {
+			m.state.missingKeys = append(m.state.missingKeys, missingKey{parent: value, field: f, typ: typ})
+		}
+	}
+}
+
+// stateError builds the error from keys collected while decoding root.
+func (m *Marshaller) stateError(root *Value) error {
+	if m.state == nil || (len(m.state.unknownKeys) == 0 && len(m.state.missingKeys) == 0) {
+		return nil
+	}
+
+	paths := map[*Value]string{}
+	collectValuePaths(root, "", paths)
+	// keys are sorted in order of appearance since included files have their own lines
+	order := map[*Value]int{}
+	collectValueOrder(root, order)
+
+	var missingErr *MissingKeysError
+	if len(m.state.missingKeys) > 0 {
+		missing := m.state.missingKeys
+		sort.SliceStable(missing, func(i, j int) bool {
+			if order[missing[i].parent] != order[missing[j].parent] {
+				return order[missing[i].parent] < order[missing[j].parent]
+			}
+			return missing[i].field.key < missing[j].field.key
+		})
+		missingErr = &MissingKeysError{}
+		for _, key := range missing {
+			missingErr.Keys = append(missingErr.Keys, &MissingKeyError{
+				KeyPath: appendKeyPath(paths[key.parent], key.field.key),
+				Field:   key.field.name,
+				Struct:  key.typ,
+				Line:    key.parent.Line,
+				File:    key.parent.File,
+			})
+		}
+	}
+
+	if len(m.state.unknownKeys) == 0 {
+		return missingErr
+	}
+
+	// missing keys are reported together with unknown keys
+	unknown := m.state.unknownKeys
+	sort.SliceStable(unknown, func(i, j int) bool {
+		return order[unknown[i].parent.Dictionary[unknown[i].key]] < order[unknown[j].parent.Dictionary[unknown[j].key]]
+	})
+	err := &UnknownKeysError{Missing: missingErr}
+	for _, key := range unknown {
+		value := key.parent.Dictionary[key.key]
+		err.Keys = append(err.Keys, &UnknownKeyError{
+			KeyPath:    appendKeyPath(paths[key.parent], key.key),
+			Line:       value.Line,
+			File:       value.File,
+			Suggestion: key.suggestion,
+		})
+	}
+	return err
+}
+
+// collectValueOrder numbers values in order of appearance in the document.
+func collectValueOrder(value *Value, order map[*Value]int) {
+	order[value] = len(order)
+	switch value.Type {
+	case ValueTypeList:
+		for _, child := range value.List {
+			collectValueOrder(child, order)
+		}
+	case ValueTypeDictionary:
+		for _, key := range value.Keys() {
+			collectValueOrder(value.Dictionary[key], order)
+		}
+	}
+}
+
+func collectValuePaths(value *Value, path string, paths map[*Value]string) {
+	paths[value] = path
+	switch value.Type {
+	case ValueTypeList:
+		for i, child := range value.List {
+			collectValuePaths(child, appendIndexPath(path, i), paths)
+		}
+	case ValueTypeDictionary:
+		for key, child := range value.Dictionary {
+			collectValuePaths(child, appendKeyPath(path, key), paths)
+		}
+	}
+}
+
+// suggestKey returns the key of field most similar to key.
+// Keys differing in more than a third of their length are not suggested.
+func suggestKey(key string, fields []field) string {
+	suggestion := ""
+	best := len([]rune(key))/3 + 1
+	for _, f := range fields {
+		distance := editDistance(strings.ToLower(key), strings.ToLower(f.key))
+		if distance < best {
+			best = distance
+			suggestion = f.key
+		}
+	}
+	return suggestion
+}
+
+// editDistance returns Damerau-Levenshtein distance between a and b in optimal string alignment,
+// transposition of adjacent characters counts as a single edit.
+func editDistance(a string, b string) int {
+	x := []rune(a)
+	y := []rune(b)
+
+	d := make([][]int, len(x)+1)
+	for i := range d {
+		d[i] = make([]int, len(y)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(x); i++ {
+		for j := 1; j <= len(y); j++ {
+			cost := 1
+			if x[i-1] == y[j-1] {
+				cost = 0
+			}
+			d[i][j] = minInt(d[i-1][j]+1, minInt(d[i][j-1]+1, d[i-1][j-1]+cost))
+			if i > 1 && j > 1 && x[i-1] == y[j-2] && x[i-2] == y[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return d[len(x)][len(y)]
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
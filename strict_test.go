@@ -0,0 +1,227 @@
+package ntgo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type StrictProfile struct {
+	Name    string `nt:"name"`
+	Address string `nt:"address"`
+}
+
+type StrictPerson struct {
+	ID      string         `nt:"id"`
+	Profile *StrictProfile `nt:"profile"`
+	Friends []StrictProfile
+}
+
+func TestStrictDecoding(t *testing.T) {
+	content := `id: 1
+profile:
+  nmae: smith
+  address: Tokyo
+  favorite: Natto
+Friends:
+  -
+    name: jones
+    adress: Osaka
+extra: value`
+
+	t.Run("DisallowUnknownFields", func(t *testing.T) {
+		m := &Marshaller{DisallowUnknownFields: true}
+
+		t.Run("should list all of unknown keys with lines and suggestions", func(t *testing.T) {
+			s := &StrictPerson{}
+			err := m.Marshal(content, s)
+			unknownErr, ok := err.(*UnknownKeysError)
+			assert.True(t, ok)
+			assert.Equal(t, []*UnknownKeyError{
+				{KeyPath: "profile.nmae", Line: 3, Suggestion: "name"},
+				{KeyPath: "profile.favorite", Line: 5},
+				{KeyPath: "Friends[0].adress", Line: 9, Suggestion: "address"},
+				{KeyPath: "extra", Line: 10},
+			}, unknownErr.Keys)
+			assert.Equal(t, `ntgo: 4 unknown key(s)
+	unknown key "profile.nmae" (line 3), did you mean "name"?
+	unknown key "profile.favorite" (line 5)
+	unknown key "Friends[0].adress" (line 9), did you mean "address"?
+	unknown key "extra" (line 10)`, err.Error())
+		})
+
+		t.Run("should still store known keys", func(t *testing.T) {
+			s := &StrictPerson{}
+			m.Marshal(content, s)
+			assert.Equal(t, "Tokyo", s.Profile.Address)
+		})
+
+		t.Run("should accept document without unknown keys", func(t *testing.T) {
+			s := &StrictPerson{}
+			assert.Nil(t, m.Marshal("id: 1\nprofile:\n  name: smith", s))
+		})
+
+		t.Run("should not affect Marshaller itself", func(t *testing.T) {
+			assert.Nil(t, m.state)
+		})
+	})
+
+	t.Run("ReportMissingKeys", func(t *testing.T) {
+		m := &Marshaller{ReportMissingKeys: true}
+
+		t.Run("should list fields whose keys are absent", func(t *testing.T) {
+			s := &StrictPerson{}
+			err := m.Marshal("id: 1\nprofile:\n  name: smith", s)
+			missingErr, ok := err.(*MissingKeysError)
+			assert.True(t, ok)
+			assert.Equal(t, []*MissingKeyError{
+				{KeyPath: "Friends", Field: "Friends", Struct: reflect.TypeOf(StrictPerson{}), Line: 1},
+				{KeyPath: "profile.address", Field: "Address", Struct: reflect.TypeOf(StrictProfile{}), Line: 2},
+			}, missingErr.Keys)
+			assert.Equal(t, `ntgo: 2 missing key(s)
+	missing key "Friends" for field Friends of ntgo.StrictPerson (line 1)
+	missing key "profile.address" for field Address of ntgo.StrictProfile (line 2)`, err.Error())
+		})
+
+		t.Run("with DisallowUnknownFields", func(t *testing.T) {
+			m := &Marshaller{ReportMissingKeys: true, DisallowUnknownFields: true}
+
+			t.Run("should report both of unknown and missing keys", func(t *testing.T) {
+				s := &StrictPerson{}
+				err := m.Marshal("id: 1\nprofile:\n  name: smith\n  adress: Tokyo", s)
+				unknownErr, ok := err.(*UnknownKeysError)
+				assert.True(t, ok)
+				assert.Equal(t, []*UnknownKeyError{{KeyPath: "profile.adress", Line: 4, Suggestion: "address"}}, unknownErr.Keys)
+
+				var missingErr *MissingKeysError
+				assert.True(t, errors.As(err, &missingErr))
+				assert.Equal(t, 2, len(missingErr.Keys))
+				assert.Equal(t, "Friends", missingErr.Keys[0].KeyPath)
+				assert.Equal(t, "profile.address", missingErr.Keys[1].KeyPath)
+			})
+
+			t.Run("should report missing keys alone without unknown keys", func(t *testing.T) {
+				s := &StrictPerson{}
+				err := m.Marshal("id: 1\nprofile:\n  name: smith\n  address: Tokyo", s)
+				assert.IsType(t, &MissingKeysError{}, err)
+			})
+		})
+	})
+}
+
+type RequiredServer struct {
+	Host    string   `nt:"host,required"`
+	Port    int      `nt:"port,required"`
+	Timeout string   `nt:"timeout,default=30s"`
+	Retry   uint8    `nt:"retry,default=3"`
+	Debug   bool     `nt:",default=true"`
+	Tags    []string `nt:"tags,default="`
+}
+
+type RequiredConfig struct {
+	Primary   RequiredServer  `nt:"primary,required"`
+	Secondary *RequiredServer `nt:"secondary"`
+}
+
+func TestRequiredAndDefault(t *testing.T) {
+	t.Run("required", func(t *testing.T) {
+		t.Run("should list all of missing required keys", func(t *testing.T) {
+			s := &RequiredConfig{}
+			err := Marshal("secondary:\n  port: 80", s)
+			missingErr, ok := err.(*MissingKeysError)
+			assert.True(t, ok)
+			assert.Equal(t, 2, len(missingErr.Keys))
+			assert.Equal(t, `ntgo: 2 missing key(s)
+	missing key "primary" for field Primary of ntgo.RequiredConfig (line 1)
+	missing key "secondary.host" for field Host of ntgo.RequiredServer (line 1)`, err.Error())
+		})
+
+		t.Run("should accept present keys", func(t *testing.T) {
+			s := &RequiredConfig{}
+			err := Marshal("primary:\n  host: localhost\n  port: 80", s)
+			assert.Nil(t, err)
+		})
+
+		t.Run("should be reported together with unknown keys", func(t *testing.T) {
+			s := &RequiredConfig{}
+			err := (&Marshaller{DisallowUnknownFields: true}).Marshal("primary:\n  host: localhost\n  prot: 80", s)
+			unknownErr, ok := err.(*UnknownKeysError)
+			assert.True(t, ok)
+			assert.Equal(t, "primary.prot", unknownErr.Keys[0].KeyPath)
+
+			var missingErr *MissingKeysError
+			assert.True(t, errors.As(err, &missingErr))
+			assert.Equal(t, "primary.port", missingErr.Keys[0].KeyPath)
+			assert.Equal(t, `ntgo: 1 unknown key(s)
+	unknown key "primary.prot" (line 3), did you mean "port"?
+1 missing key(s)
+	missing key "primary.port" for field Port of ntgo.RequiredServer (line 1)`, err.Error())
+		})
+	})
+
+	t.Run("default", func(t *testing.T) {
+		t.Run("should be applied with conversion of values", func(t *testing.T) {
+			s := &RequiredServer{}
+			err := Marshal("host: localhost\nport: 80", s)
+			assert.Nil(t, err)
+			assert.Equal(t, "30s", s.Timeout)
+			assert.Equal(t, uint8(3), s.Retry)
+			assert.Equal(t, true, s.Debug)
+		})
+
+		t.Run("should not override values in the document", func(t *testing.T) {
+			s := &RequiredServer{}
+			Marshal("host: localhost\nport: 80\nretry: 5", s)
+			assert.Equal(t, uint8(5), s.Retry)
+		})
+
+		t.Run("should not be reported by ReportMissingKeys", func(t *testing.T) {
+			s := &RequiredServer{}
+			err := (&Marshaller{ReportMissingKeys: true}).Marshal("host: localhost\nport: 80", s)
+			assert.Nil(t, err)
+		})
+
+		t.Run("invalid default", func(t *testing.T) {
+			s := &struct {
+				Port int `nt:"port,default=http"`
+			}{}
+
+			t.Run("should return DecodeError", func(t *testing.T) {
+				err := Marshal("other: value", s)
+				decodeErr, ok := err.(*DecodeError)
+				assert.True(t, ok)
+				assert.Equal(t, "Port", decodeErr.FieldPath)
+				assert.Equal(t, "port", decodeErr.KeyPath)
+			})
+		})
+	})
+}
+
+func TestSuggestKey(t *testing.T) {
+	fields := []field{{key: "name"}, {key: "address"}, {key: "additional roles"}}
+
+	t.Run("should suggest the most similar key", func(t *testing.T) {
+		assert.Equal(t, "address", suggestKey("adress", fields))
+		assert.Equal(t, "additional roles", suggestKey("Additional Role", fields))
+	})
+
+	t.Run("should not suggest dissimilar key", func(t *testing.T) {
+		assert.Equal(t, "", suggestKey("id", fields))
+	})
+}
+
+func TestEditDistance(t *testing.T) {
+	t.Run("should count insertion, deletion and substitution", func(t *testing.T) {
+		assert.Equal(t, 3, editDistance("kitten", "sitting"))
+	})
+
+	t.Run("should count transposition as single edit", func(t *testing.T) {
+		assert.Equal(t, 1, editDistance("nmae", "name"))
+	})
+
+	t.Run("should count runes", func(t *testing.T) {
+		assert.Equal(t, 1, editDistance("名前", "名"))
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 	"unicode"
 )
@@ -33,6 +34,7 @@ var (
 	DifferentLevelOnSameChildError    = errors.New("ntgo: child elements have dirfferent levels")
 	StringWithNewLineError            = errors.New("ntgo: string type can not have line break")
 	DictionaryDuplicateKeyError       = errors.New("ntgo: dictionary type can not have the same key")
+	DictionaryKeyWithLineBreakError   = errors.New("ntgo: dictionary key can not have line break")
 	ExpectedTokenError                = errors.New("ntgo: expected token for input value")
 )
 
@@ -70,6 +72,58 @@ type Value struct {
 
 	IndentSize int
 	Depth      int
+
+	// Line is 1-based line number where the value appears in parsed content, 0 when unknown.
+	// Values of dictionary and list refer to the line of their key or list token.
+	Line int
+	// File is the name of the file the value is parsed from by ParseNamed, which differs in included documents.
+	File string
+
+	// keys holds dictionary keys in order of appearance
+	keys []string
+}
+
+// Keys returns keys of Dictionary in order of appearance.
+// Keys added to Dictionary directly are placed at the end in sorted order.
+func (v *Value) Keys() []string {
+	keys := make([]string, 0, len(v.Dictionary))
+	known := make(map[string]bool, len(v.keys))
+	for _, key := range v.keys {
+		if _, exists := v.Dictionary[key]; exists && !known[key] {
+			keys = append(keys, key)
+			known[key] = true
+		}
+	}
+
+	if len(keys) < len(v.Dictionary) {
+		rest := make([]string, 0, len(v.Dictionary)-len(keys))
+		for key := range v.Dictionary {
+			if !known[key] {
+				rest = append(rest, key)
+			}
+		}
+		sort.Strings(rest)
+		keys = append(keys, rest...)
+	}
+
+	return keys
+}
+
+// Set stores child with key in Dictionary of v keeping order of calls.
+func (v *Value) Set(key string, child *Value) {
+	v.Type = ValueTypeDictionary
+	v.setDictionaryValue(key, child)
+}
+
+// setDictionaryValue stores child with key keeping order of appearance.
+func (v *Value) setDictionaryValue(key string, child *Value) {
+	if v.Dictionary == nil {
+		v.Dictionary = make(map[string]*Value)
+	}
+	if _, exists := v.Dictionary[key]; !exists {
+		v.keys = append(v.keys, key)
+	}
+	v.Dictionary[key] = child
 }
 
 func (v *Value) ToNestedText() string {
@@ -97,29 +151,60 @@ func (v *Value) ToNestedText() string {
 			child := v.List[i]
 			if child.Type == ValueTypeString {
 				dataLn = string(Space)
+				if child.String == "" {
+					dataLn = ""
+				}
 			}
 
 			// TODO: linear recursion
-			str = fmt.Sprintf("%s%s-%s%s\n", str, baseIndent, dataLn, child.ToNestedText())
+			str = fmt.Sprintf("%s%s-%s%s%s", str, baseIndent, dataLn, child.ToNestedText(), child.trailingLineBreak())
 		}
 	case ValueTypeDictionary:
-		it := 0
-		for k, v := range v.Dictionary {
+		for _, k := range v.Keys() {
+			child := v.Dictionary[k]
 			dataLn := string(LF)
 
-			if v.Type == ValueTypeString {
+			if child.Type == ValueTypeString {
 				dataLn = string(Space)
+				if child.String == "" {
+					dataLn = ""
+				}
 			}
 
-			str = fmt.Sprintf("%s%s%s:%s%s\n", str, baseIndent, k, dataLn, v.ToNestedText())
-
-			it++
+			str = fmt.Sprintf("%s%s%s:%s%s%s", str, baseIndent, formatDictionaryKey(k), dataLn, child.ToNestedText(), child.trailingLineBreak())
 		}
 	}
 
 	return str
 }
 
+// formatDictionaryKey quotes key when it can not be read back as it is.
+func formatDictionaryKey(key string) string {
+	needsQuote := key == "" ||
+		strings.Contains(key, ": ") ||
+		strings.HasSuffix(key, ":") ||
+		strings.ContainsAny(key[len(key)-1:], " \t") ||
+		strings.ContainsAny(key[:1], " \t'\"#>-")
+
+	if !needsQuote {
+		return key
+	}
+	if strings.ContainsRune(key, DoubleQuote) {
+		return string(Quote) + key + string(Quote)
+	}
+	return string(DoubleQuote) + key + string(DoubleQuote)
+}
+
+// trailingLineBreak returns line break to be placed after stringified value as a child element.
+// List and dictionary already end with line break, and empty ones are written as empty value.
+func (v *Value) trailingLineBreak() string {
+	switch v.Type {
+	case ValueTypeList, ValueTypeDictionary:
+		return ""
+	}
+	return string(LF)
+}
+
 type ByteReader interface {
 	ReadByte() (byte, error)
 }
@@ -142,11 +227,103 @@ func readLine(buffer ByteReader) (line []byte, err error) {
 	return
 }
 
-func (v *Value) Parse(content []byte) (err error) {
+// syntaxError is an error of parser located at line of the document.
+type syntaxError struct {
+	line int
+	err  error
+}
+
+func (e *syntaxError) Error() string {
+	return e.err.Error()
+}
+
+func (e *syntaxError) Unwrap() error {
+	return e.err
+}
+
+// lineReader reads content of a value and tracks lines of the document it has read.
+// Lines are split at each CR and LF as readLine does.
+type lineReader struct {
+	*bytes.Buffer
+	// lines are line numbers of split lines in the document
+	lines []int
+	// read is the number of split lines read so far
+	read     int
+	boundary bool
+}
+
+func newLineReader(content []byte, lines []int) *lineReader {
+	return &lineReader{Buffer: bytes.NewBuffer(content), lines: lines, boundary: true}
+}
+
+func (r *lineReader) ReadByte() (byte, error) {
+	b, err := r.Buffer.ReadByte()
+	if err == nil {
+		if r.boundary {
+			r.read++
+		}
+		r.boundary = b == CR || b == LF
+	}
+	return b, err
+}
+
+// line returns the line number of the last line read, 0 when unknown.
+func (r *lineReader) line() int {
+	if r.read == 0 || r.read > len(r.lines) {
+		return 0
+	}
+	return r.lines[r.read-1]
+}
+
+// locate returns err with the line last read unless it is located by a child.
+func (r *lineReader) locate(err error) error {
+	if _, ok := err.(*syntaxError); ok || err == EmptyDataError {
+		return err
+	}
+	return &syntaxError{line: r.line(), err: err}
+}
+
+// readerLine returns the line number of the last line read from buffer when it tracks lines.
+func readerLine(buffer ByteReader) int {
+	if r, ok := buffer.(*lineReader); ok {
+		return r.line()
+	}
+	return 0
+}
+
+// contentLines returns line numbers of lines split by readLine, LF following CR belongs to the same line.
+func contentLines(content []byte) []int {
+	lines := []int{}
+	line := 1
+	boundary := true
+	for i, b := range content {
+		if boundary {
+			lines = append(lines, line)
+		}
+		boundary = b == CR || b == LF
+		if b == LF || (b == CR && (i+1 == len(content) || content[i+1] != LF)) {
+			line++
+		}
+	}
+	return lines
+}
+
+// Parse parses content into v.
+func (v *Value) Parse(content []byte) error {
+	err := v.parse(content, contentLines(content))
+	if located, ok := err.(*syntaxError); ok {
+		return located.err
+	}
+	return err
+}
+
+// parse parses content whose split lines are located at lines of the document.
+// Errors other than EmptyDataError are returned as *syntaxError.
+func (v *Value) parse(content []byte, lines []int) (err error) {
 	v.Type = ValueTypeUnknown
 
 	removeBytesTrailingLineBreaks(&content)
-	buffer := bytes.NewBuffer(content)
+	buffer := newLineReader(content, lines)
 
 	var currentLine []byte
 	var index int
@@ -191,13 +368,98 @@ func (v *Value) Parse(content []byte) (err error) {
 		}
 	}
 
-	if err == nil && v.Type == ValueTypeUnknown {
+	if err != nil {
+		return buffer.locate(err)
+	}
+	if v.Type == ValueTypeUnknown {
 		err = EmptyDataError
 	}
 
+	// child contents are parsed separately, lines are resolved once from the root
+	if err == nil && v.Depth == 0 {
+		v.assignLines(content)
+	}
+
 	return
 }
 
+// assignLines sets Line of value and its descendants by scanning content in order of appearance.
+func (v *Value) assignLines(content []byte) {
+	scanner := &lineScanner{lines: splitContentLines(content)}
+	for i, line := range scanner.lines {
+		if valueType, _, err := detectValueType(line); err == nil && valueType != ValueTypeUnknown && valueType != ValueTypeComment {
+			v.Line = i + 1
+			break
+		}
+	}
+	scanner.assign(v)
+}
+
+type lineScanner struct {
+	lines [][]byte
+	index int
+}
+
+func (s *lineScanner) assign(v *Value) {
+	switch v.Type {
+	case ValueTypeText:
+		for range v.Text {
+			s.seek(ValueTypeText, "")
+		}
+	case ValueTypeList:
+		for _, child := range v.List {
+			child.Line = s.seek(ValueTypeList, "")
+			s.assign(child)
+		}
+	case ValueTypeDictionary:
+		for _, key := range v.Keys() {
+			child := v.Dictionary[key]
+			child.Line = s.seek(ValueTypeDictionary, key)
+			s.assign(child)
+		}
+	}
+}
+
+// seek advances to the next line of valueType and returns its line number.
+func (s *lineScanner) seek(valueType ValueType, key string) int {
+	for ; s.index < len(s.lines); s.index++ {
+		line := s.lines[s.index]
+		if t, _, err := detectValueType(line); err != nil || t != valueType {
+			continue
+		}
+		if valueType == ValueTypeDictionary {
+			k, _ := detectKeyBytes(line)
+			sanitizeDictionaryKey(&k)
+			if string(k) != key {
+				continue
+			}
+		}
+		s.index++
+		return s.index
+	}
+	return 0
+}
+
+// splitContentLines splits content into lines, CRLF is treated as one line break.
+func splitContentLines(content []byte) [][]byte {
+	lines := [][]byte{}
+	begin := 0
+	for i := 0; i < len(content); i++ {
+		switch content[i] {
+		case CR:
+			lines = append(lines, content[begin:i])
+			if i+1 < len(content) && content[i+1] == LF {
+				i++
+			}
+			begin = i + 1
+		case LF:
+			lines = append(lines, content[begin:i])
+			begin = i + 1
+		}
+	}
+	return append(lines, content[begin:])
+}
+
 func detectValueType(line []byte) (ValueType, int, error) {
 	valueType := ValueTypeUnknown
 	index := 0
@@ -409,6 +671,12 @@ func (v *Value) readListValue(baseIndentSpaces int, initialLine []byte, buffer B
 			}
 		}
 	} else {
+		// lines of child content in the document
+		elementLines := []int{}
+		if len(elementContent) > 0 {
+			elementLines = append(elementLines, readerLine(buffer))
+		}
+
 		// collect child content lines
 		for eof := false; !eof; {
 			var err error
@@ -430,6 +698,7 @@ func (v *Value) readListValue(baseIndentSpaces int, initialLine []byte, buffer B
 			}
 
 			elementContent = append(elementContent, currentLine...)
+			elementLines = append(elementLines, readerLine(buffer))
 		}
 
 		child = &Value{
@@ -439,7 +708,7 @@ func (v *Value) readListValue(baseIndentSpaces int, initialLine []byte, buffer B
 
 		// Parse child
 		// TODO: elementContent internally converted to bytes.Buffer, inpsect its performance cost
-		if err := child.Parse(elementContent); err != nil {
+		if err := child.parse(elementContent, elementLines); err != nil {
 			// treat empty data as empty string
 			child.Type = ValueTypeString
 			child.String = ""
@@ -543,6 +812,12 @@ func (v *Value) readDictionaryValue(baseIndentSpaces int, initialLine []byte, bu
 			}
 		}
 	} else {
+		// lines of child content in the document
+		elementLines := []int{}
+		if len(elementContent) > 0 {
+			elementLines = append(elementLines, readerLine(buffer))
+		}
+
 		levels := []int{}
 		for eof := false; !eof; {
 			lastLine := currentLine
@@ -556,6 +831,7 @@ func (v *Value) readDictionaryValue(baseIndentSpaces int, initialLine []byte, bu
 			if len(currentLine) == 1 && currentLine[0] == LF {
 				if len(lastLine) > 0 && lastLine[len(lastLine)-1] == CR {
 					elementContent = append(elementContent, currentLine[0])
+					elementLines = append(elementLines, readerLine(buffer))
 				}
 			}
 
@@ -609,6 +885,7 @@ func (v *Value) readDictionaryValue(baseIndentSpaces int, initialLine []byte, bu
 			}
 
 			elementContent = append(elementContent, currentLine...)
+			elementLines = append(elementLines, readerLine(buffer))
 		}
 
 		// char after line break
@@ -623,17 +900,13 @@ func (v *Value) readDictionaryValue(baseIndentSpaces int, initialLine []byte, bu
 		} else {
 			child.IndentSize = v.IndentSize
 
-			if err = child.Parse(elementContent); err != nil {
+			if err = child.parse(elementContent, elementLines); err != nil {
 				return nil, hasNext, err
 			}
 		}
 	}
 
-	if v.Dictionary == nil {
-		v.Dictionary = make(map[string]*Value)
-	}
-
-	v.Dictionary[string(key)] = child
+	v.setDictionaryValue(string(key), child)
 
 	return currentLine, hasNext, nil
 }
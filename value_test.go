@@ -74,6 +74,38 @@ func TestParse(t *testing.T) {
 		})
 	})
 
+	t.Run("line", func(t *testing.T) {
+		data, _ = ioutil.ReadFile("./sample/sample.nt")
+
+		t.Run("should be set to every value", func(t *testing.T) {
+			d, err := subject()
+
+			assert.Nil(t, err)
+			assert.Equal(t, 3, d.Line)
+			president := d.Dictionary["president"]
+			assert.Equal(t, 3, president.Line)
+			assert.Equal(t, 4, president.Dictionary["name"].Line)
+			assert.Equal(t, 5, president.Dictionary["address"].Line)
+			assert.Equal(t, 10, president.Dictionary["phone"].Dictionary["home"].Line)
+			assert.Equal(t, 12, president.Dictionary["additional roles"].Line)
+			assert.Equal(t, 13, president.Dictionary["additional roles"].List[0].Line)
+			assert.Equal(t, 24, d.Dictionary["vice president"].Dictionary["additional roles"].List[1].Line)
+			assert.Equal(t, 30, d.Dictionary["treasurer"].Dictionary["address"].Line)
+		})
+
+		t.Run("crlf", func(t *testing.T) {
+			data = []byte("a: 1\r\nb:\r\n  - c\r\n")
+
+			t.Run("should count as one line break", func(t *testing.T) {
+				d, err := subject()
+
+				assert.Nil(t, err)
+				assert.Equal(t, 2, d.Dictionary["b"].Line)
+				assert.Equal(t, 3, d.Dictionary["b"].List[0].Line)
+			})
+		})
+	})
+
 	t.Run("string", func(t *testing.T) {
 
 		expect := "plain text"
@@ -778,6 +810,35 @@ func TestToNestedText(t *testing.T) {
 			})
 		})
 	})
+
+	t.Run("empty values", func(t *testing.T) {
+		defer resetCondition()
+
+		data = []byte("key:\nlist:\n  -\n  - value\n")
+
+		t.Run("should be written without trailing space", func(t *testing.T) {
+			assert.Equal(t, "key:\nlist:\n  -\n  - value\n", subject())
+		})
+	})
+}
+
+func TestFormatDictionaryKey(t *testing.T) {
+	t.Run("regular key", func(t *testing.T) {
+		t.Run("should not be quoted", func(t *testing.T) {
+			assert.Equal(t, "additional roles", formatDictionaryKey("additional roles"))
+			assert.Equal(t, "a:b", formatDictionaryKey("a:b"))
+		})
+	})
+
+	t.Run("key that can not be read back", func(t *testing.T) {
+		t.Run("should be quoted", func(t *testing.T) {
+			assert.Equal(t, `"a: b"`, formatDictionaryKey("a: b"))
+			assert.Equal(t, `"trailing "`, formatDictionaryKey("trailing "))
+			assert.Equal(t, `"# comment"`, formatDictionaryKey("# comment"))
+			assert.Equal(t, `""`, formatDictionaryKey(""))
+			assert.Equal(t, `'"a": b'`, formatDictionaryKey(`"a": b`))
+		})
+	})
 }
 
 func TestDetectValueType(t *testing.T) {
@@ -2208,3 +2269,16 @@ func TestReadLine(t *testing.T) {
 		})
 	})
 }
+
+func TestValueSet(t *testing.T) {
+	t.Run("should make dictionary keeping order of keys", func(t *testing.T) {
+		value := &Value{}
+		value.Set("b", &Value{Type: ValueTypeString, String: "1"})
+		value.Set("a", &Value{Type: ValueTypeString, String: "2"})
+		value.Set("b", &Value{Type: ValueTypeString, String: "3"})
+
+		assert.Equal(t, ValueTypeDictionary, value.Type)
+		assert.Equal(t, []string{"b", "a"}, value.Keys())
+		assert.Equal(t, "b: 3\na: 2\n", value.ToNestedText())
+	})
+}